@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/Tadateki/Chirpy/internal/auth"
+	"github.com/Tadateki/Chirpy/internal/database"
+)
+
+// runAdminCommand implements `chirpy admin promote|demote <email>`.
+// It is how the first admin is created, since nobody can call the admin API yet.
+func runAdminCommand(ctx context.Context, store database.Store, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: chirpy admin promote|demote <email>")
+	}
+
+	var role string
+	switch args[0] {
+	case "promote":
+		role = auth.RoleAdmin
+	case "demote":
+		role = auth.RoleUser
+	default:
+		return fmt.Errorf("unknown admin command %q (want promote|demote)", args[0])
+	}
+
+	user, err := store.GetUserFromEmail(ctx, args[1])
+	if err != nil {
+		return fmt.Errorf("user %q: %w", args[1], err)
+	}
+	if user.Role == role {
+		log.Printf("%s already has role %s", user.Email, role)
+		return nil
+	}
+
+	if err := setUserRole(ctx, store, user, role); err != nil {
+		return err
+	}
+	log.Printf("%s is now %s", user.Email, role)
+	return nil
+}
+
+// setUserRole changes the role and revokes the user's access tokens so no token keeps the old role claim.
+func setUserRole(ctx context.Context, store database.Store, user database.User, role string) error {
+	err := store.SetUserRole(ctx, database.SetUserRoleParams{
+		Role: role,
+		ID:   user.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return store.SetTokensValidAfter(ctx, database.SetTokensValidAfterParams{
+		TokensValidAfter: sql.NullTime{Time: revocationTime(), Valid: true},
+		ID:               user.ID,
+	})
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Tadateki/Chirpy/internal/auth"
+	"github.com/Tadateki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+var errTokenRevoked = errors.New("token has been revoked")
+
+// validateAccessToken verifies the JWT and rejects tokens issued before the
+// user's tokens_valid_after (password change, logout-all, admin action).
+func (cfg *apiConfig) validateAccessToken(ctx context.Context, token string) (uuid.UUID, error) {
+	claims, err := cfg.accessTokenClaims(ctx, token)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return claims.UserID()
+}
+
+// optionalViewer identifies the caller of a public endpoint: no user without an Authorization
+// header, the token's user with one. A bad token is rejected rather than treated as anonymous.
+// On failure it has already responded.
+func (cfg *apiConfig) optionalViewer(w http.ResponseWriter, r *http.Request) (uuid.NullUUID, bool) {
+	if r.Header.Get("Authorization") == "" {
+		return uuid.NullUUID{}, true
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return uuid.NullUUID{}, false
+	}
+
+	userid, err := cfg.validateAccessToken(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return uuid.NullUUID{}, false
+	}
+	return uuid.NullUUID{UUID: userid, Valid: true}, true
+}
+
+func (cfg *apiConfig) accessTokenClaims(ctx context.Context, token string) (*auth.Claims, error) {
+	claims, err := cfg.jwtKeys.ParseJWT(token)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := claims.UserID()
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := cfg.dbQueries.GetUserFromUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Revocations store the next whole second, so a token issued in the second of a
+	// revocation is older than tokens_valid_after.
+	issuedAfter := claims.IssuedAt != nil && !claims.IssuedAt.Time.Before(user.TokensValidAfter.Time)
+	if user.TokensValidAfter.Valid && !issuedAfter {
+		return nil, errTokenRevoked
+	}
+
+	return claims, nil
+}
+
+// revokeAccessTokens invalidates every access token issued to the user so far.
+func (cfg *apiConfig) revokeAccessTokens(ctx context.Context, userID uuid.UUID) error {
+	return cfg.dbQueries.SetTokensValidAfter(ctx, database.SetTokensValidAfterParams{
+		TokensValidAfter: sql.NullTime{Time: revocationTime(), Valid: true},
+		ID:               userID,
+	})
+}
+
+// revocationTime is the tokens_valid_after for a revocation now: the start of the next
+// second, because iat has whole-second precision and tokens issued so far in this
+// second must die too.
+func revocationTime() time.Time {
+	return time.Now().UTC().Truncate(time.Second).Add(time.Second)
+}
+
+// makeAccessToken issues an access token for user. A token issued in the rest of a
+// revocation's second is dated to tokens_valid_after, so it is not revoked with the
+// tokens from before.
+func (cfg *apiConfig) makeAccessToken(user database.User) (string, error) {
+	issuedAt := time.Now()
+	if user.TokensValidAfter.Valid && issuedAt.Before(user.TokensValidAfter.Time) {
+		issuedAt = user.TokensValidAfter.Time
+	}
+	return cfg.jwtKeys.MakeJWTAt(user.ID, user.Role, issuedAt, time.Duration(cfg.expires_in_seconds)*time.Second)
+}
+
+// middlewareAdminOnly lets a request through only with a valid access token carrying the admin role.
+// Role changes revoke the user's access tokens, so the role claim is never stale.
+func (cfg *apiConfig) middlewareAdminOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, err := auth.GetBearerToken(r.Header)
+		if err != nil {
+			respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+			return
+		}
+
+		claims, err := cfg.accessTokenClaims(r.Context(), token)
+		if err != nil {
+			respondWithError(w, http.StatusUnauthorized, "Authorization Failure")
+			return
+		}
+
+		if claims.Role != auth.RoleAdmin {
+			respondWithError(w, http.StatusForbidden, "admin role required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
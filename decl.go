@@ -6,7 +6,10 @@ import (
 
 	"database/sql"
 
+	"github.com/Tadateki/Chirpy/internal/auth"
+	"github.com/Tadateki/Chirpy/internal/blobstore"
 	"github.com/Tadateki/Chirpy/internal/database"
+	"github.com/Tadateki/Chirpy/internal/mailer"
 	"github.com/google/uuid"
 	//"github.com/vertica/vertica-sql-go/logger"
 )
@@ -29,12 +32,24 @@ var maxChirpLength = 140
 
 type apiConfig struct {
 	fileserverHits           atomic.Int32
-	dbQueries                *database.Queries
+	dbQueries                database.Store
 	platform                 string
 	db                       *sql.DB
 	tokenSecret              string
+	jwtKeys                  *auth.Keyring
 	expires_in_seconds       int
 	refresh_expires_in_hours int
 	polka_key                string
+	totpKey                  []byte // AES-256 key for TOTP secrets at rest; nil disables enrollment
+	mailer                   mailer.Mailer
+	publicURL                string // base URL for links in emails
+	requireVerifiedEmail     bool   // unverified users cannot post chirps
+	loginPolicy              loginPolicy
+	resetPolicy              resetPolicy
+	passwordParams           auth.PasswordParams // Argon2id cost for new hashes; weaker hashes are upgraded at login
+	passwordPolicy           auth.PasswordPolicy
+	blobs                    blobstore.BlobStore // uploaded avatars and header images
+	accountDeletionGrace     time.Duration       // time between DELETE /api/users and the hard delete
+	timeline                 *timelineFanout     // copies new chirps into followers' timelines
 	// logger         *log.Logger
 }
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"net/mail"
+	"strings"
+)
+
+const maxEmailLength = 254
+
+// validEmail accepts a bare addr-spec (no display name) with a dotted domain.
+func validEmail(email string) bool {
+	if len(email) > maxEmailLength {
+		return false
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return false
+	}
+	_, domain, _ := strings.Cut(email, "@")
+	return strings.Contains(strings.Trim(domain, "."), ".")
+}
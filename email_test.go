@@ -0,0 +1,31 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{"saul@bettercall.com", true},
+		{"kim.wexler+chirpy@wexlermcgill.co.jp", true},
+		{"", false},
+		{"saul", false},
+		{"saul@", false},
+		{"@bettercall.com", false},
+		{"saul@localhost", false},
+		{"Saul Goodman <saul@bettercall.com>", false},
+		{" saul@bettercall.com", false},
+		{"saul@bettercall.com.", false},
+		{strings.Repeat("a", 250) + "@b.com", false},
+	}
+
+	for _, tt := range tests {
+		if got := validEmail(tt.email); got != tt.want {
+			t.Errorf("validEmail(%q) = %v, want %v", tt.email, got, tt.want)
+		}
+	}
+}
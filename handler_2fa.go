@@ -0,0 +1,402 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Tadateki/Chirpy/internal/auth"
+	"github.com/Tadateki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	totpIssuer        = "Chirpy"
+	mfaTokenExpiresIn = 5 * time.Minute
+	recoveryCodeCount = 10
+)
+
+// totpEnrollHandler starts enrollment: a new secret is stored (encrypted) but
+// two-factor login stays off until the user proves it with a code.
+// Both steps need the current password, so an access token alone cannot turn it on.
+func (cfg *apiConfig) totpEnrollHandler(w http.ResponseWriter, r *http.Request) {
+	type EnrollRequest struct {
+		CurrentPassword string `json:"current_password"`
+	}
+
+	// Authorization
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	userid, err := cfg.validateAccessToken(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	if cfg.totpKey == nil {
+		respondWithError(w, http.StatusServiceUnavailable, "two-factor authentication is not configured")
+		return
+	}
+
+	var req EnrollRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if req.CurrentPassword == "" {
+		respondWithError(w, http.StatusBadRequest, "current_password is required to change two-factor authentication")
+		return
+	}
+
+	user, err := cfg.dbQueries.GetUserFromUserID(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	if user.TotpEnabledAt.Valid {
+		respondWithError(w, http.StatusConflict, "two-factor authentication is already enabled")
+		return
+	}
+	if !cfg.reauthenticate(w, r, user, req.CurrentPassword) {
+		return
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Secret Generation Error")
+		return
+	}
+	encrypted, err := auth.EncryptSecret(cfg.totpKey, secret)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Secret Encryption Error")
+		return
+	}
+
+	err = cfg.dbQueries.SetUserTOTPSecret(r.Context(), database.SetUserTOTPSecretParams{
+		TotpSecret: sql.NullString{String: encrypted, Valid: true},
+		ID:         userid,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"secret":           secret,
+		"provisioning_uri": auth.TOTPProvisioningURI(totpIssuer, user.Email, secret),
+	})
+}
+
+// totpConfirmHandler enables two-factor login once the user sends a valid code
+// for the pending secret, and returns the recovery codes (shown only once).
+func (cfg *apiConfig) totpConfirmHandler(w http.ResponseWriter, r *http.Request) {
+	type ConfirmRequest struct {
+		Code            string `json:"code"`
+		CurrentPassword string `json:"current_password"`
+	}
+
+	// Authorization
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	userid, err := cfg.validateAccessToken(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	var req ConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if req.CurrentPassword == "" {
+		respondWithError(w, http.StatusBadRequest, "current_password is required to change two-factor authentication")
+		return
+	}
+
+	user, err := cfg.dbQueries.GetUserFromUserID(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	if user.TotpEnabledAt.Valid {
+		respondWithError(w, http.StatusConflict, "two-factor authentication is already enabled")
+		return
+	}
+	if !user.TotpSecret.Valid {
+		respondWithError(w, http.StatusBadRequest, "no pending two-factor enrollment")
+		return
+	}
+	if !cfg.reauthenticate(w, r, user, req.CurrentPassword) {
+		return
+	}
+
+	ok, err := cfg.checkTOTP(r.Context(), user, req.Code)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Secret Decryption Error")
+		return
+	}
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "invalid code")
+		return
+	}
+
+	err = cfg.dbQueries.EnableUserTOTP(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	codes, err := cfg.resetRecoveryCodes(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Recovery Code Generation Error")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]any{
+		"recovery_codes": codes,
+	})
+}
+
+// totpDisableHandler turns two-factor login off; it needs a current code or a recovery code.
+func (cfg *apiConfig) totpDisableHandler(w http.ResponseWriter, r *http.Request) {
+	type DisableRequest struct {
+		Code string `json:"code"`
+	}
+
+	// Authorization
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	userid, err := cfg.validateAccessToken(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	var req DisableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	user, err := cfg.dbQueries.GetUserFromUserID(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	if !user.TotpEnabledAt.Valid {
+		respondWithError(w, http.StatusBadRequest, "two-factor authentication is not enabled")
+		return
+	}
+
+	// A stolen access token must not be enough to guess the code; same counters as the login step.
+	keys := cfg.loginKeys(user.Email, clientIP(r))
+	status, retryAfter, err := cfg.checkLoginThrottle(r.Context(), keys)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	if status != 0 {
+		respondWithThrottle(w, status, retryAfter)
+		return
+	}
+
+	ok, err := cfg.verifySecondFactor(r.Context(), user, req.Code)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Second Factor Check Error")
+		return
+	}
+	if !ok {
+		if err := cfg.recordLoginFailure(r.Context(), keys); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+			return
+		}
+		respondWithError(w, http.StatusUnauthorized, "invalid code")
+		return
+	}
+
+	err = cfg.dbQueries.ClearLoginAttempts(r.Context(), loginEmailKey(user.Email))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	err = cfg.dbQueries.DisableUserTOTP(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	err = cfg.dbQueries.DeleteRecoveryCodesForUser(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loginMFAHandler is the second login step: the MFA pending token from
+// /api/login plus a TOTP or recovery code are exchanged for the real tokens.
+func (cfg *apiConfig) loginMFAHandler(w http.ResponseWriter, r *http.Request) {
+	type LoginMFARequest struct {
+		MFAToken string `json:"mfa_token"`
+		Code     string `json:"code"`
+	}
+
+	var req LoginMFARequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	claims, err := cfg.jwtKeys.ParseMFAToken(req.MFAToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid or expired mfa_token")
+		return
+	}
+	userid, err := claims.UserID()
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid or expired mfa_token")
+		return
+	}
+
+	user, err := cfg.dbQueries.GetUserFromUserID(r.Context(), userid)
+	if err != nil || !user.TotpEnabledAt.Valid {
+		respondWithError(w, http.StatusUnauthorized, "invalid or expired mfa_token")
+		return
+	}
+
+	// Codes are guessed against the same counters as passwords.
+	keys := cfg.loginKeys(user.Email, clientIP(r))
+	status, retryAfter, err := cfg.checkLoginThrottle(r.Context(), keys)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	if status != 0 {
+		respondWithThrottle(w, status, retryAfter)
+		return
+	}
+
+	ok, err := cfg.verifySecondFactor(r.Context(), user, req.Code)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Second Factor Check Error")
+		return
+	}
+	if !ok {
+		if err := cfg.recordLoginFailure(r.Context(), keys); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+			return
+		}
+		respondWithError(w, http.StatusUnauthorized, "invalid code")
+		return
+	}
+
+	err = cfg.dbQueries.ClearLoginAttempts(r.Context(), loginEmailKey(user.Email))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	// Each MFA pending token completes one login.
+	err = cfg.dbQueries.DeleteExpiredMFATokens(r.Context(), time.Now().UTC())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	used, err := cfg.dbQueries.UseMFAToken(r.Context(), database.UseMFATokenParams{
+		Jti:       claims.ID,
+		UserID:    user.ID,
+		ExpiresAt: claims.ExpiresAt.Time.UTC(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	if used == 0 {
+		respondWithError(w, http.StatusUnauthorized, "invalid or expired mfa_token")
+		return
+	}
+
+	cfg.respondWithLogin(w, r, user)
+}
+
+// checkTOTP validates code against the user's stored (encrypted) secret and
+// records its time step, so the same code cannot be used twice.
+func (cfg *apiConfig) checkTOTP(ctx context.Context, user database.User, code string) (bool, error) {
+	secret, err := auth.DecryptSecret(cfg.totpKey, user.TotpSecret.String)
+	if err != nil {
+		return false, err
+	}
+	step, ok, err := auth.MatchTOTP(secret, code, time.Now())
+	if err != nil || !ok {
+		return false, err
+	}
+
+	used, err := cfg.dbQueries.UseTOTPStep(ctx, database.UseTOTPStepParams{
+		Step: step,
+		ID:   user.ID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return used == 1, nil
+}
+
+// verifySecondFactor accepts a TOTP code or consumes one unused recovery code.
+// Recovery codes still work when the TOTP check fails, e.g. because the secret cannot be decrypted.
+func (cfg *apiConfig) verifySecondFactor(ctx context.Context, user database.User, code string) (bool, error) {
+	ok, totpErr := cfg.checkTOTP(ctx, user, code)
+	if totpErr == nil && ok {
+		return true, nil
+	}
+
+	used, err := cfg.dbQueries.UseRecoveryCode(ctx, database.UseRecoveryCodeParams{
+		CodeHash: auth.HashRecoveryCode(code),
+		UserID:   user.ID,
+	})
+	if err != nil {
+		return false, err
+	}
+	if used == 1 {
+		return true, nil
+	}
+	return false, totpErr
+}
+
+// resetRecoveryCodes replaces the user's recovery codes and returns the new ones in clear text.
+func (cfg *apiConfig) resetRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	err := cfg.dbQueries.DeleteRecoveryCodesForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	for _, code := range codes {
+		err := cfg.dbQueries.CreateRecoveryCode(ctx, database.CreateRecoveryCodeParams{
+			CodeHash: auth.HashRecoveryCode(code),
+			UserID:   userID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return codes, nil
+}
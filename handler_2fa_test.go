@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Tadateki/Chirpy/internal/auth"
+)
+
+func TestTOTPEnrollmentAndLogin(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.loginPolicy.freeFailures = 10 // the replay checks below count as failed attempts
+	h := cfg.routes()
+	login := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+	creds := map[string]string{"email": "saul@bettercall.com", "password": "correct horse battery staple"}
+	reauth := map[string]string{"current_password": "correct horse battery staple"}
+
+	var enroll struct {
+		Secret          string `json:"secret"`
+		ProvisioningURI string `json:"provisioning_uri"`
+	}
+	if rec := doRequest(t, h, "POST", "/api/2fa/enroll", login.Token, reauth, &enroll); rec.Code != http.StatusOK {
+		t.Fatalf("enroll: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if enroll.Secret == "" || enroll.ProvisioningURI == "" {
+		t.Fatalf("enroll returned %+v", enroll)
+	}
+
+	// Until confirmed, login still takes only the password.
+	var plain loginResponse
+	if rec := doRequest(t, h, "POST", "/api/login", "", creds, &plain); rec.Code != http.StatusOK || plain.Token == "" {
+		t.Fatalf("login before confirmation: status %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := doRequest(t, h, "POST", "/api/2fa/confirm", login.Token, map[string]string{"code": "000000", "current_password": reauth["current_password"]}, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("confirm with a wrong code: status %d, want 401", rec.Code)
+	}
+
+	// Confirm with the previous step's code and log in with the current one below.
+	waitForFreshTOTPStep(t)
+	now := time.Now()
+	confirmCode, _ := auth.TOTPCode(enroll.Secret, now.Add(-30*time.Second))
+	code, _ := auth.TOTPCode(enroll.Secret, now)
+	var confirm struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	if rec := doRequest(t, h, "POST", "/api/2fa/confirm", login.Token, map[string]string{"code": confirmCode, "current_password": reauth["current_password"]}, &confirm); rec.Code != http.StatusOK {
+		t.Fatalf("confirm: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if len(confirm.RecoveryCodes) != recoveryCodeCount {
+		t.Fatalf("confirm returned %d recovery codes, want %d", len(confirm.RecoveryCodes), recoveryCodeCount)
+	}
+
+	// The password step now only yields an MFA pending token.
+	var pending struct {
+		Token       string `json:"token"`
+		MFARequired bool   `json:"mfa_required"`
+		MFAToken    string `json:"mfa_token"`
+	}
+	if rec := doRequest(t, h, "POST", "/api/login", "", creds, &pending); rec.Code != http.StatusOK {
+		t.Fatalf("login: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if !pending.MFARequired || pending.MFAToken == "" || pending.Token != "" {
+		t.Fatalf("login with 2FA enabled returned %+v", pending)
+	}
+	if rec := doRequest(t, h, "POST", "/api/chirps", pending.MFAToken, map[string]string{"body": "hi"}, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("create chirp with an MFA pending token: status %d, want 401", rec.Code)
+	}
+
+	if rec := doRequest(t, h, "POST", "/api/login/2fa", "", map[string]string{"mfa_token": pending.MFAToken, "code": "000000"}, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("second step with a wrong code: status %d, want 401", rec.Code)
+	}
+
+	var full loginResponse
+	if rec := doRequest(t, h, "POST", "/api/login/2fa", "", map[string]string{"mfa_token": pending.MFAToken, "code": code}, &full); rec.Code != http.StatusOK {
+		t.Fatalf("second step: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if full.Token == "" || full.RefreshToken == "" {
+		t.Fatalf("second step returned %+v", full)
+	}
+
+	// Neither the MFA token nor a code for an already used time step works twice.
+	newPending := func() string {
+		t.Helper()
+		pending.MFAToken = ""
+		if rec := doRequest(t, h, "POST", "/api/login", "", creds, &pending); rec.Code != http.StatusOK || pending.MFAToken == "" {
+			t.Fatalf("login: status %d, body %s", rec.Code, rec.Body.String())
+		}
+		return pending.MFAToken
+	}
+	usedToken := pending.MFAToken
+	if rec := doRequest(t, h, "POST", "/api/login/2fa", "", map[string]string{"mfa_token": usedToken, "code": confirm.RecoveryCodes[2]}, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("second step with a used mfa_token: status %d, want 401", rec.Code)
+	}
+	mfaToken := newPending()
+	for _, c := range []string{code, confirmCode} {
+		if rec := doRequest(t, h, "POST", "/api/login/2fa", "", map[string]string{"mfa_token": mfaToken, "code": c}, nil); rec.Code != http.StatusUnauthorized {
+			t.Errorf("second step with a replayed code: status %d, want 401", rec.Code)
+		}
+	}
+
+	// Recovery codes work once.
+	recovery := map[string]string{"mfa_token": mfaToken, "code": confirm.RecoveryCodes[0]}
+	if rec := doRequest(t, h, "POST", "/api/login/2fa", "", recovery, nil); rec.Code != http.StatusOK {
+		t.Errorf("second step with a recovery code: status %d, want 200", rec.Code)
+	}
+	recovery["mfa_token"] = newPending()
+	if rec := doRequest(t, h, "POST", "/api/login/2fa", "", recovery, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("second step with a used recovery code: status %d, want 401", rec.Code)
+	}
+
+	if rec := doRequest(t, h, "POST", "/api/2fa/disable", full.Token, map[string]string{"code": confirm.RecoveryCodes[1]}, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("disable: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if rec := doRequest(t, h, "POST", "/api/login", "", creds, &plain); rec.Code != http.StatusOK || plain.Token == "" {
+		t.Errorf("login after disabling 2FA: status %d, body %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestTOTPDisableThrottled(t *testing.T) {
+	h := newTestConfig().routes()
+	login := signup(t, h, "kim@wexler.com", "correct horse battery staple")
+
+	var enroll struct {
+		Secret string `json:"secret"`
+	}
+	reauth := map[string]string{"current_password": "correct horse battery staple"}
+	if rec := doRequest(t, h, "POST", "/api/2fa/enroll", login.Token, reauth, &enroll); rec.Code != http.StatusOK {
+		t.Fatalf("enroll: status %d", rec.Code)
+	}
+	code, _ := auth.TOTPCode(enroll.Secret, time.Now())
+	if rec := doRequest(t, h, "POST", "/api/2fa/confirm", login.Token, map[string]string{"code": code, "current_password": reauth["current_password"]}, nil); rec.Code != http.StatusOK {
+		t.Fatalf("confirm: status %d", rec.Code)
+	}
+
+	// Wrong guesses count against the account like failed logins.
+	policy := defaultLoginPolicy()
+	for i := 0; i <= policy.freeFailures; i++ {
+		if rec := doRequest(t, h, "POST", "/api/2fa/disable", login.Token, map[string]string{"code": "000000"}, nil); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("disable guess %d: status %d, want 401", i+1, rec.Code)
+		}
+	}
+	rec := doRequest(t, h, "POST", "/api/2fa/disable", login.Token, map[string]string{"code": "000000"}, nil)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("disable after too many guesses: status %d, Retry-After %q; want 429", rec.Code, rec.Header().Get("Retry-After"))
+	}
+}
+
+func TestTOTPEnrollmentNeedsPassword(t *testing.T) {
+	h := newTestConfig().routes()
+	login := signup(t, h, "kim@wexler.com", "correct horse battery staple")
+
+	if rec := doRequest(t, h, "POST", "/api/2fa/enroll", "", map[string]string{"current_password": "correct horse battery staple"}, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("enroll without a token: status %d, want 401", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/2fa/enroll", login.Token, map[string]string{}, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("enroll without current_password: status %d, want 400", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/2fa/enroll", login.Token, map[string]string{"current_password": "wrong"}, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("enroll with a wrong current_password: status %d, want 403", rec.Code)
+	}
+
+	var enroll struct {
+		Secret string `json:"secret"`
+	}
+	if rec := doRequest(t, h, "POST", "/api/2fa/enroll", login.Token, map[string]string{"current_password": "correct horse battery staple"}, &enroll); rec.Code != http.StatusOK {
+		t.Fatalf("enroll: status %d", rec.Code)
+	}
+	code, _ := auth.TOTPCode(enroll.Secret, time.Now())
+	if rec := doRequest(t, h, "POST", "/api/2fa/confirm", login.Token, map[string]string{"code": code, "current_password": "wrong"}, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("confirm with a wrong current_password: status %d, want 403", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/2fa/confirm", login.Token, map[string]string{"code": code}, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("confirm without current_password: status %d, want 400", rec.Code)
+	}
+}
+
+func TestRecoveryCodeWithUndecryptableSecret(t *testing.T) {
+	cfg := newTestConfig()
+	h := cfg.routes()
+	login := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+	creds := map[string]string{"email": "saul@bettercall.com", "password": "correct horse battery staple"}
+	reauth := map[string]string{"current_password": creds["password"]}
+
+	var enroll struct {
+		Secret string `json:"secret"`
+	}
+	if rec := doRequest(t, h, "POST", "/api/2fa/enroll", login.Token, reauth, &enroll); rec.Code != http.StatusOK {
+		t.Fatalf("enroll: status %d", rec.Code)
+	}
+	code, _ := auth.TOTPCode(enroll.Secret, time.Now())
+	var confirm struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	if rec := doRequest(t, h, "POST", "/api/2fa/confirm", login.Token, map[string]string{"code": code, "current_password": creds["password"]}, &confirm); rec.Code != http.StatusOK {
+		t.Fatalf("confirm: status %d", rec.Code)
+	}
+
+	// The TOTP key changed, so the stored secret no longer decrypts.
+	cfg.totpKey = bytes.Repeat([]byte{2}, 32)
+
+	var pending struct {
+		MFAToken string `json:"mfa_token"`
+	}
+	if rec := doRequest(t, h, "POST", "/api/login", "", creds, &pending); rec.Code != http.StatusOK || pending.MFAToken == "" {
+		t.Fatalf("login: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if rec := doRequest(t, h, "POST", "/api/login/2fa", "", map[string]string{"mfa_token": pending.MFAToken, "code": "000000"}, nil); rec.Code != http.StatusInternalServerError {
+		t.Errorf("second step with a TOTP code: status %d, want 500", rec.Code)
+	}
+	var full loginResponse
+	if rec := doRequest(t, h, "POST", "/api/login/2fa", "", map[string]string{"mfa_token": pending.MFAToken, "code": confirm.RecoveryCodes[0]}, &full); rec.Code != http.StatusOK || full.Token == "" {
+		t.Errorf("second step with a recovery code: status %d, body %s", rec.Code, rec.Body.String())
+	}
+}
+
+// waitForFreshTOTPStep sleeps into the next 30-second step when the current one is about to end,
+// so codes computed now stay inside the accepted window for the rest of the test.
+func waitForFreshTOTPStep(t *testing.T) {
+	t.Helper()
+	const period = 30 * time.Second
+	if left := period - time.Duration(time.Now().UnixNano())%period; left < 5*time.Second {
+		time.Sleep(left)
+	}
+}
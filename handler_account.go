@@ -0,0 +1,336 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Tadateki/Chirpy/internal/auth"
+	"github.com/Tadateki/Chirpy/internal/database"
+	"github.com/Tadateki/Chirpy/internal/mailer"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultAccountDeletionGrace = 30 * 24 * time.Hour
+	accountPurgeInterval        = time.Hour
+)
+
+// deleteAccountHandler schedules the account for deletion after the grace period and signs the
+// user out everywhere. Logging in again before then cancels the deletion.
+func (cfg *apiConfig) deleteAccountHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Authorization
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	userid, err := cfg.validateAccessToken(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	type deleteAccountRequest struct {
+		Password string `json:"password"`
+	}
+
+	var req deleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if req.Password == "" {
+		respondWithError(w, http.StatusBadRequest, "password is required to delete the account")
+		return
+	}
+
+	user, err := cfg.dbQueries.GetUserFromUserID(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	if !cfg.reauthenticate(w, r, user, req.Password) {
+		return
+	}
+
+	deleteAfter := time.Now().UTC().Add(cfg.accountDeletionGrace)
+	err = cfg.dbQueries.ScheduleUserDeletion(r.Context(), database.ScheduleUserDeletionParams{
+		DeleteAfter: sql.NullTime{Time: deleteAfter, Valid: true},
+		ID:          userid,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	// Sign out everywhere
+	if err := cfg.dbQueries.RevokeAllRefreshTokensForUser(r.Context(), userid); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Fail to Revoke refresh tokens")
+		return
+	}
+	if err := cfg.revokeAccessTokens(r.Context(), userid); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Fail to Revoke access tokens")
+		return
+	}
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Your Chirpy account will be deleted",
+		Body: "Your Chirpy account and everything in it will be deleted permanently on " +
+			deleteAfter.Format(time.RFC1123) + ".\n\n" +
+			"Changed your mind? Log in before then and the account stays.\n",
+	}
+	if err := cfg.mailer.Send(r.Context(), msg); err != nil {
+		log.Printf("deletion notice to %s: %v", user.Email, err)
+	}
+
+	respondWithJSON(w, http.StatusAccepted, map[string]any{
+		"delete_after": deleteAfter.String(),
+	})
+}
+
+// purgeDeletedAccounts hard-deletes accounts whose grace period is over, with their uploads.
+func (cfg *apiConfig) purgeDeletedAccounts(ctx context.Context) (int, error) {
+	rows, err := cfg.dbQueries.PurgeDeletedUsers(ctx, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	for _, row := range rows {
+		cfg.deleteProfileImage(ctx, avatarImage, row.AvatarKey)
+		cfg.deleteProfileImage(ctx, headerImage, row.HeaderKey)
+	}
+	return len(rows), nil
+}
+
+// runAccountPurger calls purgeDeletedAccounts every interval until ctx is done.
+func (cfg *apiConfig) runAccountPurger(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		n, err := cfg.purgeDeletedAccounts(ctx)
+		if err != nil {
+			log.Printf("purge deleted accounts: %v", err)
+		} else if n > 0 {
+			log.Printf("purged %d deleted accounts", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// exportAccountHandler returns everything stored about the user as JSON files in a ZIP archive.
+// Secrets (password hash, TOTP secret, token digests) are left out.
+func (cfg *apiConfig) exportAccountHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Authorization
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	userid, err := cfg.validateAccessToken(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	user, err := cfg.dbQueries.GetUserFromUserID(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	chirps, err := cfg.allChirpsByUser(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	tokens, err := cfg.dbQueries.ListRefreshTokensByUser(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	events, err := cfg.dbQueries.ListWebhookEventsByUser(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	follows, err := cfg.dbQueries.ListFollowsByUser(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	likes, err := cfg.dbQueries.ListLikesByUser(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", cfg.exportProfile(user)},
+		{"chirps.json", exportChirps(user, chirps)},
+		{"sessions.json", exportSessions(tokens)},
+		{"webhooks.json", exportWebhookEvents(events)},
+		{"follows.json", exportFollows(userid, follows)},
+		{"likes.json", exportLikes(likes)},
+	}
+
+	// Built in memory, so a failure can still be reported as an error response.
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err == nil {
+			enc := json.NewEncoder(fw)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(f.data)
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Fail to Build export")
+			return
+		}
+	}
+	if err := zw.Close(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Fail to Build export")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="chirpy-export-%s.zip"`, time.Now().UTC().Format("20060102")))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// allChirpsByUser pages through the user's chirps, oldest first.
+func (cfg *apiConfig) allChirpsByUser(ctx context.Context, userID uuid.UUID) ([]database.Chirp, error) {
+	var all []database.Chirp
+	arg := database.ListChirpsAscParams{
+		AuthorID: uuid.NullUUID{UUID: userID, Valid: true},
+		Limit:    maxChirpsLimit,
+	}
+	for {
+		page, err := cfg.dbQueries.ListChirpsAsc(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < maxChirpsLimit {
+			return all, nil
+		}
+		last := page[len(page)-1]
+		arg.CursorCreatedAt = sql.NullTime{Time: last.CreatedAt, Valid: true}
+		arg.CursorID = uuid.NullUUID{UUID: last.ID, Valid: true}
+	}
+}
+
+func (cfg *apiConfig) exportProfile(user database.User) map[string]any {
+	profile := cfg.publicProfile(user)
+	profile["email"] = user.Email
+	profile["email_verified"] = user.EmailVerifiedAt.Valid
+	profile["role"] = user.Role
+	profile["two_factor_enabled"] = user.TotpEnabledAt.Valid
+	profile["updated_at"] = user.UpdatedAt.String()
+	return profile
+}
+
+// exportChirps renders the chirps as the API does, without the viewer-specific counts.
+func exportChirps(user database.User, chirps []database.Chirp) []map[string]any {
+	out := []map[string]any{}
+	for _, chirp := range chirps {
+		out = append(out, chirpJSON(chirp, user))
+	}
+	return out
+}
+
+// exportSessions lists every refresh token issued, revoked ones included. Each entry is one
+// login or refresh; session_id groups the entries of one login.
+func exportSessions(tokens []database.RefreshToken) []map[string]any {
+	out := []map[string]any{}
+	for _, rt := range tokens {
+		entry := map[string]any{
+			"session_id":         rt.FamilyID.String(),
+			"session_started_at": rt.SessionStartedAt.String(),
+			"issued_at":          rt.CreatedAt.String(),
+			"expires_at":         rt.ExpiresAt.String(),
+			"user_agent":         rt.UserAgent,
+			"ip_address":         rt.IpAddress,
+			"revoked_at":         nil,
+		}
+		if rt.RevokedAt.Valid {
+			entry["revoked_at"] = rt.RevokedAt.Time.String()
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func exportWebhookEvents(events []database.WebhookEvent) []map[string]any {
+	out := []map[string]any{}
+	for _, e := range events {
+		var payload any = e.Payload
+		if json.Valid([]byte(e.Payload)) {
+			payload = json.RawMessage(e.Payload)
+		}
+		out = append(out, map[string]any{
+			"id":          e.ID.String(),
+			"received_at": e.CreatedAt.String(),
+			"event":       e.Event,
+			"payload":     payload,
+		})
+	}
+	return out
+}
+
+// exportFollows splits the user's follows into the accounts they follow and the accounts following them.
+func exportFollows(userID uuid.UUID, follows []database.Follow) map[string][]map[string]string {
+	out := map[string][]map[string]string{
+		"following": {},
+		"followers": {},
+	}
+	for _, f := range follows {
+		if f.FollowerID == userID {
+			out["following"] = append(out["following"], map[string]string{
+				"user_id":     f.FolloweeID.String(),
+				"followed_at": f.CreatedAt.String(),
+			})
+		} else {
+			out["followers"] = append(out["followers"], map[string]string{
+				"user_id":     f.FollowerID.String(),
+				"followed_at": f.CreatedAt.String(),
+			})
+		}
+	}
+	return out
+}
+
+func exportLikes(likes []database.Like) []map[string]string {
+	out := []map[string]string{}
+	for _, l := range likes {
+		out = append(out, map[string]string{
+			"chirp_id": l.ChirpID.String(),
+			"liked_at": l.CreatedAt.String(),
+		})
+	}
+	return out
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// sendWebhook posts a Polka event with the given API key.
+func sendWebhook(t *testing.T, h http.Handler, apiKey, event, userID string) int {
+	t.Helper()
+	body := `{"event":"` + event + `","data":{"user_id":"` + userID + `"}}`
+	req := httptest.NewRequest("POST", "/api/polka/webhooks", strings.NewReader(body))
+	req.Header.Set("Authorization", "ApiKey "+apiKey)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestAccountExport(t *testing.T) {
+	cfg := newTestConfig()
+	h := cfg.routes()
+	login := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+	kim := signup(t, h, "kim@wexler.com", "correct horse battery staple")
+
+	var chirp struct {
+		ID string `json:"id"`
+	}
+	if rec := doRequest(t, h, "POST", "/api/chirps", login.Token, map[string]string{"body": "I know a guy"}, &chirp); rec.Code != http.StatusCreated {
+		t.Fatalf("create chirp: status %d", rec.Code)
+	}
+	var kimChirp struct {
+		ID string `json:"id"`
+	}
+	if rec := doRequest(t, h, "POST", "/api/chirps", kim.Token, map[string]string{"body": "We're done"}, &kimChirp); rec.Code != http.StatusCreated {
+		t.Fatalf("create Kim's chirp: status %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/chirps", login.Token, map[string]string{"body": "It's all good, man", "in_reply_to": kimChirp.ID}, nil); rec.Code != http.StatusCreated {
+		t.Fatalf("reply: status %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/chirps/"+kimChirp.ID+"/rechirp", login.Token, nil, nil); rec.Code != http.StatusCreated {
+		t.Fatalf("rechirp: status %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/users/"+kim.ID+"/follow", login.Token, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("follow: status %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/users/"+login.ID+"/follow", kim.Token, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("follow back: status %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/chirps/"+chirp.ID+"/like", login.Token, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("like: status %d", rec.Code)
+	}
+	if code := sendWebhook(t, h, "wrongkey", EventUserUpgraded, login.ID); code != http.StatusUnauthorized {
+		t.Errorf("webhook with a wrong key: status %d, want 401", code)
+	}
+	if code := sendWebhook(t, h, cfg.polka_key, EventUserUpgraded, login.ID); code != http.StatusNoContent {
+		t.Fatalf("webhook: status %d", code)
+	}
+
+	if rec := doRequest(t, h, "GET", "/api/users/export", "", nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("export without a token: status %d, want 401", rec.Code)
+	}
+	rec := doRequest(t, h, "GET", "/api/users/export", login.Token, nil, nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("export: status %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatalf("export is not a ZIP archive: %v", err)
+	}
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+
+	var profile map[string]any
+	json.Unmarshal(files["profile.json"], &profile)
+	if profile["email"] != "saul@bettercall.com" || profile["id"] != login.ID {
+		t.Errorf("profile.json = %s", files["profile.json"])
+	}
+	var chirps []map[string]string
+	if json.Unmarshal(files["chirps.json"], &chirps); len(chirps) != 3 || chirps[0]["body"] != "I know a guy" ||
+		chirps[1]["in_reply_to_id"] != kimChirp.ID || chirps[1]["conversation_id"] != kimChirp.ID || chirps[1]["rechirp_of_id"] != "" ||
+		chirps[2]["rechirp_of_id"] != kimChirp.ID || chirps[2]["in_reply_to_id"] != "" || chirps[2]["user_id"] != login.ID {
+		t.Errorf("chirps.json = %s", files["chirps.json"])
+	}
+	var sessions []map[string]any
+	if json.Unmarshal(files["sessions.json"], &sessions); len(sessions) != 1 {
+		t.Errorf("sessions.json = %s", files["sessions.json"])
+	}
+	var webhooks []struct {
+		Event   string          `json:"event"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	if json.Unmarshal(files["webhooks.json"], &webhooks); len(webhooks) != 1 || webhooks[0].Event != EventUserUpgraded || !bytes.Contains(webhooks[0].Payload, []byte(login.ID)) {
+		t.Errorf("webhooks.json = %s", files["webhooks.json"])
+	}
+	var follows map[string][]map[string]string
+	json.Unmarshal(files["follows.json"], &follows)
+	if len(follows["following"]) != 1 || follows["following"][0]["user_id"] != kim.ID || len(follows["followers"]) != 1 || follows["followers"][0]["user_id"] != kim.ID {
+		t.Errorf("follows.json = %s", files["follows.json"])
+	}
+	var likes []map[string]string
+	if json.Unmarshal(files["likes.json"], &likes); len(likes) != 1 || likes[0]["chirp_id"] != chirp.ID {
+		t.Errorf("likes.json = %s", files["likes.json"])
+	}
+
+	for name, data := range files {
+		for _, secret := range []string{"hashed_password", "totp_secret", "token_hash", login.RefreshToken} {
+			if bytes.Contains(data, []byte(secret)) {
+				t.Errorf("%s contains %q", name, secret)
+			}
+		}
+	}
+}
+
+func TestAccountDeletion(t *testing.T) {
+	cfg := newTestConfig()
+	h := cfg.routes()
+	login := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+	if rec := doRequest(t, h, "PATCH", "/api/users", login.Token, map[string]string{"handle": "saulgoodman"}, nil); rec.Code != http.StatusOK {
+		t.Fatalf("set handle: status %d", rec.Code)
+	}
+
+	if rec := doRequest(t, h, "DELETE", "/api/users", login.Token, map[string]string{}, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("delete without a password: status %d, want 400", rec.Code)
+	}
+	if rec := doRequest(t, h, "DELETE", "/api/users", login.Token, map[string]string{"password": "wrong"}, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("delete with a wrong password: status %d, want 403", rec.Code)
+	}
+	if rec := doRequest(t, h, "DELETE", "/api/users", login.Token, map[string]string{"password": "correct horse battery staple"}, nil); rec.Code != http.StatusAccepted {
+		t.Fatalf("delete: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	cfg.mailer.(*testMailer).last(t, "saul@bettercall.com")
+
+	// Signed out everywhere and hidden, but not gone yet.
+	if rec := doRequest(t, h, "GET", "/api/users/export", login.Token, nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("access token after deletion: status %d, want 401", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/refresh", login.RefreshToken, nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("refresh token after deletion: status %d, want 401", rec.Code)
+	}
+	if rec := doRequest(t, h, "GET", "/api/users/saulgoodman", "", nil, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("profile of an account being deleted: status %d, want 404", rec.Code)
+	}
+	if n, err := cfg.purgeDeletedAccounts(context.Background()); err != nil || n != 0 {
+		t.Fatalf("purge during the grace period = %d, %v", n, err)
+	}
+
+	// Logging in keeps the account.
+	creds := map[string]string{"email": "saul@bettercall.com", "password": "correct horse battery staple"}
+	var relogin struct {
+		Token             string `json:"token"`
+		DeletionCancelled bool   `json:"deletion_cancelled"`
+	}
+	if rec := doRequest(t, h, "POST", "/api/login", "", creds, &relogin); rec.Code != http.StatusOK || !relogin.DeletionCancelled {
+		t.Fatalf("login during the grace period: status %d, cancelled %v", rec.Code, relogin.DeletionCancelled)
+	}
+	if rec := doRequest(t, h, "GET", "/api/users/saulgoodman", "", nil, nil); rec.Code != http.StatusOK {
+		t.Errorf("profile after cancelling: status %d, want 200", rec.Code)
+	}
+
+	// Without a grace period the next purge removes everything.
+	cfg.accountDeletionGrace = 0
+	if rec := doRequest(t, h, "DELETE", "/api/users", relogin.Token, map[string]string{"password": "correct horse battery staple"}, nil); rec.Code != http.StatusAccepted {
+		t.Fatalf("delete again: status %d", rec.Code)
+	}
+	if n, err := cfg.purgeDeletedAccounts(context.Background()); err != nil || n != 1 {
+		t.Fatalf("purge after the grace period = %d, %v; want 1", n, err)
+	}
+	if rec := doRequest(t, h, "POST", "/api/login", "", creds, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("login after the hard delete: status %d, want 401", rec.Code)
+	}
+}
+
+func TestAccountDeletionHidesChirps(t *testing.T) {
+	cfg := newTestConfig()
+	h := cfg.routes()
+	saul := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+	kim := signup(t, h, "kim@wexler.com", "correct horse battery staple")
+
+	var chirp struct {
+		ID string `json:"id"`
+	}
+	if rec := doRequest(t, h, "POST", "/api/chirps", saul.Token, map[string]string{"body": "It's all good, man"}, &chirp); rec.Code != http.StatusCreated {
+		t.Fatalf("create chirp: status %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/users/"+saul.ID+"/follow", kim.Token, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("follow: status %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/chirps/"+chirp.ID+"/like", kim.Token, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("like: status %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/chirps", kim.Token, map[string]string{"body": "Is it?", "quoted_id": chirp.ID}, nil); rec.Code != http.StatusCreated {
+		t.Fatalf("quote: status %d", rec.Code)
+	}
+
+	if rec := doRequest(t, h, "DELETE", "/api/users", saul.Token, map[string]string{"password": "correct horse battery staple"}, nil); rec.Code != http.StatusAccepted {
+		t.Fatalf("delete: status %d", rec.Code)
+	}
+
+	var all struct {
+		Chirps []chirpWithRepost `json:"chirps"`
+	}
+	if rec := doRequest(t, h, "GET", "/api/chirps", "", nil, &all); rec.Code != http.StatusOK {
+		t.Fatalf("list chirps: status %d", rec.Code)
+	}
+	if len(all.Chirps) != 1 || all.Chirps[0].Body != "Is it?" || all.Chirps[0].Quoted == nil || !all.Chirps[0].Quoted.Deleted {
+		t.Errorf("chirps during the grace period = %+v", all.Chirps)
+	}
+	if rec := doRequest(t, h, "GET", "/api/chirps/"+chirp.ID, "", nil, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("chirp of an account being deleted: status %d, want 404", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/chirps/"+chirp.ID+"/rechirp", kim.Token, nil, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("rechirp of an account being deleted: status %d, want 404", rec.Code)
+	}
+	var timeline chirpsPage
+	if rec := doRequest(t, h, "GET", "/api/timeline", kim.Token, nil, &timeline); rec.Code != http.StatusOK || len(timeline.Chirps) != 0 {
+		t.Errorf("timeline during the grace period: status %d, chirps %+v", rec.Code, timeline.Chirps)
+	}
+	var likes chirpsPage
+	if rec := doRequest(t, h, "GET", "/api/users/"+kim.ID+"/likes", "", nil, &likes); rec.Code != http.StatusOK || len(likes.Chirps) != 0 {
+		t.Errorf("likes during the grace period: status %d, chirps %+v", rec.Code, likes.Chirps)
+	}
+
+	// Logging in cancels the deletion and brings the chirps back.
+	creds := map[string]string{"email": "saul@bettercall.com", "password": "correct horse battery staple"}
+	if rec := doRequest(t, h, "POST", "/api/login", "", creds, nil); rec.Code != http.StatusOK {
+		t.Fatalf("login: status %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "GET", "/api/chirps/"+chirp.ID, "", nil, nil); rec.Code != http.StatusOK {
+		t.Errorf("chirp after cancelling: status %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// adminRevokeTokensHandler signs a user out everywhere: refresh tokens are revoked and
+// access tokens issued so far stop validating.
+func (cfg *apiConfig) adminRevokeTokensHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	_, err = cfg.dbQueries.GetUserFromUserID(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "user not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	err = cfg.dbQueries.RevokeAllRefreshTokensForUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Fail to Revoke refresh tokens")
+		return
+	}
+
+	err = cfg.revokeAccessTokens(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Fail to Revoke access tokens")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// adminUnlockHandler lifts a login lockout before it expires.
+func (cfg *apiConfig) adminUnlockHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	user, err := cfg.dbQueries.GetUserFromUserID(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "user not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	err = cfg.dbQueries.ClearLoginAttempts(r.Context(), loginEmailKey(user.Email))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
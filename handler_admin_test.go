@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestAdminEndpointsRequireAdminRole(t *testing.T) {
+	cfg := newTestConfig()
+	h := cfg.routes()
+	login := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+	other := signup(t, h, "kim@wexlermcgill.com", "correct horse battery staple")
+
+	if rec := doRequest(t, h, "GET", "/admin/metrics", "", nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("metrics without a token: status %d, want 401", rec.Code)
+	}
+	if rec := doRequest(t, h, "GET", "/admin/metrics", login.Token, nil, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("metrics as a regular user: status %d, want 403", rec.Code)
+	}
+
+	if err := runAdminCommand(context.Background(), cfg.dbQueries, []string{"promote", "saul@bettercall.com"}); err != nil {
+		t.Fatalf("promote: %v", err)
+	}
+
+	// The role change revokes tokens carrying the old role.
+	if rec := doRequest(t, h, "GET", "/admin/metrics", login.Token, nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("metrics with a token issued before promotion: status %d, want 401", rec.Code)
+	}
+
+	var admin loginResponse
+	creds := map[string]string{"email": "saul@bettercall.com", "password": "correct horse battery staple"}
+	if rec := doRequest(t, h, "POST", "/api/login", "", creds, &admin); rec.Code != http.StatusOK {
+		t.Fatalf("login as admin: status %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "GET", "/admin/metrics", admin.Token, nil, nil); rec.Code != http.StatusOK {
+		t.Errorf("metrics as admin: status %d, want 200", rec.Code)
+	}
+
+	if rec := doRequest(t, h, "POST", "/admin/users/"+other.ID+"/revoke-tokens", other.Token, nil, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("revoke-tokens as a regular user: status %d, want 403", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/admin/users/"+other.ID+"/revoke-tokens", admin.Token, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("revoke-tokens as admin: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if rec := doRequest(t, h, "POST", "/api/refresh", other.RefreshToken, nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("refresh after admin revoke: status %d, want 401", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/chirps", other.Token, map[string]string{"body": "hi"}, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("create chirp after admin revoke: status %d, want 401", rec.Code)
+	}
+}
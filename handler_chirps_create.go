@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"regexp"
@@ -10,11 +12,14 @@ import (
 
 	"github.com/Tadateki/Chirpy/internal/auth"
 	"github.com/Tadateki/Chirpy/internal/database"
+	"github.com/google/uuid"
 )
 
 func (cfg *apiConfig) chirpsHandler(w http.ResponseWriter, r *http.Request) {
 	type createChirpRequest struct {
-		Body string `json:"body"`
+		Body      string `json:"body"`
+		InReplyTo string `json:"in_reply_to"`
+		QuotedID  string `json:"quoted_id"`
 	}
 	// JSONをパース
 	var req createChirpRequest
@@ -26,14 +31,28 @@ func (cfg *apiConfig) chirpsHandler(w http.ResponseWriter, r *http.Request) {
 	token, err := auth.GetBearerToken(r.Header)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "No authorization in hader")
+		return
 	}
 
-	user, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	user, err := cfg.validateAccessToken(r.Context(), token)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "Authorization Failure")
 		return
 	}
 
+	// Policy: only verified accounts may post
+	if cfg.requireVerifiedEmail {
+		u, err := cfg.dbQueries.GetUserFromUserID(r.Context(), user)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+			return
+		}
+		if !u.EmailVerifiedAt.Valid {
+			respondWithError(w, http.StatusForbidden, "email address is not verified")
+			return
+		}
+	}
+
 	if req.Body == "" {
 		respondWithError(w, http.StatusBadRequest, "Missing body")
 		return
@@ -45,6 +64,47 @@ func (cfg *apiConfig) chirpsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A reply joins its parent's conversation. Deleted chirps take no new replies.
+	var inReplyToID, conversationID uuid.NullUUID
+	if req.InReplyTo != "" {
+		parentID, err := uuid.Parse(req.InReplyTo)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid in_reply_to")
+			return
+		}
+		parent, err := cfg.liveChirp(r.Context(), parentID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, http.StatusNotFound, "chirp to reply to not found")
+			} else {
+				respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+			}
+			return
+		}
+		inReplyToID = uuid.NullUUID{UUID: parent.ID, Valid: true}
+		conversationID = uuid.NullUUID{UUID: parent.ConversationID, Valid: true}
+	}
+
+	// A quote chirp embeds the chirp it quotes.
+	var quotedID uuid.NullUUID
+	if req.QuotedID != "" {
+		id, err := uuid.Parse(req.QuotedID)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid quoted_id")
+			return
+		}
+		quoted, err := cfg.liveChirp(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, http.StatusNotFound, "chirp to quote not found")
+			} else {
+				respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+			}
+			return
+		}
+		quotedID = uuid.NullUUID{UUID: quoted.ID, Valid: true}
+	}
+
 	// NGワードフィルタリング
 	cleaned_body := replaceNGWords(req.Body)
 	//respondWithJSON(w, http.StatusOK, map[string]string{"cleaned_body": cleaned_body})
@@ -54,8 +114,11 @@ func (cfg *apiConfig) chirpsHandler(w http.ResponseWriter, r *http.Request) {
 	defer cancel()
 
 	arg := database.CreateChirpParams{
-		Body:   cleaned_body,
-		UserID: user,
+		Body:           cleaned_body,
+		UserID:         user,
+		InReplyToID:    inReplyToID,
+		ConversationID: conversationID,
+		QuotedID:       quotedID,
 	}
 
 	chirp, err := cfg.dbQueries.CreateChirp(ctx, arg)
@@ -64,15 +127,15 @@ func (cfg *apiConfig) chirpsHandler(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
 		return
 	}
+	cfg.timeline.enqueue(chirp)
 
 	// 作成した Chirp の情報を返す
-	respondWithJSON(w, http.StatusCreated, map[string]string{
-		"id":         chirp.ID.String(),
-		"created_at": chirp.CreatedAt.String(),
-		"updated_at": chirp.UpdatedAt.String(),
-		"body":       chirp.Body,
-		"user_id":    chirp.UserID.String(),
-	})
+	details, err := cfg.loadChirpDetails(ctx, uuid.NullUUID{UUID: user, Valid: true}, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, details.json(chirp))
 
 }
 
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"log"
 	"net/http"
 
 	"github.com/Tadateki/Chirpy/internal/auth"
@@ -18,7 +20,7 @@ func (cfg *apiConfig) deleteChirpyHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	userid, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	userid, err := cfg.validateAccessToken(r.Context(), token)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
 		return
@@ -42,6 +44,10 @@ func (cfg *apiConfig) deleteChirpyHandler(w http.ResponseWriter, r *http.Request
 		}
 		return
 	}
+	if chirp.DeletedAt.Valid {
+		respondWithError(w, http.StatusNotFound, "chirp not found")
+		return
+	}
 
 	//Check if userid is chirp's userid
 	if userid != chirp.UserID {
@@ -49,12 +55,52 @@ func (cfg *apiConfig) deleteChirpyHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	err = cfg.dbQueries.DeleteChirp(r.Context(), chirp.ID)
+	// A chirp with replies stays as a tombstone so its thread holds together. Its rechirps
+	// go away as they would with the chirp. Quotes keep its ID either way (quoted_id has
+	// no foreign key) and show it as deleted.
+	replies, err := cfg.dbQueries.CountReplies(r.Context(), chirp.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	if replies > 0 {
+		err = cfg.dbQueries.TombstoneChirp(r.Context(), chirp.ID)
+		if err == nil {
+			err = cfg.dbQueries.DeleteTimelineEntriesForChirp(r.Context(), chirp.ID)
+		}
+		if err == nil {
+			err = cfg.dbQueries.DeleteRechirpsOf(r.Context(), chirp.ID)
+		}
+	} else {
+		err = cfg.dbQueries.DeleteChirp(r.Context(), chirp.ID)
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Fail to Delete chirp")
 		return
 	}
+	if replies == 0 {
+		cfg.pruneTombstones(r.Context(), chirp.InReplyToID)
+	}
 
 	w.WriteHeader(http.StatusNoContent)
 
 }
+
+// pruneTombstones removes deleted parents, starting at parentID, that no longer have replies.
+func (cfg *apiConfig) pruneTombstones(ctx context.Context, parentID uuid.NullUUID) {
+	for parentID.Valid {
+		parent, err := cfg.dbQueries.GetChirp(ctx, parentID.UUID)
+		if err != nil || !parent.DeletedAt.Valid {
+			return
+		}
+		replies, err := cfg.dbQueries.CountReplies(ctx, parent.ID)
+		if err != nil || replies > 0 {
+			return
+		}
+		if err := cfg.dbQueries.DeleteChirp(ctx, parent.ID); err != nil {
+			log.Printf("prune deleted chirp %s: %v", parent.ID, err)
+			return
+		}
+		parentID = parent.InReplyToID
+	}
+}
@@ -1,10 +1,11 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"net/http"
-	"sort"
+	"strings"
 
 	"github.com/Tadateki/Chirpy/internal/database"
 	"github.com/google/uuid"
@@ -13,71 +14,77 @@ import (
 const (
 	ORDER_ASC = "asc"
 	ORDER_DSC = "desc"
+
+	defaultChirpsLimit = 50
+	maxChirpsLimit     = 100
 )
 
 func (cfg *apiConfig) getchirpsHandler(w http.ResponseWriter, r *http.Request) {
-	s := r.URL.Query().Get("author_id")
+	query := r.URL.Query()
+	s := query.Get("author_id")
 	var err error
-	var user database.User
-	var userID uuid.UUID
+	var authorID uuid.NullUUID
 
 	// Author ID LOOKUP
 	if s != "" {
-		userID, err = uuid.Parse(s)
+		userID, err := uuid.Parse(s)
 		if err != nil {
 			respondWithError(w, http.StatusBadRequest, "invalid User ID")
 			return
 		}
 		// User Check
-		user, err = cfg.dbQueries.GetUserFromUserID(r.Context(), userID)
+		user, err := cfg.dbQueries.GetUserFromUserID(r.Context(), userID)
 		if err != nil {
 			respondWithJSON(w, http.StatusNoContent, "")
 			//respondWithError(w, http.StatusNoContent, "No Chirps for the author")
 			return
 		}
-
+		authorID = uuid.NullUUID{UUID: user.ID, Valid: true}
 	}
 
-	// Chirps Lookup
-	var chirps []database.Chirp
-
-	if s == "" {
-		// DBからChirpsを取得
-		chirps, err = cfg.dbQueries.GetChirps(r.Context())
-		if err != nil {
-			respondWithError(w, http.StatusInternalServerError, "ERR_DB")
-			return
-		}
-	} else {
-		chirps, err = cfg.dbQueries.GetChirpsByAuthor(r.Context(), user.ID)
-		if err != nil {
-			respondWithError(w, http.StatusInternalServerError, "ERR_DB")
-			return
-		}
+	// Sort order (default: ASC)
+	order := strings.ToLower(query.Get("sort"))
+	if order == "" {
+		order = ORDER_ASC
 	}
-
-	// Order by created_at ASC is done in SQL
-
-	order := r.URL.Query().Get("sort")
 	if order != ORDER_ASC && order != ORDER_DSC {
 		respondWithError(w, http.StatusBadRequest, "Invalid sort parameter; must be 'ASC' or 'DESC'")
-	} else if order == ORDER_DSC {
-		sort.Slice(chirps, func(i, j int) bool { return chirps[i].CreatedAt.After(chirps[j].CreatedAt) })
+		return
 	}
 
-	// Chirpsの情報を返す
-	var response []map[string]string
-	for _, chirp := range chirps {
-		response = append(response, map[string]string{
-			"id":         chirp.ID.String(),
-			"created_at": chirp.CreatedAt.String(),
-			"updated_at": chirp.UpdatedAt.String(),
-			"body":       chirp.Body,
-			"user_id":    chirp.UserID.String(),
+	p, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+
+	viewer, ok := cfg.optionalViewer(w, r)
+	if !ok {
+		return
+	}
+
+	// Chirps Lookup (one extra row tells us whether a next page exists)
+	var chirps []database.Chirp
+	if order == ORDER_ASC {
+		chirps, err = cfg.dbQueries.ListChirpsAsc(r.Context(), database.ListChirpsAscParams{
+			AuthorID:        authorID,
+			CursorCreatedAt: p.cursorCreatedAt,
+			CursorID:        p.cursorID,
+			Limit:           p.queryLimit(),
+		})
+	} else {
+		chirps, err = cfg.dbQueries.ListChirpsDesc(r.Context(), database.ListChirpsDescParams{
+			AuthorID:        authorID,
+			CursorCreatedAt: p.cursorCreatedAt,
+			CursorID:        p.cursorID,
+			Limit:           p.queryLimit(),
 		})
 	}
-	respondWithJSON(w, http.StatusOK, response)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
 
+	cfg.respondWithChirps(w, r, p, viewer, chirps)
 }
 
 func (cfg *apiConfig) getChirpByIDHandler(w http.ResponseWriter, r *http.Request) {
@@ -99,12 +106,297 @@ func (cfg *apiConfig) getChirpByIDHandler(w http.ResponseWriter, r *http.Request
 		}
 		return
 	}
-	respondWithJSON(w, http.StatusOK, map[string]string{
-		"id":         chirp.ID.String(),
-		"created_at": chirp.CreatedAt.String(),
-		"updated_at": chirp.UpdatedAt.String(),
-		"body":       chirp.Body,
-		"user_id":    chirp.UserID.String(),
+	// Deleted chirps only show up as placeholders in threads.
+	if chirp.DeletedAt.Valid {
+		respondWithError(w, http.StatusNotFound, "chirp not found")
+		return
+	}
+
+	viewer, ok := cfg.optionalViewer(w, r)
+	if !ok {
+		return
+	}
+
+	details, err := cfg.loadChirpDetails(r.Context(), viewer, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	if details.hidden(chirp) {
+		respondWithError(w, http.StatusNotFound, "chirp not found")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, details.json(chirp))
+
+}
+
+// liveChirp loads a chirp that can be replied to, quoted, liked or rechirped: a rechirp stands
+// for its original, and deleted chirps and chirps of accounts waiting for deletion are reported
+// as sql.ErrNoRows.
+func (cfg *apiConfig) liveChirp(ctx context.Context, id uuid.UUID) (database.Chirp, error) {
+	chirp, err := cfg.dbQueries.GetChirp(ctx, id)
+	if err == nil && chirp.RechirpOfID.Valid {
+		chirp, err = cfg.dbQueries.GetChirp(ctx, chirp.RechirpOfID.UUID)
+	}
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if chirp.DeletedAt.Valid {
+		return database.Chirp{}, sql.ErrNoRows
+	}
+	author, err := cfg.dbQueries.GetUserFromUserID(ctx, chirp.UserID)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if author.DeleteAfter.Valid {
+		return database.Chirp{}, sql.ErrNoRows
+	}
+	return chirp, nil
+}
+
+// pathChirp is liveChirp for the {chirpID} path value. On failure it has already responded.
+func (cfg *apiConfig) pathChirp(w http.ResponseWriter, r *http.Request) (database.Chirp, bool) {
+	id, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid chirp ID")
+		return database.Chirp{}, false
+	}
+
+	chirp, err := cfg.liveChirp(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "chirp not found")
+		} else {
+			respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		}
+		return database.Chirp{}, false
+	}
+	return chirp, true
+}
+
+// pathOriginalID resolves the {chirpID} path value like pathChirp, a rechirp standing for its
+// original, for undoing a like or rechirp. A missing or deleted chirp keeps its own ID, so undoing
+// stays possible and repeating it is not an error. On failure it has already responded.
+func (cfg *apiConfig) pathOriginalID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid chirp ID")
+		return uuid.Nil, false
+	}
+
+	chirp, err := cfg.dbQueries.GetChirp(r.Context(), id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return uuid.Nil, false
+	}
+	if err == nil && chirp.RechirpOfID.Valid {
+		return chirp.RechirpOfID.UUID, true
+	}
+	return id, true
+}
+
+// respondWithChirps writes one page of chirps fetched with p.queryLimit(), with their authors, likes and reposts.
+func (cfg *apiConfig) respondWithChirps(w http.ResponseWriter, r *http.Request, p page, viewer uuid.NullUUID, chirps []database.Chirp) {
+	nextCursor := ""
+	if len(chirps) > p.limit {
+		chirps = chirps[:p.limit]
+		last := chirps[len(chirps)-1]
+		nextCursor = p.next(w, r, last.CreatedAt, last.ID)
+	}
+
+	details, err := cfg.loadChirpDetails(r.Context(), viewer, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	// Chirpsの情報を返す
+	response := []map[string]any{}
+	for _, chirp := range chirps {
+		response = append(response, details.json(chirp))
+	}
+	respondWithJSON(w, http.StatusOK, map[string]any{
+		"chirps":      response,
+		"next_cursor": nextCursor,
 	})
+}
+
+// chirpDetails is what rendering chirps needs besides the chirps themselves.
+type chirpDetails struct {
+	chirps        map[uuid.UUID]database.Chirp // the chirps and the ones they rechirp or quote
+	authors       map[uuid.UUID]database.User
+	likeCounts    map[uuid.UUID]int64
+	likedByMe     map[uuid.UUID]bool
+	rechirpCounts map[uuid.UUID]int64
+	quoteCounts   map[uuid.UUID]int64
+}
+
+// loadChirpDetails loads the chirps that chirps rechirp or quote, then the authors, likes
+// and repost counts of all of them, with one query each.
+// liked_by_me is false for every chirp when there is no viewer.
+func (cfg *apiConfig) loadChirpDetails(ctx context.Context, viewer uuid.NullUUID, chirps []database.Chirp) (chirpDetails, error) {
+	d := chirpDetails{
+		chirps:        map[uuid.UUID]database.Chirp{},
+		likeCounts:    map[uuid.UUID]int64{},
+		likedByMe:     map[uuid.UUID]bool{},
+		rechirpCounts: map[uuid.UUID]int64{},
+		quoteCounts:   map[uuid.UUID]int64{},
+	}
+	for _, chirp := range chirps {
+		d.chirps[chirp.ID] = chirp
+	}
+	var embedded []uuid.UUID
+	for _, chirp := range chirps {
+		for _, ref := range []uuid.NullUUID{chirp.RechirpOfID, chirp.QuotedID} {
+			if _, ok := d.chirps[ref.UUID]; ref.Valid && !ok {
+				embedded = append(embedded, ref.UUID)
+			}
+		}
+	}
+	if len(embedded) > 0 {
+		originals, err := cfg.dbQueries.ListChirpsByIDs(ctx, embedded)
+		if err != nil {
+			return d, err
+		}
+		for _, chirp := range originals {
+			d.chirps[chirp.ID] = chirp
+		}
+	}
+
+	var all []database.Chirp
+	var ids []uuid.UUID
+	for id, chirp := range d.chirps {
+		all = append(all, chirp)
+		ids = append(ids, id)
+	}
+	authors, err := cfg.chirpAuthors(ctx, all)
+	if err != nil {
+		return d, err
+	}
+	d.authors = authors
+	if len(ids) == 0 {
+		return d, nil
+	}
+
+	likes, err := cfg.dbQueries.CountLikesByIDs(ctx, ids)
+	if err != nil {
+		return d, err
+	}
+	for _, row := range likes {
+		d.likeCounts[row.ChirpID] = row.LikeCount
+	}
+	rechirps, err := cfg.dbQueries.CountRechirpsByIDs(ctx, ids)
+	if err != nil {
+		return d, err
+	}
+	for _, row := range rechirps {
+		d.rechirpCounts[row.RechirpOfID.UUID] = row.RechirpCount
+	}
+	quotes, err := cfg.dbQueries.CountQuotesByIDs(ctx, ids)
+	if err != nil {
+		return d, err
+	}
+	for _, row := range quotes {
+		d.quoteCounts[row.QuotedID.UUID] = row.QuoteCount
+	}
+
+	if viewer.Valid {
+		liked, err := cfg.dbQueries.ListLikedChirpIDs(ctx, database.ListLikedChirpIDsParams{
+			UserID: viewer.UUID,
+			Ids:    ids,
+		})
+		if err != nil {
+			return d, err
+		}
+		for _, id := range liked {
+			d.likedByMe[id] = true
+		}
+	}
+	return d, nil
+}
+
+// json renders a chirp with its author, likes and reposts, and the chirp it rechirps or quotes
+// embedded as rechirp_of or quoted.
+func (d chirpDetails) json(chirp database.Chirp) map[string]any {
+	m := d.fields(chirp)
+	if chirp.RechirpOfID.Valid {
+		m["rechirp_of"] = d.embedded(chirp.RechirpOfID.UUID)
+	}
+	if chirp.QuotedID.Valid {
+		m["quoted"] = d.embedded(chirp.QuotedID.UUID)
+	}
+	return m
+}
+
+func (d chirpDetails) fields(chirp database.Chirp) map[string]any {
+	m := chirpJSON(chirp, d.authors[chirp.UserID])
+	m["like_count"] = d.likeCounts[chirp.ID]
+	m["liked_by_me"] = d.likedByMe[chirp.ID]
+	m["rechirp_count"] = d.rechirpCounts[chirp.ID]
+	m["quote_count"] = d.quoteCounts[chirp.ID]
+	return m
+}
+
+// hidden reports whether a chirp is deleted or its author's account is waiting for deletion.
+func (d chirpDetails) hidden(chirp database.Chirp) bool {
+	return chirp.DeletedAt.Valid || d.authors[chirp.UserID].DeleteAfter.Valid
+}
+
+// embedded renders a rechirped or quoted chirp one level deep. A hidden one keeps only its ID.
+func (d chirpDetails) embedded(id uuid.UUID) map[string]any {
+	chirp, ok := d.chirps[id]
+	if !ok || d.hidden(chirp) {
+		return map[string]any{"id": id.String(), "deleted": true}
+	}
+	return d.fields(chirp)
+}
+
+// chirpAuthors loads the authors of chirps with one query, keyed by user ID.
+func (cfg *apiConfig) chirpAuthors(ctx context.Context, chirps []database.Chirp) (map[uuid.UUID]database.User, error) {
+	var ids []uuid.UUID
+	seen := map[uuid.UUID]bool{}
+	for _, chirp := range chirps {
+		if !seen[chirp.UserID] {
+			seen[chirp.UserID] = true
+			ids = append(ids, chirp.UserID)
+		}
+	}
+
+	authors := map[uuid.UUID]database.User{}
+	if len(ids) == 0 {
+		return authors, nil
+	}
+	users, err := cfg.dbQueries.ListUsersByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		authors[u.ID] = u
+	}
+	return authors, nil
+}
 
+// chirpJSON renders a chirp with its author's public name; authors without a handle get empty strings,
+// as do in_reply_to_id, rechirp_of_id and quoted_id for a chirp that does not reply, rechirp or quote.
+func chirpJSON(chirp database.Chirp, author database.User) map[string]any {
+	return map[string]any{
+		"id":                  chirp.ID.String(),
+		"created_at":          chirp.CreatedAt.String(),
+		"updated_at":          chirp.UpdatedAt.String(),
+		"body":                chirp.Body,
+		"user_id":             chirp.UserID.String(),
+		"author_handle":       author.Handle.String,
+		"author_display_name": author.DisplayName,
+		"in_reply_to_id":      nullID(chirp.InReplyToID),
+		"conversation_id":     chirp.ConversationID.String(),
+		"rechirp_of_id":       nullID(chirp.RechirpOfID),
+		"quoted_id":           nullID(chirp.QuotedID),
+	}
+}
+
+func nullID(id uuid.NullUUID) string {
+	if !id.Valid {
+		return ""
+	}
+	return id.UUID.String()
 }
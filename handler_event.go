@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 
 	"github.com/Tadateki/Chirpy/internal/auth"
@@ -15,10 +16,12 @@ func (cfg *apiConfig) eventHandler(w http.ResponseWriter, r *http.Request) {
 	apikey, err := auth.GetAPIKey(r.Header)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "No authorization in hader")
+		return
 	}
 
 	if apikey != cfg.polka_key {
 		respondWithError(w, http.StatusUnauthorized, "API KEY is wrong")
+		return
 	}
 
 	type UserRequest struct {
@@ -28,9 +31,14 @@ func (cfg *apiConfig) eventHandler(w http.ResponseWriter, r *http.Request) {
 		} `json:"data"`
 	}
 
-	// JSON Purse
+	// JSON Purse (the raw body is kept for the user's webhook history)
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
 	var req UserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := json.Unmarshal(body, &req); err != nil {
 		respondWithError(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
@@ -48,6 +56,16 @@ func (cfg *apiConfig) eventHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = cfg.dbQueries.CreateWebhookEvent(r.Context(), database.CreateWebhookEventParams{
+		UserID:  user.ID,
+		Event:   req.Event,
+		Payload: string(body),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "DB Error")
+		return
+	}
+
 	// Event Check
 	switch req.Event {
 	case EventUserUpgraded:
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/Tadateki/Chirpy/internal/auth"
+	"github.com/Tadateki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// followHandler makes the caller follow the user in the path. Following twice is not an error.
+func (cfg *apiConfig) followHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Authorization
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	userid, err := cfg.validateAccessToken(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	followee, ok := cfg.pathUser(w, r)
+	if !ok {
+		return
+	}
+	if followee.ID == userid {
+		respondWithError(w, http.StatusBadRequest, "you cannot follow yourself")
+		return
+	}
+
+	n, err := cfg.dbQueries.FollowUser(r.Context(), database.FollowUserParams{
+		FollowerID: userid,
+		FolloweeID: followee.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	// Earlier chirps were fanned out before this follow existed.
+	if n > 0 {
+		err = cfg.dbQueries.BackfillTimeline(r.Context(), database.BackfillTimelineParams{
+			UserID:     userid,
+			FolloweeID: followee.ID,
+			Limit:      timelineBackfillLimit,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// unfollowHandler undoes followHandler. Unfollowing someone not followed is not an error.
+func (cfg *apiConfig) unfollowHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Authorization
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	userid, err := cfg.validateAccessToken(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	followeeID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid User ID")
+		return
+	}
+
+	_, err = cfg.dbQueries.UnfollowUser(r.Context(), database.UnfollowUserParams{
+		FollowerID: userid,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	err = cfg.dbQueries.DeleteTimelineEntriesByAuthor(r.Context(), database.DeleteTimelineEntriesByAuthorParams{
+		UserID:   userid,
+		AuthorID: followeeID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// followersHandler lists who follows the user in the path, most recent follows first.
+func (cfg *apiConfig) followersHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := cfg.pathUser(w, r)
+	if !ok {
+		return
+	}
+	p, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := cfg.dbQueries.ListFollowers(r.Context(), database.ListFollowersParams{
+		UserID:          user.ID,
+		CursorCreatedAt: p.cursorCreatedAt,
+		CursorID:        p.cursorID,
+		Limit:           p.queryLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	var users []database.ListFollowingRow
+	for _, row := range rows {
+		users = append(users, database.ListFollowingRow(row))
+	}
+	respondWithFollows(w, r, p, users)
+}
+
+// followingHandler lists who the user in the path follows, most recent follows first.
+func (cfg *apiConfig) followingHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := cfg.pathUser(w, r)
+	if !ok {
+		return
+	}
+	p, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := cfg.dbQueries.ListFollowing(r.Context(), database.ListFollowingParams{
+		UserID:          user.ID,
+		CursorCreatedAt: p.cursorCreatedAt,
+		CursorID:        p.cursorID,
+		Limit:           p.queryLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	respondWithFollows(w, r, p, rows)
+}
+
+// respondWithFollows writes one page of users fetched with p.queryLimit().
+// The cursor is the follow's created_at and the listed user's ID.
+func respondWithFollows(w http.ResponseWriter, r *http.Request, p page, rows []database.ListFollowingRow) {
+	nextCursor := ""
+	if len(rows) > p.limit {
+		rows = rows[:p.limit]
+		last := rows[len(rows)-1]
+		nextCursor = p.next(w, r, last.FollowedAt, last.ID)
+	}
+
+	users := []map[string]string{}
+	for _, row := range rows {
+		users = append(users, map[string]string{
+			"id":           row.ID.String(),
+			"handle":       row.Handle.String,
+			"display_name": row.DisplayName,
+			"avatar_url":   row.AvatarUrl,
+			"followed_at":  row.FollowedAt.String(),
+		})
+	}
+	respondWithJSON(w, http.StatusOK, map[string]any{
+		"users":       users,
+		"next_cursor": nextCursor,
+	})
+}
+
+// pathUser loads the user named by the {id} path value. Accounts waiting for deletion
+// are not found. On failure it has already responded.
+func (cfg *apiConfig) pathUser(w http.ResponseWriter, r *http.Request) (database.User, bool) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid User ID")
+		return database.User{}, false
+	}
+
+	user, err := cfg.dbQueries.GetUserFromUserID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "user not found")
+		} else {
+			respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		}
+		return database.User{}, false
+	}
+	if user.DeleteAfter.Valid {
+		respondWithError(w, http.StatusNotFound, "user not found")
+		return database.User{}, false
+	}
+	return user, true
+}
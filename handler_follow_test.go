@@ -0,0 +1,131 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type usersPage struct {
+	Users []struct {
+		ID     string `json:"id"`
+		Handle string `json:"handle"`
+	} `json:"users"`
+	NextCursor string `json:"next_cursor"`
+}
+
+type chirpsPage struct {
+	Chirps []struct {
+		Body   string `json:"body"`
+		UserID string `json:"user_id"`
+	} `json:"chirps"`
+	NextCursor string `json:"next_cursor"`
+}
+
+func TestFollowAndTimeline(t *testing.T) {
+	cfg := newTestConfig()
+	h := cfg.routes()
+	saul := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+	kim := signup(t, h, "kim@wexler.com", "correct horse battery staple")
+	mike := signup(t, h, "mike@ehrmantraut.com", "correct horse battery staple")
+
+	for _, c := range []struct {
+		login loginResponse
+		body  string
+	}{
+		{kim, "Pick a lane"},
+		{mike, "No half measures"},
+		{saul, "It's all good, man"},
+		{kim, "Remember me"},
+	} {
+		if rec := doRequest(t, h, "POST", "/api/chirps", c.login.Token, map[string]string{"body": c.body}, nil); rec.Code != http.StatusCreated {
+			t.Fatalf("create chirp: status %d", rec.Code)
+		}
+	}
+
+	follow := "/api/users/" + kim.ID + "/follow"
+	if rec := doRequest(t, h, "POST", follow, "", nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("follow without a token: status %d, want 401", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/users/"+saul.ID+"/follow", saul.Token, nil, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("follow oneself: status %d, want 400", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/users/"+uuid.NewString()+"/follow", saul.Token, nil, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("follow an unknown user: status %d, want 404", rec.Code)
+	}
+	for range 2 {
+		if rec := doRequest(t, h, "POST", follow, saul.Token, nil, nil); rec.Code != http.StatusNoContent {
+			t.Fatalf("follow: status %d, body %s", rec.Code, rec.Body.String())
+		}
+	}
+	if rec := doRequest(t, h, "POST", "/api/users/"+mike.ID+"/follow", saul.Token, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("follow: status %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", follow, mike.Token, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("follow: status %d", rec.Code)
+	}
+
+	// Newest first, without the caller's own chirps.
+	var timeline chirpsPage
+	if rec := doRequest(t, h, "GET", "/api/timeline?limit=2", saul.Token, nil, &timeline); rec.Code != http.StatusOK {
+		t.Fatalf("timeline: status %d", rec.Code)
+	}
+	if len(timeline.Chirps) != 2 || timeline.Chirps[0].Body != "Remember me" || timeline.Chirps[1].Body != "No half measures" || timeline.NextCursor == "" {
+		t.Fatalf("first timeline page = %+v", timeline)
+	}
+	var rest chirpsPage
+	if rec := doRequest(t, h, "GET", "/api/timeline?limit=2&cursor="+timeline.NextCursor, saul.Token, nil, &rest); rec.Code != http.StatusOK {
+		t.Fatalf("timeline page 2: status %d", rec.Code)
+	}
+	if len(rest.Chirps) != 1 || rest.Chirps[0].Body != "Pick a lane" || rest.NextCursor != "" {
+		t.Errorf("second timeline page = %+v", rest)
+	}
+	if rec := doRequest(t, h, "GET", "/api/timeline", "", nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("timeline without a token: status %d, want 401", rec.Code)
+	}
+
+	// Follower lists are public and paginated.
+	var followers usersPage
+	if rec := doRequest(t, h, "GET", "/api/users/"+kim.ID+"/followers?limit=1", "", nil, &followers); rec.Code != http.StatusOK {
+		t.Fatalf("followers: status %d", rec.Code)
+	}
+	if len(followers.Users) != 1 || followers.Users[0].ID != mike.ID || followers.NextCursor == "" {
+		t.Fatalf("first followers page = %+v", followers)
+	}
+	if rec := doRequest(t, h, "GET", "/api/users/"+kim.ID+"/followers?limit=1&cursor="+followers.NextCursor, "", nil, &followers); rec.Code != http.StatusOK {
+		t.Fatalf("followers page 2: status %d", rec.Code)
+	}
+	if len(followers.Users) != 1 || followers.Users[0].ID != saul.ID || followers.NextCursor != "" {
+		t.Errorf("second followers page = %+v", followers)
+	}
+	var following usersPage
+	if rec := doRequest(t, h, "GET", "/api/users/"+saul.ID+"/following", "", nil, &following); rec.Code != http.StatusOK || len(following.Users) != 2 {
+		t.Errorf("following = %+v, status %d", following, rec.Code)
+	}
+	if rec := doRequest(t, h, "GET", "/api/users/not-a-uuid/followers", "", nil, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("followers of a bad ID: status %d, want 400", rec.Code)
+	}
+
+	// The profile counts follows.
+	if rec := doRequest(t, h, "PATCH", "/api/users", kim.Token, map[string]string{"handle": "kimwexler"}, nil); rec.Code != http.StatusOK {
+		t.Fatalf("set handle: status %d", rec.Code)
+	}
+	var profile struct {
+		FollowerCount  int `json:"follower_count"`
+		FollowingCount int `json:"following_count"`
+	}
+	if rec := doRequest(t, h, "GET", "/api/users/kimwexler", "", nil, &profile); rec.Code != http.StatusOK || profile.FollowerCount != 2 || profile.FollowingCount != 0 {
+		t.Errorf("profile counts = %+v, status %d", profile, rec.Code)
+	}
+
+	// Unfollowing is idempotent and empties the timeline.
+	for _, id := range []string{kim.ID, kim.ID, mike.ID} {
+		if rec := doRequest(t, h, "DELETE", "/api/users/"+id+"/follow", saul.Token, nil, nil); rec.Code != http.StatusNoContent {
+			t.Fatalf("unfollow: status %d", rec.Code)
+		}
+	}
+	if rec := doRequest(t, h, "GET", "/api/timeline", saul.Token, nil, &timeline); rec.Code != http.StatusOK || len(timeline.Chirps) != 0 {
+		t.Errorf("timeline after unfollowing = %+v", timeline)
+	}
+}
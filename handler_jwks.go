@@ -0,0 +1,12 @@
+package main
+
+import (
+	"net/http"
+)
+
+// jwksHandler publishes the public keys access tokens are signed with,
+// so other services can verify Chirpy tokens without the signing key.
+func (cfg *apiConfig) jwksHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	respondWithJSON(w, http.StatusOK, cfg.jwtKeys.JWKS())
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/Tadateki/Chirpy/internal/auth"
+	"github.com/Tadateki/Chirpy/internal/database"
+)
+
+// likeHandler makes the caller like the chirp in the path, or the original of a rechirp.
+// Liking twice is not an error.
+func (cfg *apiConfig) likeHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Authorization
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	userid, err := cfg.validateAccessToken(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	chirp, ok := cfg.pathChirp(w, r)
+	if !ok {
+		return
+	}
+
+	_, err = cfg.dbQueries.LikeChirp(r.Context(), database.LikeChirpParams{
+		UserID:  userid,
+		ChirpID: chirp.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// unlikeHandler undoes likeHandler for the chirp in the path, or the original of a rechirp.
+// Unliking a chirp not liked is not an error.
+func (cfg *apiConfig) unlikeHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Authorization
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	userid, err := cfg.validateAccessToken(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	chirpID, ok := cfg.pathOriginalID(w, r)
+	if !ok {
+		return
+	}
+
+	_, err = cfg.dbQueries.UnlikeChirp(r.Context(), database.UnlikeChirpParams{
+		UserID:  userid,
+		ChirpID: chirpID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// userLikesHandler lists the chirps the user in the path liked, most recent likes first.
+func (cfg *apiConfig) userLikesHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := cfg.pathUser(w, r)
+	if !ok {
+		return
+	}
+	p, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+	viewer, ok := cfg.optionalViewer(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := cfg.dbQueries.ListLikedChirps(r.Context(), database.ListLikedChirpsParams{
+		UserID:          user.ID,
+		CursorCreatedAt: p.cursorCreatedAt,
+		CursorID:        p.cursorID,
+		Limit:           p.queryLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	// The cursor is the like's created_at and the chirp's ID.
+	nextCursor := ""
+	if len(rows) > p.limit {
+		rows = rows[:p.limit]
+		last := rows[len(rows)-1]
+		nextCursor = p.next(w, r, last.LikedAt, last.Chirp.ID)
+	}
+
+	var chirps []database.Chirp
+	for _, row := range rows {
+		chirps = append(chirps, row.Chirp)
+	}
+	details, err := cfg.loadChirpDetails(r.Context(), viewer, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	response := []map[string]any{}
+	for i, chirp := range chirps {
+		c := details.json(chirp)
+		c["liked_at"] = rows[i].LikedAt.String()
+		response = append(response, c)
+	}
+	respondWithJSON(w, http.StatusOK, map[string]any{
+		"chirps":      response,
+		"next_cursor": nextCursor,
+	})
+}
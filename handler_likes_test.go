@@ -0,0 +1,135 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+type likedChirp struct {
+	ID        string `json:"id"`
+	Body      string `json:"body"`
+	LikeCount int64  `json:"like_count"`
+	LikedByMe bool   `json:"liked_by_me"`
+	LikedAt   string `json:"liked_at"`
+}
+
+func TestLikes(t *testing.T) {
+	cfg := newTestConfig()
+	h := cfg.routes()
+	saul := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+	kim := signup(t, h, "kim@wexler.com", "correct horse battery staple")
+
+	var ids []string
+	for _, body := range []string{"Better call Saul", "S'all good, man"} {
+		var created struct {
+			ID string `json:"id"`
+		}
+		if rec := doRequest(t, h, "POST", "/api/chirps", saul.Token, map[string]string{"body": body}, &created); rec.Code != http.StatusCreated {
+			t.Fatalf("create chirp: status %d", rec.Code)
+		}
+		ids = append(ids, created.ID)
+	}
+
+	like := func(login loginResponse, id string) {
+		t.Helper()
+		if rec := doRequest(t, h, "POST", "/api/chirps/"+id+"/like", login.Token, nil, nil); rec.Code != http.StatusNoContent {
+			t.Fatalf("like: status %d", rec.Code)
+		}
+	}
+	like(kim, ids[0])
+	like(kim, ids[0]) // idempotent
+	like(saul, ids[0])
+	like(kim, ids[1])
+
+	if rec := doRequest(t, h, "POST", "/api/chirps/"+ids[0]+"/like", "", nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("like without a token: status %d, want 401", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/chirps/00000000-0000-0000-0000-000000000000/like", kim.Token, nil, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("like an unknown chirp: status %d, want 404", rec.Code)
+	}
+
+	get := func(bearer string) likedChirp {
+		t.Helper()
+		var c likedChirp
+		if rec := doRequest(t, h, "GET", "/api/chirps/"+ids[0], bearer, nil, &c); rec.Code != http.StatusOK {
+			t.Fatalf("get chirp: status %d", rec.Code)
+		}
+		return c
+	}
+	if c := get(kim.Token); c.LikeCount != 2 || !c.LikedByMe {
+		t.Errorf("chirp seen by a liker = %+v", c)
+	}
+	if c := get(""); c.LikeCount != 2 || c.LikedByMe {
+		t.Errorf("chirp seen anonymously = %+v", c)
+	}
+	if rec := doRequest(t, h, "GET", "/api/chirps/"+ids[0], "garbage", nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("get chirp with a bad token: status %d, want 401", rec.Code)
+	}
+
+	var list struct {
+		Chirps []likedChirp `json:"chirps"`
+	}
+	if rec := doRequest(t, h, "GET", "/api/chirps", kim.Token, nil, &list); rec.Code != http.StatusOK || len(list.Chirps) != 2 {
+		t.Fatalf("list chirps: status %d, %+v", rec.Code, list.Chirps)
+	}
+	for _, c := range list.Chirps {
+		if !c.LikedByMe {
+			t.Errorf("listed chirp %q not liked by me", c.Body)
+		}
+	}
+
+	// Kim's likes, most recent first, one per page.
+	var got []string
+	path := "/api/users/" + kim.ID + "/likes?limit=1"
+	for path != "" {
+		var page struct {
+			Chirps     []likedChirp `json:"chirps"`
+			NextCursor string       `json:"next_cursor"`
+		}
+		if rec := doRequest(t, h, "GET", path, "", nil, &page); rec.Code != http.StatusOK {
+			t.Fatalf("list likes: status %d", rec.Code)
+		}
+		for _, c := range page.Chirps {
+			if c.LikedAt == "" {
+				t.Errorf("liked chirp %q has no liked_at", c.Body)
+			}
+			got = append(got, c.ID)
+		}
+		path = ""
+		if page.NextCursor != "" {
+			path = "/api/users/" + kim.ID + "/likes?limit=1&cursor=" + page.NextCursor
+		}
+	}
+	if len(got) != 2 || got[0] != ids[1] || got[1] != ids[0] {
+		t.Errorf("kim's likes = %q, want %q", got, []string{ids[1], ids[0]})
+	}
+
+	if rec := doRequest(t, h, "DELETE", "/api/chirps/"+ids[0]+"/like", kim.Token, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("unlike: status %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "DELETE", "/api/chirps/"+ids[0]+"/like", kim.Token, nil, nil); rec.Code != http.StatusNoContent {
+		t.Errorf("unlike twice: status %d, want 204", rec.Code)
+	}
+	if c := get(kim.Token); c.LikeCount != 1 || c.LikedByMe {
+		t.Errorf("chirp after unliking = %+v", c)
+	}
+
+	// A like through a rechirp's ID goes to the original, and is undone through the same URL.
+	var rechirp struct {
+		ID string `json:"id"`
+	}
+	if rec := doRequest(t, h, "POST", "/api/chirps/"+ids[1]+"/rechirp", kim.Token, nil, &rechirp); rec.Code != http.StatusCreated {
+		t.Fatalf("rechirp: status %d", rec.Code)
+	}
+	like(saul, rechirp.ID)
+	var c likedChirp
+	if doRequest(t, h, "GET", "/api/chirps/"+ids[1], saul.Token, nil, &c); c.LikeCount != 2 || !c.LikedByMe {
+		t.Errorf("original after liking the rechirp = %+v", c)
+	}
+	if rec := doRequest(t, h, "DELETE", "/api/chirps/"+rechirp.ID+"/like", saul.Token, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("unlike through the rechirp: status %d", rec.Code)
+	}
+	if doRequest(t, h, "GET", "/api/chirps/"+ids[1], saul.Token, nil, &c); c.LikeCount != 1 || c.LikedByMe {
+		t.Errorf("original after unliking the rechirp = %+v", c)
+	}
+}
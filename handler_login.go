@@ -3,14 +3,17 @@ package main
 // All code comments should be written in English.
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/Tadateki/Chirpy/internal/auth"
 	"github.com/Tadateki/Chirpy/internal/database"
+	"github.com/google/uuid"
 )
 
 func (cfg *apiConfig) loginUserHandler(w http.ResponseWriter, r *http.Request) {
@@ -26,11 +29,24 @@ func (cfg *apiConfig) loginUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Brute-force protection
+	keys := cfg.loginKeys(req.Email, clientIP(r))
+	status, retryAfter, err := cfg.checkLoginThrottle(r.Context(), keys)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Database Error")
+		return
+	}
+	if status != 0 {
+		respondWithThrottle(w, status, retryAfter)
+		return
+	}
+
 	// User Lookup
 	user, err := cfg.dbQueries.GetUserFromEmail(r.Context(), req.Email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			respondWithError(w, http.StatusUnauthorized, "Incorrect email or password")
+			// Unknown emails count too, so lockouts do not reveal which accounts exist.
+			cfg.failLogin(w, r, keys)
 			return
 		}
 		respondWithError(w, http.StatusInternalServerError, "Database Error")
@@ -44,12 +60,82 @@ func (cfg *apiConfig) loginUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if !chk {
-		respondWithError(w, http.StatusUnauthorized, "Incorrect email or password")
+		cfg.failLogin(w, r, keys)
+		return
+	}
+
+	err = cfg.dbQueries.ClearLoginAttempts(r.Context(), loginEmailKey(user.Email))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Database Error")
+		return
+	}
+
+	// The plain password is only available here, so hashes made with weaker
+	// Argon2 parameters are upgraded now. A failure must not block the login.
+	cfg.rehashPassword(r.Context(), user, req.Password)
+
+	// Second factor: the password step only earns a short-lived MFA pending token.
+	if user.TotpEnabledAt.Valid {
+		mfaToken, err := cfg.jwtKeys.MakeMFAToken(user.ID, mfaTokenExpiresIn)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Token Generation Error")
+			return
+		}
+		respondWithJSON(w, http.StatusOK, map[string]any{
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+		})
+		return
+	}
+
+	cfg.respondWithLogin(w, r, user)
+}
+
+func (cfg *apiConfig) rehashPassword(ctx context.Context, user database.User, password string) {
+	needsRehash, err := cfg.passwordParams.NeedsRehash(user.HashedPassword)
+	if err != nil || !needsRehash {
+		return
+	}
+
+	HashedPassword, err := cfg.passwordParams.Hash(password)
+	if err != nil {
+		log.Printf("rehash password for user %s: %v", user.ID, err)
 		return
 	}
 
+	// Only replace the hash this login was checked against; a password change or reset
+	// that landed in the meantime wins.
+	_, err = cfg.dbQueries.RehashUserPassword(ctx, database.RehashUserPasswordParams{
+		NewHash: HashedPassword,
+		ID:      user.ID,
+		OldHash: user.HashedPassword,
+	})
+	if err != nil {
+		log.Printf("store rehashed password for user %s: %v", user.ID, err)
+	}
+}
+
+// failLogin records the failed attempt and answers 401.
+func (cfg *apiConfig) failLogin(w http.ResponseWriter, r *http.Request, keys []loginKey) {
+	if err := cfg.recordLoginFailure(r.Context(), keys); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Database Error")
+		return
+	}
+	respondWithError(w, http.StatusUnauthorized, "Incorrect email or password")
+}
+
+// respondWithLogin issues an access token and a refresh token that starts a new session.
+func (cfg *apiConfig) respondWithLogin(w http.ResponseWriter, r *http.Request, user database.User) {
+	// Logging in during the deletion grace period keeps the account.
+	if user.DeleteAfter.Valid {
+		if err := cfg.dbQueries.CancelUserDeletion(r.Context(), user.ID); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+			return
+		}
+	}
+
 	// JWT Token Generation
-	token, err := auth.MakeJWT(user.ID, cfg.tokenSecret, time.Duration(cfg.expires_in_seconds)*time.Second)
+	token, err := cfg.makeAccessToken(user)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Token Generation Error")
 		return
@@ -62,26 +148,32 @@ func (cfg *apiConfig) loginUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Only the digest is stored; the raw token is returned to the client once.
 	arg := database.StoreRefreshTokenParams{
-		Token:     ref_token_str,
+		TokenHash: auth.HashRefreshToken(ref_token_str),
 		UserID:    user.ID,
-		ExpiresAt: time.Now().Add(time.Duration(cfg.refresh_expires_in_hours) * time.Hour),
+		ExpiresAt: time.Now().UTC().Add(time.Duration(cfg.refresh_expires_in_hours) * time.Hour),
+		FamilyID:  uuid.New(), // a login starts a new rotation family
+		UserAgent: r.UserAgent(),
+		IpAddress: clientIP(r),
 	}
 
-	ref_token, err := cfg.dbQueries.StoreRefreshToken(r.Context(), arg)
+	_, err = cfg.dbQueries.StoreRefreshToken(r.Context(), arg)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Refresh Token Storing Error")
 		return
 	}
 
 	respondWithJSON(w, http.StatusOK, map[string]any{
-		"id":            user.ID.String(),
-		"created_at":    user.CreatedAt.String(),
-		"updated_at":    user.UpdatedAt.String(),
-		"email":         user.Email,
-		"token":         token,
-		"refresh_token": ref_token.Token,
-		"is_chirpy_red": user.IsChirpyRed,
+		"id":                 user.ID.String(),
+		"created_at":         user.CreatedAt.String(),
+		"updated_at":         user.UpdatedAt.String(),
+		"email":              user.Email,
+		"token":              token,
+		"refresh_token":      ref_token_str,
+		"is_chirpy_red":      user.IsChirpyRed,
+		"role":               user.Role,
+		"email_verified":     user.EmailVerifiedAt.Valid,
+		"deletion_cancelled": user.DeleteAfter.Valid,
 	})
-
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Tadateki/Chirpy/internal/auth"
+)
+
+func TestLoginRehashesWeakPasswordHash(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.passwordParams = auth.PasswordParams{Memory: 8 * 1024, Iterations: 1, Parallelism: 1}
+	h := cfg.routes()
+	signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+
+	ctx := context.Background()
+	before, _ := cfg.dbQueries.GetUserFromEmail(ctx, "saul@bettercall.com")
+
+	// Raise the cost; the next successful login upgrades the stored hash.
+	cfg.passwordParams = auth.PasswordParams{Memory: 16 * 1024, Iterations: 2, Parallelism: 1}
+	if needs, _ := cfg.passwordParams.NeedsRehash(before.HashedPassword); !needs {
+		t.Fatalf("hash made with the old params does not need a rehash")
+	}
+
+	creds := map[string]string{"email": "saul@bettercall.com", "password": "correct horse battery staple"}
+	if rec := doRequest(t, h, "POST", "/api/login", "", map[string]string{"email": "saul@bettercall.com", "password": "wrong"}, nil); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("login with a wrong password: status %d, want 401", rec.Code)
+	}
+	if after, _ := cfg.dbQueries.GetUserFromEmail(ctx, "saul@bettercall.com"); after.HashedPassword != before.HashedPassword {
+		t.Fatalf("failed login changed the password hash")
+	}
+
+	if rec := doRequest(t, h, "POST", "/api/login", "", creds, nil); rec.Code != http.StatusOK {
+		t.Fatalf("login: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	after, _ := cfg.dbQueries.GetUserFromEmail(ctx, "saul@bettercall.com")
+	if after.HashedPassword == before.HashedPassword {
+		t.Fatalf("login did not rehash the password")
+	}
+	if needs, _ := cfg.passwordParams.NeedsRehash(after.HashedPassword); needs {
+		t.Errorf("rehashed password still uses weaker params")
+	}
+
+	if rec := doRequest(t, h, "POST", "/api/login", "", creds, nil); rec.Code != http.StatusOK {
+		t.Errorf("login after rehash: status %d, want 200", rec.Code)
+	}
+}
+
+func TestRehashKeepsNewerPassword(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.passwordParams = auth.PasswordParams{Memory: 8 * 1024, Iterations: 1, Parallelism: 1}
+	h := cfg.routes()
+	login := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+
+	ctx := context.Background()
+	stale, _ := cfg.dbQueries.GetUserFromEmail(ctx, "saul@bettercall.com")
+
+	// A login that read the old hash finishes after the password was changed.
+	if rec := doRequest(t, h, "PUT", "/api/users", login.Token, map[string]string{"email": "saul@bettercall.com", "password": "tr0ub4dor and 3 saxophones"}, nil); rec.Code != http.StatusOK {
+		t.Fatalf("change password: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	changed, _ := cfg.dbQueries.GetUserFromEmail(ctx, "saul@bettercall.com")
+
+	cfg.passwordParams = auth.PasswordParams{Memory: 16 * 1024, Iterations: 2, Parallelism: 1}
+	cfg.rehashPassword(ctx, stale, "correct horse battery staple")
+
+	if after, _ := cfg.dbQueries.GetUserFromEmail(ctx, "saul@bettercall.com"); after.HashedPassword != changed.HashedPassword {
+		t.Fatalf("rehash with the old password overwrote the new one")
+	}
+}
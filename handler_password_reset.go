@@ -0,0 +1,172 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Tadateki/Chirpy/internal/auth"
+	"github.com/Tadateki/Chirpy/internal/database"
+	"github.com/Tadateki/Chirpy/internal/mailer"
+)
+
+const passwordResetExpiresIn = time.Hour
+
+// passwordResetHandler mails a single-use reset token. It answers 202 whether
+// or not the email is registered, and whether or not the mail could be sent,
+// so it cannot be used to enumerate accounts. Requests are limited per email
+// and per client IP.
+func (cfg *apiConfig) passwordResetHandler(w http.ResponseWriter, r *http.Request) {
+	type PasswordResetRequest struct {
+		Email string `json:"email"`
+	}
+
+	var req PasswordResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	retryAfter, err := cfg.checkResetThrottle(r.Context(), req.Email, clientIP(r))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	if retryAfter > 0 {
+		respondWithResetThrottle(w, retryAfter)
+		return
+	}
+
+	user, err := cfg.dbQueries.GetUserFromEmail(r.Context(), req.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	// Same generator and digest as refresh tokens; only the digest is stored.
+	reset_token_str, err := auth.MakeRefreshToken()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Reset Token Generation Error")
+		return
+	}
+
+	err = cfg.dbQueries.CreatePasswordResetToken(r.Context(), database.CreatePasswordResetTokenParams{
+		TokenHash: auth.HashRefreshToken(reset_token_str),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().UTC().Add(passwordResetExpiresIn),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	err = cfg.mailer.Send(r.Context(), mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Chirpy password",
+		Body: fmt.Sprintf("Someone asked to reset the password of your Chirpy account.\n\n"+
+			"Reset token: %s\n\n"+
+			"The token can be used once within %s. If this was not you, ignore this email.\n",
+			reset_token_str, passwordResetExpiresIn),
+	})
+	if err != nil {
+		log.Printf("password reset mail to %s: %v", user.Email, err)
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// passwordResetConfirmHandler sets a new password with a reset token and signs
+// the user out everywhere.
+func (cfg *apiConfig) passwordResetConfirmHandler(w http.ResponseWriter, r *http.Request) {
+	type PasswordResetConfirmRequest struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+
+	var req PasswordResetConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	tokenHash := auth.HashRefreshToken(req.Token)
+	userid, err := cfg.dbQueries.GetPasswordResetTokenUser(r.Context(), database.GetPasswordResetTokenUserParams{
+		TokenHash: tokenHash,
+		Now:       time.Now().UTC(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusBadRequest, "invalid or expired token")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	user, err := cfg.dbQueries.GetUserFromUserID(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	// Checked before the token is consumed, so a rejected password does not burn it.
+	if !cfg.checkPasswordPolicy(w, r, req.Password, user.Email) {
+		return
+	}
+
+	// Consuming is atomic, so a token cannot be used twice even concurrently.
+	userid, err = cfg.dbQueries.ConsumePasswordResetToken(r.Context(), database.ConsumePasswordResetTokenParams{
+		Now:       time.Now().UTC(),
+		TokenHash: tokenHash,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusBadRequest, "invalid or expired token")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	HashedPassword, err := cfg.passwordParams.Hash(req.Password)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Hash Password Fail")
+		return
+	}
+
+	err = cfg.dbQueries.SetUserPassword(r.Context(), database.SetUserPasswordParams{
+		HashedPassword: HashedPassword,
+		ID:             userid,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Fail to Update user")
+		return
+	}
+
+	// Other outstanding reset tokens and every session die with the old password.
+	err = cfg.dbQueries.InvalidatePasswordResetTokensForUser(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	err = cfg.dbQueries.RevokeAllRefreshTokensForUser(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Fail to Revoke refresh tokens")
+		return
+	}
+	err = cfg.revokeAccessTokens(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Fail to Revoke access tokens")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
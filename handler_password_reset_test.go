@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+var resetTokenRe = regexp.MustCompile(`Reset token: ([0-9a-f]+)`)
+
+func TestPasswordReset(t *testing.T) {
+	cfg := newTestConfig()
+	h := cfg.routes()
+	login := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+	mail := cfg.mailer.(*testMailer)
+
+	// Unknown emails get the same answer and no mail.
+	sent := len(mail.sent)
+	if rec := doRequest(t, h, "POST", "/api/password-reset", "", map[string]string{"email": "nobody@example.com"}, nil); rec.Code != http.StatusAccepted {
+		t.Errorf("reset for an unknown email: status %d, want 202", rec.Code)
+	}
+	if len(mail.sent) != sent {
+		t.Errorf("mail sent for an unknown email")
+	}
+
+	if rec := doRequest(t, h, "POST", "/api/password-reset", "", map[string]string{"email": "saul@bettercall.com"}, nil); rec.Code != http.StatusAccepted {
+		t.Fatalf("reset: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	m := resetTokenRe.FindStringSubmatch(mail.last(t, "saul@bettercall.com").Body)
+	if m == nil {
+		t.Fatalf("reset mail has no token")
+	}
+	token := m[1]
+
+	if rec := doRequest(t, h, "POST", "/api/password-reset/confirm", "", map[string]string{"token": "bogus", "password": "tr0ub4dor and 3 saxophones"}, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("confirm with a bogus token: status %d, want 400", rec.Code)
+	}
+
+	// A password made from the account's email is rejected without using up the token.
+	weak := map[string]string{"token": token, "password": "bettercall saul goodman"}
+	if rec := doRequest(t, h, "POST", "/api/password-reset/confirm", "", weak, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("confirm with a password made from the email: status %d, want 400", rec.Code)
+	}
+
+	confirm := map[string]string{"token": token, "password": "tr0ub4dor and 3 saxophones"}
+	if rec := doRequest(t, h, "POST", "/api/password-reset/confirm", "", confirm, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("confirm: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if rec := doRequest(t, h, "POST", "/api/password-reset/confirm", "", confirm, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("confirm with a used token: status %d, want 400", rec.Code)
+	}
+
+	// Existing sessions are gone.
+	if rec := doRequest(t, h, "POST", "/api/refresh", login.RefreshToken, nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("refresh after reset: status %d, want 401", rec.Code)
+	}
+	if rec := doRequest(t, h, "GET", "/api/sessions", login.Token, nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("access token after reset: status %d, want 401", rec.Code)
+	}
+
+	if rec := doRequest(t, h, "POST", "/api/login", "", map[string]string{"email": "saul@bettercall.com", "password": "correct horse battery staple"}, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("login with the old password: status %d, want 401", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/login", "", map[string]string{"email": "saul@bettercall.com", "password": "tr0ub4dor and 3 saxophones"}, nil); rec.Code != http.StatusOK {
+		t.Errorf("login with the new password: status %d, want 200", rec.Code)
+	}
+}
+
+func TestPasswordResetMailFailure(t *testing.T) {
+	cfg := newTestConfig()
+	h := cfg.routes()
+	signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+
+	// A registered address whose mail fails looks like any other address.
+	cfg.mailer.(*testMailer).err = errors.New("smtp down")
+	if rec := doRequest(t, h, "POST", "/api/password-reset", "", map[string]string{"email": "saul@bettercall.com"}, nil); rec.Code != http.StatusAccepted {
+		t.Errorf("reset with a failing mailer: status %d, want 202", rec.Code)
+	}
+}
+
+func TestPasswordResetThrottled(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.resetPolicy.maxPerIP = 2 * cfg.resetPolicy.maxPerEmail
+	h := cfg.routes()
+	signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+	mail := cfg.mailer.(*testMailer)
+
+	reset := func(email string) int {
+		t.Helper()
+		rec := doRequest(t, h, "POST", "/api/password-reset", "", map[string]string{"email": email}, nil)
+		if rec.Code == http.StatusTooManyRequests && rec.Header().Get("Retry-After") == "" {
+			t.Errorf("429 without Retry-After")
+		}
+		return rec.Code
+	}
+
+	// Registered and unknown addresses are limited alike.
+	sent := len(mail.sent)
+	for _, email := range []string{"saul@bettercall.com", "nobody@example.com"} {
+		for i := range cfg.resetPolicy.maxPerEmail {
+			if code := reset(email); code != http.StatusAccepted {
+				t.Fatalf("reset %d for %s: status %d, want 202", i+1, email, code)
+			}
+		}
+		if code := reset(email); code != http.StatusTooManyRequests {
+			t.Errorf("reset over the limit for %s: status %d, want 429", email, code)
+		}
+	}
+	if n := len(mail.sent) - sent; n != cfg.resetPolicy.maxPerEmail {
+		t.Errorf("%d reset mails sent, want %d", n, cfg.resetPolicy.maxPerEmail)
+	}
+
+	// The IP limit covers every address.
+	if code := reset("kim@wexler.com"); code != http.StatusTooManyRequests {
+		t.Errorf("reset over the IP limit: status %d, want 429", code)
+	}
+
+	// Reset requests are not failed logins.
+	creds := map[string]string{"email": "saul@bettercall.com", "password": "correct horse battery staple"}
+	if rec := doRequest(t, h, "POST", "/api/login", "", creds, nil); rec.Code != http.StatusOK {
+		t.Errorf("login after throttled resets: status %d, want 200", rec.Code)
+	}
+}
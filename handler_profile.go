@@ -0,0 +1,66 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/Tadateki/Chirpy/internal/database"
+)
+
+// getProfileHandler serves the public profile of a handle. It never exposes the email address.
+func (cfg *apiConfig) getProfileHandler(w http.ResponseWriter, r *http.Request) {
+	handle := normalizeHandle(r.PathValue("handle"))
+	if !handleRe.MatchString(handle) {
+		respondWithError(w, http.StatusNotFound, "user not found")
+		return
+	}
+
+	user, err := cfg.dbQueries.GetUserFromHandle(r.Context(), sql.NullString{String: handle, Valid: true})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "user not found")
+		} else {
+			respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		}
+		return
+	}
+
+	// Accounts waiting for deletion are already hidden.
+	if user.DeleteAfter.Valid {
+		respondWithError(w, http.StatusNotFound, "user not found")
+		return
+	}
+
+	followers, err := cfg.dbQueries.CountFollowers(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	following, err := cfg.dbQueries.CountFollowing(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	profile := cfg.publicProfile(user)
+	profile["follower_count"] = followers
+	profile["following_count"] = following
+	respondWithJSON(w, http.StatusOK, profile)
+}
+
+// publicProfile is the part of a user anyone may see. Uploaded images are linked in every thumbnail size.
+func (cfg *apiConfig) publicProfile(user database.User) map[string]any {
+	return map[string]any{
+		"id":                user.ID.String(),
+		"handle":            user.Handle.String,
+		"display_name":      user.DisplayName,
+		"bio":               user.Bio,
+		"avatar_url":        user.AvatarUrl,
+		"avatar_thumbnails": cfg.thumbnails(avatarImage, user.AvatarKey),
+		"header_url":        user.HeaderUrl,
+		"header_thumbnails": cfg.thumbnails(headerImage, user.HeaderKey),
+		"is_chirpy_red":     user.IsChirpyRed,
+		"created_at":        user.CreatedAt.String(),
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Tadateki/Chirpy/internal/auth"
+	"github.com/Tadateki/Chirpy/internal/database"
+	"github.com/Tadateki/Chirpy/internal/imaging"
+)
+
+// maxImageUploadSize limits the uploaded file; the pixel count is limited separately by imaging.MaxPixels.
+const maxImageUploadSize = 5 << 20
+
+type imageVariant struct {
+	name          string
+	width, height int
+}
+
+// profileImage describes one kind of profile picture. The first variant is the one the *_url column points at.
+type profileImage struct {
+	field    string // multipart form field, also the blob key prefix
+	variants []imageVariant
+}
+
+var (
+	avatarImage = profileImage{
+		field: "avatar",
+		variants: []imageVariant{
+			{"400", 400, 400},
+			{"200", 200, 200},
+			{"96", 96, 96},
+			{"48", 48, 48},
+		},
+	}
+	headerImage = profileImage{
+		field: "header",
+		variants: []imageVariant{
+			{"1500x500", 1500, 500},
+			{"600x200", 600, 200},
+		},
+	}
+)
+
+// blobKey names one thumbnail; prefix is what the *_key column stores.
+func (p profileImage) blobKey(prefix string, v imageVariant) string {
+	return prefix + "_" + v.name + ".jpg"
+}
+
+// thumbnails maps variant names to public URLs, or returns nil when nothing was uploaded.
+func (cfg *apiConfig) thumbnails(p profileImage, prefix string) map[string]string {
+	if prefix == "" {
+		return nil
+	}
+	urls := map[string]string{}
+	for _, v := range p.variants {
+		urls[v.name] = cfg.blobs.URL(p.blobKey(prefix, v))
+	}
+	return urls
+}
+
+// deleteProfileImage removes the thumbnails of a replaced upload. Failures only leave orphaned files behind.
+func (cfg *apiConfig) deleteProfileImage(ctx context.Context, p profileImage, prefix string) {
+	if prefix == "" {
+		return
+	}
+	for _, v := range p.variants {
+		if err := cfg.blobs.Delete(ctx, p.blobKey(prefix, v)); err != nil {
+			log.Printf("delete %s: %v", p.blobKey(prefix, v), err)
+		}
+	}
+}
+
+func (cfg *apiConfig) uploadAvatarHandler(w http.ResponseWriter, r *http.Request) {
+	cfg.uploadProfileImage(w, r, avatarImage)
+}
+
+func (cfg *apiConfig) uploadHeaderHandler(w http.ResponseWriter, r *http.Request) {
+	cfg.uploadProfileImage(w, r, headerImage)
+}
+
+// uploadProfileImage accepts a multipart upload, checks it by its magic bytes and re-encodes it into
+// every thumbnail size. Only the re-encoded JPEGs are stored, so the metadata of the original is gone.
+func (cfg *apiConfig) uploadProfileImage(w http.ResponseWriter, r *http.Request, p profileImage) {
+
+	// Authorization
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	userid, err := cfg.validateAccessToken(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	data, status, msg := readUploadedFile(w, r, p.field)
+	if status != 0 {
+		respondWithError(w, status, msg)
+		return
+	}
+
+	img, err := imaging.Decode(data)
+	switch {
+	case errors.Is(err, imaging.ErrUnsupportedFormat):
+		respondWithError(w, http.StatusUnsupportedMediaType, "unsupported image type; use JPEG, PNG, GIF or WebP")
+		return
+	case errors.Is(err, imaging.ErrTooManyPixels):
+		respondWithError(w, http.StatusRequestEntityTooLarge, "image dimensions are too large")
+		return
+	case err != nil:
+		respondWithError(w, http.StatusBadRequest, "invalid image")
+		return
+	}
+
+	user, err := cfg.dbQueries.GetUserFromUserID(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	// A fresh name per upload, so the files can be cached forever.
+	prefix := p.field + "s/" + userid.String() + "/" + strings.ToLower(rand.Text())
+	for _, v := range p.variants {
+		var buf bytes.Buffer
+		if err := imaging.EncodeJPEG(&buf, img.Thumbnail(v.width, v.height)); err != nil {
+			cfg.deleteProfileImage(r.Context(), p, prefix)
+			respondWithError(w, http.StatusInternalServerError, "Fail to Encode image")
+			return
+		}
+		if err := cfg.blobs.Put(r.Context(), p.blobKey(prefix, v), &buf, "image/jpeg"); err != nil {
+			log.Printf("store %s: %v", p.blobKey(prefix, v), err)
+			cfg.deleteProfileImage(r.Context(), p, prefix)
+			respondWithError(w, http.StatusInternalServerError, "Fail to Store image")
+			return
+		}
+	}
+
+	url := cfg.blobs.URL(p.blobKey(prefix, p.variants[0]))
+	oldPrefix := user.AvatarKey
+	if p.field == headerImage.field {
+		oldPrefix = user.HeaderKey
+		err = cfg.dbQueries.SetUserHeader(r.Context(), database.SetUserHeaderParams{HeaderUrl: url, HeaderKey: prefix, ID: userid})
+	} else {
+		err = cfg.dbQueries.SetUserAvatar(r.Context(), database.SetUserAvatarParams{AvatarUrl: url, AvatarKey: prefix, ID: userid})
+	}
+	if err != nil {
+		cfg.deleteProfileImage(r.Context(), p, prefix)
+		respondWithError(w, http.StatusInternalServerError, "Fail to Update user")
+		return
+	}
+	cfg.deleteProfileImage(r.Context(), p, oldPrefix)
+
+	user, err = cfg.dbQueries.GetUserFromUserID(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Fail to Load updated information")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, cfg.publicProfile(user))
+}
+
+// readUploadedFile returns the contents of the named file field, or an HTTP status and message.
+func readUploadedFile(w http.ResponseWriter, r *http.Request, field string) ([]byte, int, string) {
+	// Headers and other fields get a little room on top of the file itself.
+	r.Body = http.MaxBytesReader(w, r.Body, maxImageUploadSize+64<<10)
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, http.StatusBadRequest, "expected a multipart/form-data upload"
+	}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, http.StatusBadRequest, "missing " + field + " file"
+		}
+		if err != nil {
+			return nil, uploadErrorStatus(err), "invalid multipart upload"
+		}
+		if part.FormName() != field {
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(part, maxImageUploadSize+1))
+		if err != nil {
+			return nil, uploadErrorStatus(err), "invalid multipart upload"
+		}
+		if len(data) > maxImageUploadSize {
+			return nil, http.StatusRequestEntityTooLarge, "image is larger than 5 MiB"
+		}
+		return data, 0, ""
+	}
+}
+
+func uploadErrorStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Tadateki/Chirpy/internal/blobstore"
+)
+
+// uploadFile sends data as the named multipart file field.
+func uploadFile(t *testing.T, h http.Handler, path, bearer, field string, data []byte, out any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile(field, "upload.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write(data)
+	mw.Close()
+
+	req := httptest.NewRequest("PUT", path, &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if out != nil && rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("decode response %q: %v", rec.Body.String(), err)
+		}
+	}
+	return rec
+}
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+type profileResponse struct {
+	AvatarURL        string            `json:"avatar_url"`
+	AvatarThumbnails map[string]string `json:"avatar_thumbnails"`
+	HeaderURL        string            `json:"header_url"`
+	HeaderThumbnails map[string]string `json:"header_thumbnails"`
+}
+
+func TestAvatarUpload(t *testing.T) {
+	cfg := newTestConfig()
+	h := cfg.routes()
+	blobs := cfg.blobs.(*blobstore.MemoryStore)
+	login := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+
+	if rec := uploadFile(t, h, "/api/users/avatar", "", "avatar", testPNG(t, 30, 20), nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("upload without a token: status %d, want 401", rec.Code)
+	}
+	if rec := uploadFile(t, h, "/api/users/avatar", login.Token, "avatar", []byte("<svg onload=alert(1)></svg>"), nil); rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("upload of an SVG: status %d, want 415", rec.Code)
+	}
+	if rec := uploadFile(t, h, "/api/users/avatar", login.Token, "photo", testPNG(t, 30, 20), nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("upload in the wrong field: status %d, want 400", rec.Code)
+	}
+	if rec := doRequest(t, h, "PUT", "/api/users/avatar", login.Token, map[string]string{"avatar": "x"}, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("JSON instead of multipart: status %d, want 400", rec.Code)
+	}
+	big := append(testPNG(t, 30, 20), bytes.Repeat([]byte{0}, maxImageUploadSize)...)
+	if rec := uploadFile(t, h, "/api/users/avatar", login.Token, "avatar", big, nil); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("upload over the size limit: status %d, want 413", rec.Code)
+	}
+
+	var first profileResponse
+	if rec := uploadFile(t, h, "/api/users/avatar", login.Token, "avatar", testPNG(t, 30, 20), &first); rec.Code != http.StatusOK {
+		t.Fatalf("upload avatar: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if len(first.AvatarThumbnails) != len(avatarImage.variants) || first.AvatarURL != first.AvatarThumbnails["400"] {
+		t.Fatalf("profile after upload = %+v", first)
+	}
+	for name, url := range first.AvatarThumbnails {
+		key := strings.TrimPrefix(url, "http://chirpy.test/app/uploads/")
+		data, ok := blobs.Get(key)
+		if !ok {
+			t.Fatalf("thumbnail %s not stored at %s", name, key)
+		}
+		dims, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("thumbnail %s is not a JPEG: %v", name, err)
+		}
+		if want, _ := strconv.Atoi(name); dims.Width != want || dims.Height != want {
+			t.Errorf("thumbnail %s is %dx%d", name, dims.Width, dims.Height)
+		}
+	}
+
+	// The profile links the upload.
+	if rec := doRequest(t, h, "PATCH", "/api/users", login.Token, map[string]string{"handle": "saulgoodman"}, nil); rec.Code != http.StatusOK {
+		t.Fatalf("set handle: status %d", rec.Code)
+	}
+	var profile profileResponse
+	if rec := doRequest(t, h, "GET", "/api/users/saulgoodman", "", nil, &profile); rec.Code != http.StatusOK || profile.AvatarURL != first.AvatarURL {
+		t.Errorf("public profile avatar = %q, want %q", profile.AvatarURL, first.AvatarURL)
+	}
+
+	// A new upload replaces the old files.
+	var second profileResponse
+	if rec := uploadFile(t, h, "/api/users/avatar", login.Token, "avatar", testPNG(t, 20, 30), &second); rec.Code != http.StatusOK {
+		t.Fatalf("second upload: status %d", rec.Code)
+	}
+	if second.AvatarURL == first.AvatarURL {
+		t.Errorf("second upload reused the URL %s", first.AvatarURL)
+	}
+	if _, ok := blobs.Get(strings.TrimPrefix(first.AvatarURL, "http://chirpy.test/app/uploads/")); ok {
+		t.Errorf("old avatar still stored after a new upload")
+	}
+
+	var header profileResponse
+	if rec := uploadFile(t, h, "/api/users/header", login.Token, "header", testPNG(t, 300, 100), &header); rec.Code != http.StatusOK {
+		t.Fatalf("upload header: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	data, _ := blobs.Get(strings.TrimPrefix(header.HeaderURL, "http://chirpy.test/app/uploads/"))
+	if dims, err := jpeg.DecodeConfig(bytes.NewReader(data)); err != nil || dims.Width != 1500 || dims.Height != 500 {
+		t.Errorf("header image = %+v, %v; want 1500x500", dims, err)
+	}
+	if header.AvatarURL != second.AvatarURL {
+		t.Errorf("header upload changed the avatar")
+	}
+}
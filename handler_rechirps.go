@@ -0,0 +1,96 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/Tadateki/Chirpy/internal/auth"
+	"github.com/Tadateki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// rechirpHandler reposts the chirp in the path, or the original of a rechirp, as the caller.
+// The rechirp is a chirp of the caller's, so it reaches their followers' timelines.
+// Rechirping twice returns the existing rechirp.
+func (cfg *apiConfig) rechirpHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Authorization
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	userid, err := cfg.validateAccessToken(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	original, ok := cfg.pathChirp(w, r)
+	if !ok {
+		return
+	}
+
+	status := http.StatusCreated
+	rechirp, err := cfg.dbQueries.CreateRechirp(r.Context(), database.CreateRechirpParams{
+		UserID:      userid,
+		RechirpOfID: original.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		status = http.StatusOK
+		rechirp, err = cfg.dbQueries.GetRechirp(r.Context(), database.GetRechirpParams{
+			UserID:      userid,
+			RechirpOfID: original.ID,
+		})
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	if status == http.StatusCreated {
+		cfg.timeline.enqueue(rechirp)
+	}
+
+	details, err := cfg.loadChirpDetails(r.Context(), uuid.NullUUID{UUID: userid, Valid: true}, []database.Chirp{rechirp})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	respondWithJSON(w, status, details.json(rechirp))
+}
+
+// unrechirpHandler undoes rechirpHandler for the chirp in the path, or the original of a rechirp.
+// Undoing a rechirp that does not exist is not an error.
+func (cfg *apiConfig) unrechirpHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Authorization
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	userid, err := cfg.validateAccessToken(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	chirpID, ok := cfg.pathOriginalID(w, r)
+	if !ok {
+		return
+	}
+
+	_, err = cfg.dbQueries.DeleteRechirp(r.Context(), database.DeleteRechirpParams{
+		UserID:      userid,
+		RechirpOfID: chirpID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+type repostedChirp struct {
+	ID           string `json:"id"`
+	Body         string `json:"body"`
+	UserID       string `json:"user_id"`
+	RechirpOfID  string `json:"rechirp_of_id"`
+	QuotedID     string `json:"quoted_id"`
+	RechirpCount int64  `json:"rechirp_count"`
+	QuoteCount   int64  `json:"quote_count"`
+	Deleted      bool   `json:"deleted"`
+}
+
+type chirpWithRepost struct {
+	repostedChirp
+	RechirpOf *repostedChirp `json:"rechirp_of"`
+	Quoted    *repostedChirp `json:"quoted"`
+}
+
+func TestRechirpsAndQuotes(t *testing.T) {
+	cfg := newTestConfig()
+	h := cfg.routes()
+	walt := signup(t, h, "walt@graymatter.com", "correct horse battery staple")
+	jesse := signup(t, h, "jesse@capncook.com", "correct horse battery staple")
+	skyler := signup(t, h, "skyler@carwash.com", "correct horse battery staple")
+
+	// Skyler follows Jesse only.
+	if rec := doRequest(t, h, "POST", "/api/users/"+jesse.ID+"/follow", skyler.Token, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("follow: status %d", rec.Code)
+	}
+
+	var original chirpWithRepost
+	if rec := doRequest(t, h, "POST", "/api/chirps", walt.Token, map[string]string{"body": "I am the one who knocks"}, &original); rec.Code != http.StatusCreated {
+		t.Fatalf("create chirp: status %d", rec.Code)
+	}
+
+	var rechirp chirpWithRepost
+	if rec := doRequest(t, h, "POST", "/api/chirps/"+original.ID+"/rechirp", jesse.Token, nil, &rechirp); rec.Code != http.StatusCreated {
+		t.Fatalf("rechirp: status %d", rec.Code)
+	}
+	if rechirp.UserID != jesse.ID || rechirp.RechirpOfID != original.ID || rechirp.RechirpOf == nil || rechirp.RechirpOf.Body != original.Body {
+		t.Fatalf("rechirp = %+v", rechirp)
+	}
+	var again chirpWithRepost
+	if rec := doRequest(t, h, "POST", "/api/chirps/"+rechirp.ID+"/rechirp", jesse.Token, nil, &again); rec.Code != http.StatusOK || again.ID != rechirp.ID {
+		t.Errorf("rechirp the rechirp: status %d, id %s, want 200 and %s", rec.Code, again.ID, rechirp.ID)
+	}
+	cfg.timeline.wait()
+
+	// The rechirp reaches Jesse's followers with the original embedded.
+	var timeline struct {
+		Chirps []chirpWithRepost `json:"chirps"`
+	}
+	if rec := doRequest(t, h, "GET", "/api/timeline", skyler.Token, nil, &timeline); rec.Code != http.StatusOK {
+		t.Fatalf("timeline: status %d", rec.Code)
+	}
+	if len(timeline.Chirps) != 1 || timeline.Chirps[0].ID != rechirp.ID || timeline.Chirps[0].RechirpOf == nil ||
+		timeline.Chirps[0].RechirpOf.ID != original.ID || timeline.Chirps[0].RechirpOf.RechirpCount != 1 {
+		t.Fatalf("timeline = %+v", timeline.Chirps)
+	}
+
+	var quote chirpWithRepost
+	req := map[string]string{"body": "Say my name", "quoted_id": rechirp.ID}
+	if rec := doRequest(t, h, "POST", "/api/chirps", skyler.Token, req, &quote); rec.Code != http.StatusCreated {
+		t.Fatalf("quote: status %d", rec.Code)
+	}
+	if quote.QuotedID != original.ID || quote.Quoted == nil || quote.Quoted.Body != original.Body || quote.Quoted.QuoteCount != 1 {
+		t.Fatalf("quote = %+v", quote)
+	}
+	if rec := doRequest(t, h, "POST", "/api/chirps", skyler.Token, map[string]string{"body": "hi", "quoted_id": "nope"}, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("quote an invalid id: status %d, want 400", rec.Code)
+	}
+
+	// A liked quote keeps its quoted chirp in the likes list.
+	if rec := doRequest(t, h, "POST", "/api/chirps/"+quote.ID+"/like", walt.Token, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("like quote: status %d", rec.Code)
+	}
+	var likes struct {
+		Chirps []chirpWithRepost `json:"chirps"`
+	}
+	if rec := doRequest(t, h, "GET", "/api/users/"+walt.ID+"/likes", "", nil, &likes); rec.Code != http.StatusOK {
+		t.Fatalf("list likes: status %d", rec.Code)
+	}
+	if len(likes.Chirps) != 1 || likes.Chirps[0].QuotedID != original.ID || likes.Chirps[0].Quoted == nil {
+		t.Errorf("liked quote = %+v", likes.Chirps)
+	}
+
+	get := func(id string) chirpWithRepost {
+		t.Helper()
+		var c chirpWithRepost
+		if rec := doRequest(t, h, "GET", "/api/chirps/"+id, "", nil, &c); rec.Code != http.StatusOK {
+			t.Fatalf("get chirp: status %d", rec.Code)
+		}
+		return c
+	}
+	if c := get(original.ID); c.RechirpCount != 1 || c.QuoteCount != 1 {
+		t.Errorf("original counts = %d rechirps, %d quotes; want 1, 1", c.RechirpCount, c.QuoteCount)
+	}
+
+	// Undoing the rechirp takes it out of timelines and the count. Like the rechirp above, it can
+	// go through the rechirp's own ID.
+	for _, id := range []string{rechirp.ID, rechirp.ID, original.ID} {
+		if rec := doRequest(t, h, "DELETE", "/api/chirps/"+id+"/rechirp", jesse.Token, nil, nil); rec.Code != http.StatusNoContent {
+			t.Fatalf("undo rechirp: status %d", rec.Code)
+		}
+	}
+	if rec := doRequest(t, h, "GET", "/api/chirps/"+rechirp.ID, "", nil, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("GET undone rechirp: status %d, want 404", rec.Code)
+	}
+	timeline.Chirps = nil
+	if rec := doRequest(t, h, "GET", "/api/timeline", skyler.Token, nil, &timeline); rec.Code != http.StatusOK || len(timeline.Chirps) != 0 {
+		t.Errorf("timeline after undoing: status %d, %+v", rec.Code, timeline.Chirps)
+	}
+	if c := get(original.ID); c.RechirpCount != 0 {
+		t.Errorf("rechirp_count after undoing = %d, want 0", c.RechirpCount)
+	}
+
+	// A deleted original stays in the quote as a placeholder.
+	if rec := doRequest(t, h, "POST", "/api/chirps", jesse.Token, map[string]string{"body": "Yeah", "in_reply_to": original.ID}, nil); rec.Code != http.StatusCreated {
+		t.Fatalf("reply: status %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "DELETE", "/api/chirps/"+original.ID, walt.Token, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete original: status %d", rec.Code)
+	}
+	if c := get(quote.ID); c.Quoted == nil || !c.Quoted.Deleted || c.Quoted.Body != "" {
+		t.Errorf("quote of a deleted chirp = %+v", c.Quoted)
+	}
+	if rec := doRequest(t, h, "POST", "/api/chirps/"+original.ID+"/rechirp", jesse.Token, nil, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("rechirp a deleted chirp: status %d, want 404", rec.Code)
+	}
+
+	// So does one deleted outright, which happens to a chirp without replies.
+	var plain chirpWithRepost
+	if rec := doRequest(t, h, "POST", "/api/chirps", walt.Token, map[string]string{"body": "Tread lightly"}, &plain); rec.Code != http.StatusCreated {
+		t.Fatalf("create chirp: status %d", rec.Code)
+	}
+	var quote2 chirpWithRepost
+	if rec := doRequest(t, h, "POST", "/api/chirps", skyler.Token, map[string]string{"body": "Noted", "quoted_id": plain.ID}, &quote2); rec.Code != http.StatusCreated {
+		t.Fatalf("quote: status %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "DELETE", "/api/chirps/"+plain.ID, walt.Token, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete quoted chirp: status %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "GET", "/api/chirps/"+plain.ID, "", nil, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("GET hard-deleted chirp: status %d, want 404", rec.Code)
+	}
+	if c := get(quote2.ID); c.QuotedID != plain.ID || c.Quoted == nil || !c.Quoted.Deleted || c.Quoted.ID != plain.ID {
+		t.Errorf("quote of a hard-deleted chirp = quoted_id %q, quoted %+v", c.QuotedID, c.Quoted)
+	}
+}
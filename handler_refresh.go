@@ -1,11 +1,14 @@
 package main
 
 import (
+	"database/sql"
 	"fmt"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/Tadateki/Chirpy/internal/auth"
+	"github.com/Tadateki/Chirpy/internal/database"
 )
 
 func (cfg *apiConfig) refreshHandler(w http.ResponseWriter, r *http.Request) {
@@ -19,8 +22,21 @@ func (cfg *apiConfig) refreshHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// DB Lookup for Refresh Token
-	refresh_token, err := cfg.dbQueries.GetRefreshTokenFromToken(r.Context(), r_token)
-	if err != nil || time.Now().After(refresh_token.ExpiresAt) || refresh_token.RevokedAt.Valid {
+	refresh_token, err := cfg.dbQueries.GetRefreshTokenFromToken(r.Context(), auth.HashRefreshToken(r_token))
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid refresh token")
+		return
+	}
+
+	// Reuse Detection: a token that was already rotated is being presented again,
+	// so it may have been stolen. Revoke every token of its family.
+	if refresh_token.RevokedAt.Valid && refresh_token.ReplacedBy.Valid {
+		cfg.revokeRefreshTokenFamily(r, refresh_token)
+		respondWithError(w, http.StatusUnauthorized, "Invalid refresh token")
+		return
+	}
+
+	if time.Now().After(refresh_token.ExpiresAt) || refresh_token.RevokedAt.Valid {
 		respondWithError(w, http.StatusUnauthorized, "Invalid refresh token")
 		return
 	}
@@ -32,13 +48,57 @@ func (cfg *apiConfig) refreshHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// JWT Token Generation
-	token, err := auth.MakeJWT(user.ID, cfg.tokenSecret, time.Duration(cfg.expires_in_seconds)*time.Second)
+	token, err := cfg.makeAccessToken(user)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Access Token Generation Error")
 		return
 	}
 
+	// Refresh Token Rotation
+	new_token_str, err := auth.MakeRefreshToken()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Refresh Token Generation Error")
+		return
+	}
+
+	new_token, err := cfg.dbQueries.StoreRefreshToken(r.Context(), database.StoreRefreshTokenParams{
+		TokenHash:        auth.HashRefreshToken(new_token_str),
+		UserID:           user.ID,
+		ExpiresAt:        time.Now().UTC().Add(time.Duration(cfg.refresh_expires_in_hours) * time.Hour),
+		FamilyID:         refresh_token.FamilyID,
+		UserAgent:        refresh_token.UserAgent,
+		IpAddress:        refresh_token.IpAddress,
+		SessionStartedAt: sql.NullTime{Time: refresh_token.SessionStartedAt, Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Refresh Token Storing Error")
+		return
+	}
+
+	rotated, err := cfg.dbQueries.RotateRefreshToken(r.Context(), database.RotateRefreshTokenParams{
+		TokenHash:  refresh_token.TokenHash,
+		ReplacedBy: sql.NullString{String: new_token.TokenHash, Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Refresh Token Rotation Error")
+		return
+	}
+	if rotated == 0 {
+		// Another request rotated or revoked the same token concurrently.
+		cfg.revokeRefreshTokenFamily(r, refresh_token)
+		respondWithError(w, http.StatusUnauthorized, "Invalid refresh token")
+		return
+	}
+
 	respondWithJSON(w, http.StatusOK, map[string]string{
-		"token": token,
+		"token":         token,
+		"refresh_token": new_token_str,
 	})
 }
+
+func (cfg *apiConfig) revokeRefreshTokenFamily(r *http.Request, refresh_token database.RefreshToken) {
+	log.Printf("refresh token reuse detected for user %s; revoking family %s", refresh_token.UserID, refresh_token.FamilyID)
+	if err := cfg.dbQueries.RevokeRefreshTokenFamily(r.Context(), refresh_token.FamilyID); err != nil {
+		log.Printf("RevokeRefreshTokenFamily error: %v", err)
+	}
+}
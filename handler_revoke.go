@@ -19,13 +19,13 @@ func (cfg *apiConfig) revokeHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// DB Lookup for Refresh Token
-	refresh_token, err := cfg.dbQueries.GetRefreshTokenFromToken(r.Context(), r_token)
+	refresh_token, err := cfg.dbQueries.GetRefreshTokenFromToken(r.Context(), auth.HashRefreshToken(r_token))
 	if err != nil || time.Now().After(refresh_token.ExpiresAt) || refresh_token.RevokedAt.Valid {
 		respondWithError(w, http.StatusUnauthorized, "Invalid refresh token")
 		return
 	}
 
-	err = cfg.dbQueries.SetRevokeRefreshToken(r.Context(), refresh_token.Token)
+	err = cfg.dbQueries.SetRevokeRefreshToken(r.Context(), refresh_token.TokenHash)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "Fail to Revoke refresh token")
 		return
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/Tadateki/Chirpy/internal/auth"
+	"github.com/Tadateki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// A session is a refresh token family: it starts at login and survives rotation.
+// Its ID is the family ID.
+
+func (cfg *apiConfig) getSessionsHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Authorization
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	userid, err := cfg.validateAccessToken(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	tokens, err := cfg.dbQueries.ListActiveRefreshTokensByUser(r.Context(), database.ListActiveRefreshTokensByUserParams{
+		UserID: userid,
+		Now:    time.Now().UTC(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	// The active token of a family was issued at the last login or refresh.
+	response := []map[string]string{}
+	for _, rt := range tokens {
+		response = append(response, map[string]string{
+			"id":           rt.FamilyID.String(),
+			"created_at":   rt.SessionStartedAt.String(),
+			"expires_at":   rt.ExpiresAt.String(),
+			"last_used_at": rt.CreatedAt.String(),
+			"user_agent":   rt.UserAgent,
+			"ip_address":   rt.IpAddress,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+func (cfg *apiConfig) deleteSessionHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Authorization
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	userid, err := cfg.validateAccessToken(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	sessionID, err := uuid.Parse(r.PathValue("sessionID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid session ID")
+		return
+	}
+
+	revoked, err := cfg.dbQueries.RevokeUserRefreshTokenFamily(r.Context(), database.RevokeUserRefreshTokenFamilyParams{
+		FamilyID: sessionID,
+		UserID:   userid,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	if revoked == 0 {
+		respondWithError(w, http.StatusNotFound, "session not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) logoutAllHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Authorization
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	userid, err := cfg.validateAccessToken(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	err = cfg.dbQueries.RevokeAllRefreshTokensForUser(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Fail to Revoke refresh tokens")
+		return
+	}
+
+	err = cfg.revokeAccessTokens(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Fail to Revoke access tokens")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// clientIP returns the address of the peer that sent the request.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
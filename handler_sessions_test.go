@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSessions(t *testing.T) {
+	h := newTestConfig().routes()
+	first := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+
+	var second loginResponse
+	creds := map[string]string{"email": "saul@bettercall.com", "password": "correct horse battery staple"}
+	if rec := doRequest(t, h, "POST", "/api/login", "", creds, &second); rec.Code != http.StatusOK {
+		t.Fatalf("second login: status %d", rec.Code)
+	}
+
+	// Rotation keeps the session.
+	var refreshed struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if rec := doRequest(t, h, "POST", "/api/refresh", first.RefreshToken, nil, &refreshed); rec.Code != http.StatusOK {
+		t.Fatalf("refresh: status %d", rec.Code)
+	}
+
+	var sessions []map[string]string
+	if rec := doRequest(t, h, "GET", "/api/sessions", second.Token, nil, &sessions); rec.Code != http.StatusOK {
+		t.Fatalf("list sessions: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("list sessions returned %d sessions, want 2", len(sessions))
+	}
+	for _, s := range sessions {
+		if s["ip_address"] == "" {
+			t.Errorf("session %s has no ip_address", s["id"])
+		}
+	}
+
+	// Sessions are listed newest first, so the first login is last.
+	firstSession := sessions[1]["id"]
+
+	other := signup(t, h, "kim@wexlermcgill.com", "correct horse battery staple")
+	if rec := doRequest(t, h, "DELETE", "/api/sessions/"+firstSession, other.Token, nil, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("delete another user's session: status %d, want 404", rec.Code)
+	}
+
+	if rec := doRequest(t, h, "DELETE", "/api/sessions/"+firstSession, second.Token, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete session: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if rec := doRequest(t, h, "POST", "/api/refresh", refreshed.RefreshToken, nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("refresh with deleted session: status %d, want 401", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/refresh", second.RefreshToken, nil, nil); rec.Code != http.StatusOK {
+		t.Errorf("refresh with remaining session: status %d, want 200", rec.Code)
+	}
+
+	if rec := doRequest(t, h, "POST", "/api/logout-all", second.Token, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("logout-all: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	// logout-all also revokes access tokens.
+	if rec := doRequest(t, h, "GET", "/api/sessions", second.Token, nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("list sessions with an access token issued before logout-all: status %d, want 401", rec.Code)
+	}
+
+	var third loginResponse
+	doRequest(t, h, "POST", "/api/login", "", creds, &third)
+	sessions = nil
+	doRequest(t, h, "GET", "/api/sessions", third.Token, nil, &sessions)
+	if len(sessions) != 1 {
+		t.Errorf("list sessions after logout-all and a new login returned %d sessions, want 1", len(sessions))
+	}
+
+	// Other users keep their sessions.
+	if rec := doRequest(t, h, "POST", "/api/refresh", other.RefreshToken, nil, nil); rec.Code != http.StatusOK {
+		t.Errorf("refresh for another user after logout-all: status %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Tadateki/Chirpy/internal/auth"
+	"github.com/Tadateki/Chirpy/internal/blobstore"
+	"github.com/Tadateki/Chirpy/internal/database"
+	"github.com/Tadateki/Chirpy/internal/mailer"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestConfig() *apiConfig {
+	jwtKeys, err := auth.GenerateEd25519Keyring()
+	if err != nil {
+		panic(err)
+	}
+	store := database.NewMemoryStore()
+	return &apiConfig{
+		dbQueries:                store,
+		platform:                 "dev",
+		tokenSecret:              "testsecret",
+		jwtKeys:                  jwtKeys,
+		expires_in_seconds:       3600,
+		refresh_expires_in_hours: 1,
+		polka_key:                "testpolkakey",
+		totpKey:                  bytes.Repeat([]byte{1}, 32),
+		mailer:                   &testMailer{},
+		publicURL:                "http://chirpy.test",
+		loginPolicy:              defaultLoginPolicy(),
+		resetPolicy:              defaultResetPolicy(),
+		passwordParams:           auth.DefaultPasswordParams,
+		passwordPolicy:           auth.DefaultPasswordPolicy,
+		blobs:                    blobstore.NewMemoryStore("http://chirpy.test/app/uploads"),
+		accountDeletionGrace:     defaultAccountDeletionGrace,
+		timeline:                 newTimelineFanout(store, defaultFanoutMaxFollowers, 1),
+	}
+}
+
+// testMailer records sent messages instead of delivering them. When err is set, Send fails with it.
+type testMailer struct {
+	mu   sync.Mutex
+	sent []mailer.Message
+	err  error
+}
+
+func (m *testMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// last returns the most recent message sent to the address.
+func (m *testMailer) last(t *testing.T, to string) mailer.Message {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.sent) - 1; i >= 0; i-- {
+		if m.sent[i].To == to {
+			return m.sent[i]
+		}
+	}
+	t.Fatalf("no mail sent to %s", to)
+	return mailer.Message{}
+}
+
+// doRequest sends a JSON request through the full router and decodes the JSON response into out (if non-nil).
+func doRequest(t *testing.T, h http.Handler, method, path, bearer string, body any, out any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("encode request body: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if out != nil && rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: decode response %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec
+}
+
+type loginResponse struct {
+	ID           string `json:"id"`
+	Email        string `json:"email"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// signup creates a user and logs them in.
+func signup(t *testing.T, h http.Handler, email, password string) loginResponse {
+	t.Helper()
+
+	creds := map[string]string{"email": email, "password": password}
+	if rec := doRequest(t, h, "POST", "/api/users", "", creds, nil); rec.Code != http.StatusCreated {
+		t.Fatalf("create user: status %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	var login loginResponse
+	if rec := doRequest(t, h, "POST", "/api/login", "", creds, &login); rec.Code != http.StatusOK {
+		t.Fatalf("login: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	return login
+}
+
+func TestSignupRejectsWeakPassword(t *testing.T) {
+	h := newTestConfig().routes()
+
+	for _, pw := range []string{"123456", "saulgoodman"} {
+		creds := map[string]string{"email": "saul@bettercall.com", "password": pw}
+		if rec := doRequest(t, h, "POST", "/api/users", "", creds, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("create user with password %q: status %d, want 400", pw, rec.Code)
+		}
+		if rec := doRequest(t, h, "POST", "/api/login", "", creds, nil); rec.Code != http.StatusUnauthorized {
+			t.Errorf("login after a rejected signup: status %d, want 401", rec.Code)
+		}
+	}
+}
+
+func TestLoginRefreshRevoke(t *testing.T) {
+	h := newTestConfig().routes()
+	login := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+
+	if rec := doRequest(t, h, "POST", "/api/login", "", map[string]string{"email": "saul@bettercall.com", "password": "wrong"}, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("login with wrong password: status %d, want 401", rec.Code)
+	}
+
+	var refreshed struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if rec := doRequest(t, h, "POST", "/api/refresh", login.RefreshToken, nil, &refreshed); rec.Code != http.StatusOK {
+		t.Fatalf("refresh: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if refreshed.Token == "" || refreshed.RefreshToken == "" {
+		t.Fatalf("refresh returned empty tokens: %+v", refreshed)
+	}
+	if refreshed.RefreshToken == login.RefreshToken {
+		t.Fatalf("refresh did not rotate the refresh token")
+	}
+
+	if rec := doRequest(t, h, "POST", "/api/revoke", refreshed.RefreshToken, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("revoke: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if rec := doRequest(t, h, "POST", "/api/refresh", refreshed.RefreshToken, nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("refresh after revoke: status %d, want 401", rec.Code)
+	}
+}
+
+func TestRefreshTokenStoredHashed(t *testing.T) {
+	cfg := newTestConfig()
+	login := signup(t, cfg.routes(), "saul@bettercall.com", "correct horse battery staple")
+
+	ctx := context.Background()
+	if _, err := cfg.dbQueries.GetRefreshTokenFromToken(ctx, login.RefreshToken); err == nil {
+		t.Errorf("raw refresh token found in the store")
+	}
+	if _, err := cfg.dbQueries.GetRefreshTokenFromToken(ctx, auth.HashRefreshToken(login.RefreshToken)); err != nil {
+		t.Errorf("refresh token digest not found in the store: %v", err)
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	h := newTestConfig().routes()
+	login := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+	other := signup(t, h, "kim@wexlermcgill.com", "correct horse battery staple")
+
+	var first struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if rec := doRequest(t, h, "POST", "/api/refresh", login.RefreshToken, nil, &first); rec.Code != http.StatusOK {
+		t.Fatalf("refresh: status %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	// Replaying the rotated token is treated as theft.
+	if rec := doRequest(t, h, "POST", "/api/refresh", login.RefreshToken, nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh with rotated token: status %d, want 401", rec.Code)
+	}
+
+	// ...which also kills the token the legitimate client holds.
+	if rec := doRequest(t, h, "POST", "/api/refresh", first.RefreshToken, nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("refresh with latest token of a compromised family: status %d, want 401", rec.Code)
+	}
+
+	// Other families are unaffected.
+	if rec := doRequest(t, h, "POST", "/api/refresh", other.RefreshToken, nil, nil); rec.Code != http.StatusOK {
+		t.Errorf("refresh for another user: status %d, want 200", rec.Code)
+	}
+}
+
+func TestPasswordChangeRevokesAccessTokens(t *testing.T) {
+	h := newTestConfig().routes()
+	login := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+
+	update := map[string]string{"email": "saul@bettercall.com", "password": "tr0ub4dor and 3 saxophones"}
+	if rec := doRequest(t, h, "PUT", "/api/users", login.Token, update, nil); rec.Code != http.StatusOK {
+		t.Fatalf("update user: status %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	chirp := map[string]string{"body": "I know a guy"}
+	if rec := doRequest(t, h, "POST", "/api/chirps", login.Token, chirp, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("create chirp with an access token issued before the password change: status %d, want 401", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/refresh", login.RefreshToken, nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("refresh with a refresh token issued before the password change: status %d, want 401", rec.Code)
+	}
+
+	var relogin loginResponse
+	if rec := doRequest(t, h, "POST", "/api/login", "", update, &relogin); rec.Code != http.StatusOK {
+		t.Fatalf("login with the new password: status %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/chirps", relogin.Token, chirp, nil); rec.Code != http.StatusCreated {
+		t.Errorf("create chirp with a fresh access token: status %d, want 201", rec.Code)
+	}
+}
+
+func TestChirpsPagination(t *testing.T) {
+	h := newTestConfig().routes()
+	login := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+
+	for i := 0; i < 5; i++ {
+		if rec := doRequest(t, h, "POST", "/api/chirps", login.Token, map[string]string{"body": "I know a guy"}, nil); rec.Code != http.StatusCreated {
+			t.Fatalf("create chirp: status %d, body %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	type page struct {
+		Chirps []struct {
+			ID string `json:"id"`
+		} `json:"chirps"`
+		NextCursor string `json:"next_cursor"`
+	}
+
+	seen := map[string]bool{}
+	path := "/api/chirps?sort=desc&limit=2"
+	for pages := 0; path != ""; pages++ {
+		if pages > 3 {
+			t.Fatalf("pagination did not terminate")
+		}
+
+		var p page
+		rec := doRequest(t, h, "GET", path, "", nil, &p)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("list chirps: status %d, body %s", rec.Code, rec.Body.String())
+		}
+		for _, c := range p.Chirps {
+			if seen[c.ID] {
+				t.Fatalf("chirp %s returned twice", c.ID)
+			}
+			seen[c.ID] = true
+		}
+
+		path = ""
+		if p.NextCursor != "" {
+			if rec.Header().Get("Link") == "" {
+				t.Errorf("missing Link header with next_cursor %q", p.NextCursor)
+			}
+			path = "/api/chirps?sort=desc&limit=2&cursor=" + p.NextCursor
+		}
+	}
+
+	if len(seen) != 5 {
+		t.Errorf("paginated listing returned %d chirps, want 5", len(seen))
+	}
+
+	if rec := doRequest(t, h, "GET", "/api/chirps?cursor=garbage", "", nil, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid cursor: status %d, want 400", rec.Code)
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	cfg := newTestConfig()
+	h := cfg.routes()
+	login := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+
+	var set auth.JWKSet
+	if rec := doRequest(t, h, "GET", "/.well-known/jwks.json", "", nil, &set); rec.Code != http.StatusOK {
+		t.Fatalf("jwks: status %d", rec.Code)
+	}
+	if len(set.Keys) != 1 || set.Keys[0].Kty != "OKP" || set.Keys[0].X == "" {
+		t.Fatalf("jwks returned %+v, want one Ed25519 key", set)
+	}
+
+	token, _, err := jwt.NewParser().ParseUnverified(login.Token, &jwt.RegisteredClaims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified returned error: %v", err)
+	}
+	if token.Header["kid"] != set.Keys[0].Kid {
+		t.Errorf("access token kid %v not published in jwks", token.Header["kid"])
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/Tadateki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxThreadAncestors bounds the walk up from a chirp to the start of its conversation.
+	maxThreadAncestors = 100
+
+	// threadDepth is how many levels of replies one page of a thread shows. Deeper
+	// replies are fetched with the thread of the chirp they reply to.
+	threadDepth = 4
+
+	// maxThreadDescendants bounds the replies shown below one page of direct replies.
+	// The page itself is always shown in full, so next_cursor never skips a direct reply.
+	maxThreadDescendants = 500
+)
+
+// getThreadHandler returns the chirps a chirp replies to, root first, and a page of its replies,
+// oldest first, each with the replies to it nested below. Every chirp has a reply_count, so
+// replies cut off by threadDepth or maxThreadDescendants are visible. Deleted chirps that
+// still have replies show up as placeholders.
+func (cfg *apiConfig) getThreadHandler(w http.ResponseWriter, r *http.Request) {
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid chirp ID")
+		return
+	}
+
+	p, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+
+	viewer, ok := cfg.optionalViewer(w, r)
+	if !ok {
+		return
+	}
+
+	chirp, err := cfg.dbQueries.GetChirp(r.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "chirp not found")
+		} else {
+			respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		}
+		return
+	}
+
+	ancestors, err := cfg.dbQueries.ListChirpAncestors(r.Context(), database.ListChirpAncestorsParams{
+		ID:       chirp.ID,
+		MaxDepth: maxThreadAncestors,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	// One page of direct replies (one extra row tells us whether a next page exists)
+	replies, err := cfg.dbQueries.ListReplies(r.Context(), database.ListRepliesParams{
+		ID:              chirp.ID,
+		CursorCreatedAt: p.cursorCreatedAt,
+		CursorID:        p.cursorID,
+		Limit:           p.queryLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	nextCursor := ""
+	if len(replies) > p.limit {
+		replies = replies[:p.limit]
+		last := replies[len(replies)-1]
+		nextCursor = p.next(w, r, last.CreatedAt, last.ID)
+	}
+
+	// The replies below the page's replies
+	var descendants []database.Chirp
+	if len(replies) > 0 {
+		first, last := replies[0], replies[len(replies)-1]
+		descendants, err = cfg.dbQueries.ListDescendants(r.Context(), database.ListDescendantsParams{
+			ID:             chirp.ID,
+			FirstCreatedAt: first.CreatedAt,
+			FirstID:        first.ID,
+			LastCreatedAt:  last.CreatedAt,
+			LastID:         last.ID,
+			MaxDepth:       threadDepth,
+			Limit:          maxThreadDescendants,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+			return
+		}
+	}
+
+	all := append(append(append([]database.Chirp{chirp}, ancestors...), replies...), descendants...)
+	details, err := cfg.loadChirpDetails(r.Context(), viewer, all)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	replyCounts, err := cfg.replyCounts(r.Context(), all)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	node := func(c database.Chirp) map[string]any {
+		return threadNode(c, details, replyCounts[c.ID])
+	}
+
+	// Build the tree; descendants are oldest first, so parents come before their replies.
+	nodes := map[uuid.UUID]map[string]any{}
+	tree := []map[string]any{}
+	for _, c := range replies {
+		n := node(c)
+		nodes[c.ID] = n
+		tree = append(tree, n)
+	}
+	for _, c := range descendants {
+		n := node(c)
+		nodes[c.ID] = n
+		if parent, ok := nodes[c.InReplyToID.UUID]; ok {
+			parent["replies"] = append(parent["replies"].([]map[string]any), n)
+		}
+	}
+
+	ancestorNodes := []map[string]any{}
+	for _, c := range ancestors {
+		ancestorNodes = append(ancestorNodes, node(c))
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]any{
+		"ancestors":   ancestorNodes,
+		"chirp":       node(chirp),
+		"replies":     tree,
+		"next_cursor": nextCursor,
+	})
+}
+
+// replyCounts counts the direct replies of each chirp with one query.
+func (cfg *apiConfig) replyCounts(ctx context.Context, chirps []database.Chirp) (map[uuid.UUID]int64, error) {
+	var ids []uuid.UUID
+	for _, c := range chirps {
+		ids = append(ids, c.ID)
+	}
+	rows, err := cfg.dbQueries.CountRepliesByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[uuid.UUID]int64{}
+	for _, row := range rows {
+		counts[row.InReplyToID.UUID] = row.ReplyCount
+	}
+	return counts, nil
+}
+
+// threadNode renders a chirp in a thread. A hidden chirp keeps only its place in the tree.
+func threadNode(chirp database.Chirp, details chirpDetails, replyCount int64) map[string]any {
+	var n map[string]any
+	hidden := details.hidden(chirp)
+	if hidden {
+		n = map[string]any{
+			"id":              chirp.ID.String(),
+			"in_reply_to_id":  nullID(chirp.InReplyToID),
+			"conversation_id": chirp.ConversationID.String(),
+		}
+	} else {
+		n = details.json(chirp)
+	}
+	n["deleted"] = hidden
+	n["reply_count"] = replyCount
+	n["replies"] = []map[string]any{}
+	return n
+}
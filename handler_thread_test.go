@@ -0,0 +1,125 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+type threadNodeJSON struct {
+	ID          string           `json:"id"`
+	Body        string           `json:"body"`
+	InReplyToID string           `json:"in_reply_to_id"`
+	Deleted     bool             `json:"deleted"`
+	ReplyCount  int64            `json:"reply_count"`
+	Replies     []threadNodeJSON `json:"replies"`
+}
+
+type threadResponse struct {
+	Ancestors  []threadNodeJSON `json:"ancestors"`
+	Chirp      threadNodeJSON   `json:"chirp"`
+	Replies    []threadNodeJSON `json:"replies"`
+	NextCursor string           `json:"next_cursor"`
+}
+
+func TestRepliesAndThread(t *testing.T) {
+	cfg := newTestConfig()
+	h := cfg.routes()
+	walt := signup(t, h, "walt@graymatter.com", "correct horse battery staple")
+	jesse := signup(t, h, "jesse@capncook.com", "correct horse battery staple")
+
+	type created struct {
+		ID             string `json:"id"`
+		InReplyToID    string `json:"in_reply_to_id"`
+		ConversationID string `json:"conversation_id"`
+	}
+	post := func(login loginResponse, body, inReplyTo string) created {
+		t.Helper()
+		var c created
+		req := map[string]string{"body": body}
+		if inReplyTo != "" {
+			req["in_reply_to"] = inReplyTo
+		}
+		if rec := doRequest(t, h, "POST", "/api/chirps", login.Token, req, &c); rec.Code != http.StatusCreated {
+			t.Fatalf("create chirp %q: status %d", body, rec.Code)
+		}
+		return c
+	}
+	thread := func(path string) threadResponse {
+		t.Helper()
+		var resp threadResponse
+		if rec := doRequest(t, h, "GET", path, "", nil, &resp); rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: status %d", path, rec.Code)
+		}
+		return resp
+	}
+
+	root := post(walt, "Say my name", "")
+	if root.ConversationID != root.ID || root.InReplyToID != "" {
+		t.Fatalf("root chirp = %+v", root)
+	}
+	first := post(jesse, "Heisenberg", root.ID)
+	if first.InReplyToID != root.ID || first.ConversationID != root.ID {
+		t.Fatalf("reply = %+v", first)
+	}
+	nested := post(walt, "You're goddamn right", first.ID)
+	second := post(jesse, "Yeah science", root.ID)
+	if nested.ConversationID != root.ID {
+		t.Errorf("nested reply conversation = %s, want %s", nested.ConversationID, root.ID)
+	}
+
+	if rec := doRequest(t, h, "POST", "/api/chirps", jesse.Token, map[string]string{"body": "hi", "in_reply_to": "nope"}, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("reply to an invalid id: status %d, want 400", rec.Code)
+	}
+
+	// The first page holds one direct reply with its own reply nested below.
+	page := thread("/api/chirps/" + root.ID + "/thread?limit=1")
+	if len(page.Ancestors) != 0 || page.Chirp.ID != root.ID || page.Chirp.ReplyCount != 2 {
+		t.Fatalf("thread of the root = %+v", page)
+	}
+	if len(page.Replies) != 1 || page.Replies[0].ID != first.ID || page.Replies[0].ReplyCount != 1 {
+		t.Fatalf("first page of replies = %+v", page.Replies)
+	}
+	if got := page.Replies[0].Replies; len(got) != 1 || got[0].ID != nested.ID {
+		t.Fatalf("nested replies = %+v", got)
+	}
+	if page.NextCursor == "" {
+		t.Fatal("no next_cursor with another reply left")
+	}
+	page = thread("/api/chirps/" + root.ID + "/thread?limit=1&cursor=" + page.NextCursor)
+	if len(page.Replies) != 1 || page.Replies[0].ID != second.ID || page.NextCursor != "" {
+		t.Fatalf("second page of replies = %+v", page)
+	}
+
+	page = thread("/api/chirps/" + nested.ID + "/thread")
+	if len(page.Ancestors) != 2 || page.Ancestors[0].ID != root.ID || page.Ancestors[1].ID != first.ID {
+		t.Fatalf("ancestors = %+v", page.Ancestors)
+	}
+
+	// Deleting a chirp with replies leaves a placeholder in the thread.
+	if rec := doRequest(t, h, "DELETE", "/api/chirps/"+first.ID, jesse.Token, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete chirp: status %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "GET", "/api/chirps/"+first.ID, "", nil, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("GET deleted chirp: status %d, want 404", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/chirps", walt.Token, map[string]string{"body": "hi", "in_reply_to": first.ID}, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("reply to a deleted chirp: status %d, want 404", rec.Code)
+	}
+	page = thread("/api/chirps/" + nested.ID + "/thread")
+	if len(page.Ancestors) != 2 || !page.Ancestors[1].Deleted || page.Ancestors[1].Body != "" {
+		t.Fatalf("ancestors after deleting the parent = %+v", page.Ancestors)
+	}
+	page = thread("/api/chirps/" + root.ID + "/thread")
+	if len(page.Replies) != 2 || !page.Replies[0].Deleted || len(page.Replies[0].Replies) != 1 {
+		t.Fatalf("replies after deleting one = %+v", page.Replies)
+	}
+
+	// Deleting its last reply removes the placeholder too.
+	if rec := doRequest(t, h, "DELETE", "/api/chirps/"+nested.ID, walt.Token, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete reply: status %d", rec.Code)
+	}
+	page = thread("/api/chirps/" + root.ID + "/thread")
+	if len(page.Replies) != 1 || page.Replies[0].ID != second.ID || page.Chirp.ReplyCount != 1 {
+		t.Fatalf("replies after deleting the last nested reply = %+v", page)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/Tadateki/Chirpy/internal/auth"
+	"github.com/Tadateki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// timelineHandler returns the chirps of the accounts the caller follows, newest first.
+// It merges the materialized timeline with the followed chirps that were not fanned out.
+func (cfg *apiConfig) timelineHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Authorization
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	userid, err := cfg.validateAccessToken(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	p, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+
+	// Fan-out-on-write
+	materialized, err := cfg.dbQueries.ListTimelineEntries(r.Context(), database.ListTimelineEntriesParams{
+		UserID:          userid,
+		CursorCreatedAt: p.cursorCreatedAt,
+		CursorID:        p.cursorID,
+		Limit:           p.queryLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	// Fan-out-on-read
+	pulled, err := cfg.dbQueries.ListUnfannedTimelineChirps(r.Context(), database.ListUnfannedTimelineChirpsParams{
+		UserID:          userid,
+		CursorCreatedAt: p.cursorCreatedAt,
+		CursorID:        p.cursorID,
+		Limit:           p.queryLimit(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	viewer := uuid.NullUUID{UUID: userid, Valid: true}
+	cfg.respondWithChirps(w, r, p, viewer, mergeChirpsDesc(materialized, pulled))
+}
+
+// mergeChirpsDesc merges two lists sorted newest first, dropping duplicates.
+// A chirp is in both while its fan-out is finishing.
+func mergeChirpsDesc(a, b []database.Chirp) []database.Chirp {
+	out := make([]database.Chirp, 0, len(a)+len(b))
+	seen := map[uuid.UUID]bool{}
+	for len(a) > 0 || len(b) > 0 {
+		var next database.Chirp
+		if len(b) == 0 || (len(a) > 0 && !chirpBefore(a[0], b[0])) {
+			next, a = a[0], a[1:]
+		} else {
+			next, b = b[0], b[1:]
+		}
+		if !seen[next.ID] {
+			seen[next.ID] = true
+			out = append(out, next)
+		}
+	}
+	return out
+}
+
+// chirpBefore orders chirps by (created_at, id) like the keyset queries.
+func chirpBefore(x, y database.Chirp) bool {
+	if !x.CreatedAt.Equal(y.CreatedAt) {
+		return x.CreatedAt.Before(y.CreatedAt)
+	}
+	return x.ID.String() < y.ID.String()
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Tadateki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestTimelineHybridFanout(t *testing.T) {
+	cfg := newTestConfig()
+	// Accounts with more than one follower are served on read.
+	cfg.timeline = newTimelineFanout(cfg.dbQueries, 1, 1)
+	h := cfg.routes()
+	saul := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+	kim := signup(t, h, "kim@wexler.com", "correct horse battery staple")
+	gus := signup(t, h, "gus@pollos.com", "correct horse battery staple")
+
+	for _, f := range [][2]loginResponse{{saul, kim}, {saul, gus}, {kim, gus}} {
+		if rec := doRequest(t, h, "POST", "/api/users/"+f[1].ID+"/follow", f[0].Token, nil, nil); rec.Code != http.StatusNoContent {
+			t.Fatalf("follow: status %d", rec.Code)
+		}
+	}
+
+	var chirps []database.Chirp
+	for _, c := range []struct {
+		login loginResponse
+		body  string
+	}{
+		{kim, "Pick a lane"},
+		{gus, "I hide in plain sight"},
+		{kim, "Remember me"},
+	} {
+		var created struct {
+			ID uuid.UUID `json:"id"`
+		}
+		if rec := doRequest(t, h, "POST", "/api/chirps", c.login.Token, map[string]string{"body": c.body}, &created); rec.Code != http.StatusCreated {
+			t.Fatalf("create chirp: status %d", rec.Code)
+		}
+		chirp, err := cfg.dbQueries.GetChirp(context.Background(), created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chirps = append(chirps, chirp)
+	}
+	cfg.timeline.wait()
+
+	for _, c := range chirps {
+		got, _ := cfg.dbQueries.GetChirp(context.Background(), c.ID)
+		if heavy := c.UserID.String() == gus.ID; got.FannedOutAt.Valid == heavy {
+			t.Errorf("chirp %q fanned out = %v", c.Body, got.FannedOutAt.Valid)
+		}
+	}
+
+	want := []string{"Remember me", "I hide in plain sight", "Pick a lane"}
+	check := func(when string) {
+		t.Helper()
+		var got []string
+		path := "/api/timeline?limit=2"
+		for path != "" {
+			var page chirpsPage
+			if rec := doRequest(t, h, "GET", path, saul.Token, nil, &page); rec.Code != http.StatusOK {
+				t.Fatalf("%s: timeline status %d", when, rec.Code)
+			}
+			for _, c := range page.Chirps {
+				got = append(got, c.Body)
+			}
+			path = ""
+			if page.NextCursor != "" {
+				path = "/api/timeline?limit=2&cursor=" + page.NextCursor
+			}
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%s: timeline = %q, want %q", when, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("%s: timeline = %q, want %q", when, got, want)
+			}
+		}
+	}
+	check("after fan-out")
+
+	if err := rebuildTimelines(context.Background(), cfg.dbQueries, 1); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	check("after rebuild")
+
+	// Unfollowing drops the materialized chirps too.
+	if rec := doRequest(t, h, "DELETE", "/api/users/"+kim.ID+"/follow", saul.Token, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("unfollow: status %d", rec.Code)
+	}
+	want = []string{"I hide in plain sight"}
+	check("after unfollowing")
+}
+
+func TestMergeChirpsDesc(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	chirp := func(id byte, minute int) database.Chirp {
+		return database.Chirp{ID: uuid.UUID{15: id}, CreatedAt: base.Add(time.Duration(minute) * time.Minute)}
+	}
+
+	materialized := []database.Chirp{chirp(5, 5), chirp(3, 3), chirp(1, 1)}
+	pulled := []database.Chirp{chirp(4, 4), chirp(3, 3), chirp(2, 1)}
+	got := mergeChirpsDesc(materialized, pulled)
+
+	want := []byte{5, 4, 3, 2, 1}
+	if len(got) != len(want) {
+		t.Fatalf("merged %d chirps, want %d", len(got), len(want))
+	}
+	for i, id := range want {
+		if got[i].ID[15] != id {
+			t.Errorf("chirp %d = %v, want id %d", i, got[i].ID, id)
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/Tadateki/Chirpy/internal/auth"
 	"github.com/Tadateki/Chirpy/internal/database"
@@ -22,8 +25,16 @@ func (cfg *apiConfig) createUserHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !validEmail(req.Email) {
+		respondWithError(w, http.StatusBadRequest, "invalid email address")
+		return
+	}
+	if !cfg.checkPasswordPolicy(w, r, req.Password, req.Email) {
+		return
+	}
+
 	// PasswordをHash化
-	HashedPassword, err := auth.HashPassword(req.Password)
+	HashedPassword, err := cfg.passwordParams.Hash(req.Password)
 	if err != nil {
 		http.Error(w, `{"error":"Hash Password Fail Error"}`, http.StatusBadRequest)
 	}
@@ -40,13 +51,20 @@ func (cfg *apiConfig) createUserHandler(w http.ResponseWriter, r *http.Request)
 		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
 		return
 	}
+
+	// The account exists either way; a failed mail can be retried via /api/verify-email/resend.
+	if err := cfg.sendVerificationEmail(r.Context(), user); err != nil {
+		log.Printf("verification mail to %s: %v", user.Email, err)
+	}
+
 	// 作成したユーザーのIDを返す
 	respondWithJSON(w, http.StatusCreated, map[string]any{
-		"id":            user.ID.String(),
-		"created_at":    user.CreatedAt.String(),
-		"updated_at":    user.UpdatedAt.String(),
-		"email":         user.Email,
-		"is_chirpy_red": user.IsChirpyRed,
+		"id":             user.ID.String(),
+		"created_at":     user.CreatedAt.String(),
+		"updated_at":     user.UpdatedAt.String(),
+		"email":          user.Email,
+		"is_chirpy_red":  user.IsChirpyRed,
+		"email_verified": user.EmailVerifiedAt.Valid,
 	})
 
 }
@@ -60,7 +78,7 @@ func (cfg *apiConfig) updateUserHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	userid, err := auth.ValidateJWT(token, cfg.tokenSecret)
+	userid, err := cfg.validateAccessToken(r.Context(), token)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
 		return
@@ -78,10 +96,25 @@ func (cfg *apiConfig) updateUserHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !validEmail(req.Email) {
+		respondWithError(w, http.StatusBadRequest, "invalid email address")
+		return
+	}
+	if !cfg.checkPasswordPolicy(w, r, req.Password, req.Email) {
+		return
+	}
+
+	current, err := cfg.dbQueries.GetUserFromUserID(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
 	// PasswordをHash化
-	HashedPassword, err := auth.HashPassword(req.Password)
+	HashedPassword, err := cfg.passwordParams.Hash(req.Password)
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, "Hash Password Fail")
+		return
 	}
 
 	arg := database.UpdateUserParams{
@@ -90,24 +123,279 @@ func (cfg *apiConfig) updateUserHandler(w http.ResponseWriter, r *http.Request)
 		ID:             userid,
 	}
 
+	// A new address loses its verification (see UpdateUser) and gets a new mail below.
 	err = cfg.dbQueries.UpdateUser(r.Context(), arg)
+	if database.IsUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "email address is already in use")
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Fail to Update user")
 		return
 	}
 
+	// The password was replaced, so sessions and access tokens from before stop working.
+	err = cfg.dbQueries.RevokeAllRefreshTokensForUser(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Fail to Revoke refresh tokens")
+		return
+	}
+	err = cfg.revokeAccessTokens(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Fail to Revoke access tokens")
+		return
+	}
+
 	user, err := cfg.dbQueries.GetUserFromUserID(r.Context(), userid)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Fail to Load updated information")
 		return
 	}
 
+	if user.Email != current.Email {
+		if err := cfg.sendVerificationEmail(r.Context(), user); err != nil {
+			log.Printf("verification mail to %s: %v", user.Email, err)
+		}
+	}
+
 	// 作成したユーザーのIDを返す
 	respondWithJSON(w, http.StatusOK, map[string]any{
-		"id":            user.ID.String(),
-		"created_at":    user.CreatedAt.String(),
-		"updated_at":    user.UpdatedAt.String(),
-		"email":         user.Email,
-		"is_chirpy_red": user.IsChirpyRed,
+		"id":             user.ID.String(),
+		"created_at":     user.CreatedAt.String(),
+		"updated_at":     user.UpdatedAt.String(),
+		"email":          user.Email,
+		"is_chirpy_red":  user.IsChirpyRed,
+		"email_verified": user.EmailVerifiedAt.Valid,
+	})
+}
+
+// patchUserHandler updates only the fields that are sent. Changing the email or
+// password needs the current password; a new email has to be verified again.
+// Profile fields (handle, display_name, bio, avatar_url) need no re-authentication.
+func (cfg *apiConfig) patchUserHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Authorization
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	userid, err := cfg.validateAccessToken(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	type patchUserRequest struct {
+		Email           *string `json:"email"`
+		Password        *string `json:"password"`
+		CurrentPassword string  `json:"current_password"`
+		Handle          *string `json:"handle"`
+		DisplayName     *string `json:"display_name"`
+		Bio             *string `json:"bio"`
+		AvatarURL       *string `json:"avatar_url"`
+	}
+
+	var req patchUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	user, err := cfg.dbQueries.GetUserFromUserID(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	emailChanged := req.Email != nil && *req.Email != user.Email
+	passwordChanged := req.Password != nil
+
+	// Profile fields keep their current value unless sent; an empty handle removes it.
+	patch := database.PatchUserParams{
+		Handle:      user.Handle,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		AvatarUrl:   user.AvatarUrl,
+		ID:          userid,
+	}
+	if req.Handle != nil {
+		handle := normalizeHandle(*req.Handle)
+		patch.Handle = sql.NullString{String: handle, Valid: handle != ""}
+	}
+	if req.DisplayName != nil {
+		patch.DisplayName = strings.TrimSpace(*req.DisplayName)
+	}
+	if req.Bio != nil {
+		patch.Bio = strings.TrimSpace(*req.Bio)
+	}
+	if req.AvatarURL != nil {
+		patch.AvatarUrl = *req.AvatarURL
+	}
+	profileChanged := patch.Handle != user.Handle || patch.DisplayName != user.DisplayName ||
+		patch.Bio != user.Bio || patch.AvatarUrl != user.AvatarUrl
+
+	// Validate everything before writing anything
+	if patch.Handle.Valid && !validHandle(patch.Handle.String) {
+		respondWithError(w, http.StatusBadRequest, "invalid handle; use 3-30 letters, digits or underscores")
+		return
+	}
+	if !validDisplayName(patch.DisplayName) {
+		respondWithError(w, http.StatusBadRequest, "display name is too long")
+		return
+	}
+	if !validBio(patch.Bio) {
+		respondWithError(w, http.StatusBadRequest, "bio is too long")
+		return
+	}
+	if !validAvatarURL(patch.AvatarUrl) {
+		respondWithError(w, http.StatusBadRequest, "invalid avatar URL")
+		return
+	}
+	if emailChanged && !validEmail(*req.Email) {
+		respondWithError(w, http.StatusBadRequest, "invalid email address")
+		return
+	}
+	if passwordChanged {
+		email := user.Email
+		if emailChanged {
+			email = *req.Email
+		}
+		if !cfg.checkPasswordPolicy(w, r, *req.Password, email) {
+			return
+		}
+	}
+
+	if emailChanged || passwordChanged {
+		if req.CurrentPassword == "" {
+			respondWithError(w, http.StatusBadRequest, "current_password is required to change email or password")
+			return
+		}
+		if !cfg.reauthenticate(w, r, user, req.CurrentPassword) {
+			return
+		}
+	}
+
+	// Unique fields are checked up front, so a conflict can name the field.
+	if emailChanged {
+		_, err := cfg.dbQueries.GetUserFromEmail(r.Context(), *req.Email)
+		if err == nil {
+			respondWithError(w, http.StatusConflict, "email address is already in use")
+			return
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+			return
+		}
+	}
+	if patch.Handle.Valid && patch.Handle != user.Handle {
+		_, err := cfg.dbQueries.GetUserFromHandle(r.Context(), patch.Handle)
+		if err == nil {
+			respondWithError(w, http.StatusConflict, "handle is already taken")
+			return
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+			return
+		}
+	}
+
+	// One UPDATE, so the change is applied completely or not at all.
+	if emailChanged {
+		patch.Email = sql.NullString{String: *req.Email, Valid: true}
+	}
+	if passwordChanged {
+		HashedPassword, err := cfg.passwordParams.Hash(*req.Password)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Hash Password Fail")
+			return
+		}
+		patch.HashedPassword = sql.NullString{String: HashedPassword, Valid: true}
+	}
+
+	if profileChanged || emailChanged || passwordChanged {
+		err = cfg.dbQueries.PatchUser(r.Context(), patch)
+		if database.IsUniqueViolation(err) {
+			respondWithError(w, http.StatusConflict, "email address or handle is already in use")
+			return
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Fail to Update user")
+			return
+		}
+		if patch.AvatarUrl != user.AvatarUrl {
+			cfg.deleteProfileImage(r.Context(), avatarImage, user.AvatarKey)
+		}
+	}
+
+	if passwordChanged {
+		// The password was replaced, so sessions and access tokens from before stop working.
+		err = cfg.dbQueries.RevokeAllRefreshTokensForUser(r.Context(), userid)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Fail to Revoke refresh tokens")
+			return
+		}
+		err = cfg.revokeAccessTokens(r.Context(), userid)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Fail to Revoke access tokens")
+			return
+		}
+	}
+
+	user, err = cfg.dbQueries.GetUserFromUserID(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Fail to Load updated information")
+		return
+	}
+
+	if emailChanged {
+		if err := cfg.sendVerificationEmail(r.Context(), user); err != nil {
+			log.Printf("verification mail to %s: %v", user.Email, err)
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]any{
+		"id":             user.ID.String(),
+		"created_at":     user.CreatedAt.String(),
+		"updated_at":     user.UpdatedAt.String(),
+		"email":          user.Email,
+		"is_chirpy_red":  user.IsChirpyRed,
+		"email_verified": user.EmailVerifiedAt.Valid,
+		"handle":         user.Handle.String,
+		"display_name":   user.DisplayName,
+		"bio":            user.Bio,
+		"avatar_url":     user.AvatarUrl,
 	})
 }
+
+// reauthenticate checks the password of a logged-in user before a sensitive change. Failures count
+// towards the login throttle, so a stolen access token cannot be used to guess the password.
+// It writes the error response and returns false when the request must stop.
+func (cfg *apiConfig) reauthenticate(w http.ResponseWriter, r *http.Request, user database.User, password string) bool {
+	keys := cfg.loginKeys(user.Email, clientIP(r))
+	status, retryAfter, err := cfg.checkLoginThrottle(r.Context(), keys)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return false
+	}
+	if status != 0 {
+		respondWithThrottle(w, status, retryAfter)
+		return false
+	}
+
+	chk, err := auth.CheckPasswordHash(password, user.HashedPassword)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Password Check Error")
+		return false
+	}
+	if !chk {
+		if err := cfg.recordLoginFailure(r.Context(), keys); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+			return false
+		}
+		respondWithError(w, http.StatusForbidden, "current password is incorrect")
+		return false
+	}
+	return true
+}
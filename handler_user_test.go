@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPatchUser(t *testing.T) {
+	cfg := newTestConfig()
+	h := cfg.routes()
+	login := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+	signup(t, h, "kim@wexlermcgill.com", "correct horse battery staple")
+
+	type userResponse struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+
+	// Sending nothing (or the current email) changes nothing and needs no re-authentication.
+	var got userResponse
+	if rec := doRequest(t, h, "PATCH", "/api/users", login.Token, map[string]string{"email": "saul@bettercall.com"}, &got); rec.Code != http.StatusOK {
+		t.Fatalf("no-op patch: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if got.Email != "saul@bettercall.com" {
+		t.Errorf("no-op patch returned email %q", got.Email)
+	}
+
+	if rec := doRequest(t, h, "PATCH", "/api/users", login.Token, map[string]string{"email": "jimmy@bettercall.com"}, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("email change without current_password: status %d, want 400", rec.Code)
+	}
+	wrong := map[string]string{"email": "jimmy@bettercall.com", "current_password": "wrong"}
+	if rec := doRequest(t, h, "PATCH", "/api/users", login.Token, wrong, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("email change with a wrong current_password: status %d, want 403", rec.Code)
+	}
+	taken := map[string]string{"email": "kim@wexlermcgill.com", "current_password": "correct horse battery staple", "handle": "saulgoodman", "bio": "Lawyer"}
+	if rec := doRequest(t, h, "PATCH", "/api/users", login.Token, taken, nil); rec.Code != http.StatusConflict {
+		t.Errorf("email change to a taken address: status %d, want 409", rec.Code)
+	}
+	if u, _ := cfg.dbQueries.GetUserFromEmail(context.Background(), "saul@bettercall.com"); u.Handle.Valid || u.Bio != "" {
+		t.Errorf("rejected patch still saved handle %v, bio %q", u.Handle, u.Bio)
+	}
+	invalid := map[string]string{"email": "not-an-email", "current_password": "correct horse battery staple"}
+	if rec := doRequest(t, h, "PATCH", "/api/users", login.Token, invalid, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("email change to an invalid address: status %d, want 400", rec.Code)
+	}
+
+	change := map[string]string{"email": "jimmy@bettercall.com", "current_password": "correct horse battery staple"}
+	if rec := doRequest(t, h, "PATCH", "/api/users", login.Token, change, &got); rec.Code != http.StatusOK {
+		t.Fatalf("email change: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if got.Email != "jimmy@bettercall.com" || got.EmailVerified {
+		t.Errorf("email change returned %+v, want the new unverified address", got)
+	}
+	if m := verifyLinkRe.FindStringSubmatch(cfg.mailer.(*testMailer).last(t, "jimmy@bettercall.com").Body); m == nil {
+		t.Errorf("no verification link sent to the new address")
+	}
+
+	// An email change alone keeps the session; a password change does not.
+	pw := map[string]string{"password": "tr0ub4dor and 3 saxophones", "current_password": "correct horse battery staple"}
+	if rec := doRequest(t, h, "PATCH", "/api/users", login.Token, pw, nil); rec.Code != http.StatusOK {
+		t.Fatalf("password change: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if rec := doRequest(t, h, "PATCH", "/api/users", login.Token, nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("patch with an access token issued before the password change: status %d, want 401", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/refresh", login.RefreshToken, nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("refresh with a refresh token issued before the password change: status %d, want 401", rec.Code)
+	}
+
+	creds := map[string]string{"email": "jimmy@bettercall.com", "password": "tr0ub4dor and 3 saxophones"}
+	if rec := doRequest(t, h, "POST", "/api/login", "", creds, nil); rec.Code != http.StatusOK {
+		t.Errorf("login with the new email and password: status %d, want 200", rec.Code)
+	}
+}
+
+func TestPublicProfile(t *testing.T) {
+	h := newTestConfig().routes()
+	login := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+	other := signup(t, h, "kim@wexlermcgill.com", "correct horse battery staple")
+
+	profile := map[string]string{"handle": "@SaulGoodman", "display_name": "Saul Goodman", "bio": "Better call Saul!"}
+	if rec := doRequest(t, h, "PATCH", "/api/users", login.Token, profile, nil); rec.Code != http.StatusOK {
+		t.Fatalf("set profile: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if rec := doRequest(t, h, "PATCH", "/api/users", other.Token, map[string]string{"handle": "saulgoodman"}, nil); rec.Code != http.StatusConflict {
+		t.Errorf("take an existing handle: status %d, want 409", rec.Code)
+	}
+	for _, bad := range []map[string]string{
+		{"handle": "no spaces"},
+		{"handle": "export"},
+		{"avatar_url": "javascript:alert(1)"},
+		{"bio": strings.Repeat("x", maxBioLength+1)},
+	} {
+		if rec := doRequest(t, h, "PATCH", "/api/users", other.Token, bad, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("patch %v: status %d, want 400", bad, rec.Code)
+		}
+	}
+
+	var got map[string]any
+	if rec := doRequest(t, h, "GET", "/api/users/@saulgoodman", "", nil, &got); rec.Code != http.StatusOK {
+		t.Fatalf("get profile: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if got["handle"] != "saulgoodman" || got["display_name"] != "Saul Goodman" || got["bio"] != "Better call Saul!" {
+		t.Errorf("profile = %v", got)
+	}
+	if _, ok := got["email"]; ok {
+		t.Errorf("public profile exposes the email address")
+	}
+	if rec := doRequest(t, h, "GET", "/api/users/nobody", "", nil, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown handle: status %d, want 404", rec.Code)
+	}
+
+	var chirp struct {
+		ID string `json:"id"`
+	}
+	if rec := doRequest(t, h, "POST", "/api/chirps", login.Token, map[string]string{"body": "I know a guy"}, &chirp); rec.Code != http.StatusCreated {
+		t.Fatalf("create chirp: status %d", rec.Code)
+	}
+	var single map[string]any
+	if rec := doRequest(t, h, "GET", "/api/chirps/"+chirp.ID, "", nil, &single); rec.Code != http.StatusOK {
+		t.Fatalf("get chirp: status %d", rec.Code)
+	}
+	if single["author_handle"] != "saulgoodman" || single["author_display_name"] != "Saul Goodman" {
+		t.Errorf("chirp author = %q / %q", single["author_handle"], single["author_display_name"])
+	}
+	var list struct {
+		Chirps []map[string]any `json:"chirps"`
+	}
+	if rec := doRequest(t, h, "GET", "/api/chirps", "", nil, &list); rec.Code != http.StatusOK || len(list.Chirps) != 1 {
+		t.Fatalf("list chirps: status %d, %d chirps", rec.Code, len(list.Chirps))
+	}
+	if list.Chirps[0]["author_handle"] != "saulgoodman" {
+		t.Errorf("listed chirp author_handle = %q", list.Chirps[0]["author_handle"])
+	}
+}
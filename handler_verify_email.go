@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Tadateki/Chirpy/internal/auth"
+	"github.com/Tadateki/Chirpy/internal/database"
+	"github.com/Tadateki/Chirpy/internal/mailer"
+)
+
+const emailVerificationExpiresIn = 48 * time.Hour
+
+// sendVerificationEmail mails a link that proves the user owns user.Email.
+func (cfg *apiConfig) sendVerificationEmail(ctx context.Context, user database.User) error {
+	verify_token_str, err := auth.MakeRefreshToken()
+	if err != nil {
+		return err
+	}
+
+	err = cfg.dbQueries.CreateEmailVerificationToken(ctx, database.CreateEmailVerificationTokenParams{
+		TokenHash: auth.HashRefreshToken(verify_token_str),
+		UserID:    user.ID,
+		Email:     user.Email,
+		ExpiresAt: time.Now().UTC().Add(emailVerificationExpiresIn),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := cfg.publicURL + "/api/verify-email?token=" + url.QueryEscape(verify_token_str)
+	return cfg.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your Chirpy email address",
+		Body: fmt.Sprintf("Welcome to Chirpy! Open this link to verify your email address:\n\n%s\n\n"+
+			"The link expires in %s.\n", link, emailVerificationExpiresIn),
+	})
+}
+
+func (cfg *apiConfig) verifyEmailHandler(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		respondWithError(w, http.StatusBadRequest, "missing token")
+		return
+	}
+
+	row, err := cfg.dbQueries.ConsumeEmailVerificationToken(r.Context(), database.ConsumeEmailVerificationTokenParams{
+		Now:       time.Now().UTC(),
+		TokenHash: auth.HashRefreshToken(token),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusBadRequest, "invalid or expired token")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+
+	// Only verifies the address the token was sent to; a changed email needs a new token.
+	verified, err := cfg.dbQueries.MarkEmailVerified(r.Context(), database.MarkEmailVerifiedParams{
+		ID:    row.UserID,
+		Email: row.Email,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	if verified == 0 {
+		respondWithError(w, http.StatusBadRequest, "invalid or expired token")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]any{
+		"email":          row.Email,
+		"email_verified": true,
+	})
+}
+
+func (cfg *apiConfig) resendVerificationHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Authorization
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	userid, err := cfg.validateAccessToken(r.Context(), token)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no authorization in header")
+		return
+	}
+
+	user, err := cfg.dbQueries.GetUserFromUserID(r.Context(), userid)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "ERR_DB")
+		return
+	}
+	if user.EmailVerifiedAt.Valid {
+		respondWithError(w, http.StatusConflict, "email address is already verified")
+		return
+	}
+
+	if err := cfg.sendVerificationEmail(r.Context(), user); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Fail to Send email")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
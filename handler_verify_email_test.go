@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+)
+
+var verifyLinkRe = regexp.MustCompile(`http://chirpy\.test(/api/verify-email\?token=\S+)`)
+
+func TestEmailVerification(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.requireVerifiedEmail = true
+	h := cfg.routes()
+
+	creds := map[string]string{"email": "not-an-email", "password": "correct horse battery staple"}
+	if rec := doRequest(t, h, "POST", "/api/users", "", creds, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("create user with an invalid email: status %d, want 400", rec.Code)
+	}
+
+	login := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+	chirp := map[string]string{"body": "I know a guy"}
+	if rec := doRequest(t, h, "POST", "/api/chirps", login.Token, chirp, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("create chirp before verification: status %d, want 403", rec.Code)
+	}
+
+	m := verifyLinkRe.FindStringSubmatch(cfg.mailer.(*testMailer).last(t, "saul@bettercall.com").Body)
+	if m == nil {
+		t.Fatalf("verification mail has no link")
+	}
+	path := m[1]
+
+	if rec := doRequest(t, h, "GET", "/api/verify-email?token="+url.QueryEscape("bogus"), "", nil, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("verify with a bogus token: status %d, want 400", rec.Code)
+	}
+
+	var verified struct {
+		EmailVerified bool `json:"email_verified"`
+	}
+	if rec := doRequest(t, h, "GET", path, "", nil, &verified); rec.Code != http.StatusOK || !verified.EmailVerified {
+		t.Fatalf("verify: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if rec := doRequest(t, h, "GET", path, "", nil, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("verify with a used token: status %d, want 400", rec.Code)
+	}
+
+	if rec := doRequest(t, h, "POST", "/api/chirps", login.Token, chirp, nil); rec.Code != http.StatusCreated {
+		t.Errorf("create chirp after verification: status %d, want 201", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/verify-email/resend", login.Token, nil, nil); rec.Code != http.StatusConflict {
+		t.Errorf("resend when already verified: status %d, want 409", rec.Code)
+	}
+
+	// PUT /api/users validates a new address and makes it unverified again.
+	update := map[string]string{"email": "not-an-email", "password": "correct horse battery staple"}
+	if rec := doRequest(t, h, "PUT", "/api/users", login.Token, update, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("PUT an invalid email: status %d, want 400", rec.Code)
+	}
+	update["email"] = "jimmy@bettercall.com"
+	var updated struct {
+		EmailVerified bool `json:"email_verified"`
+	}
+	if rec := doRequest(t, h, "PUT", "/api/users", login.Token, update, &updated); rec.Code != http.StatusOK || updated.EmailVerified {
+		t.Fatalf("PUT a new email: status %d, email_verified %v", rec.Code, updated.EmailVerified)
+	}
+	if verifyLinkRe.FindStringSubmatch(cfg.mailer.(*testMailer).last(t, "jimmy@bettercall.com").Body) == nil {
+		t.Errorf("no verification mail for the new address")
+	}
+	var relogin loginResponse
+	if rec := doRequest(t, h, "POST", "/api/login", "", update, &relogin); rec.Code != http.StatusOK {
+		t.Fatalf("login with the new email: status %d", rec.Code)
+	}
+	if rec := doRequest(t, h, "POST", "/api/chirps", relogin.Token, chirp, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("create chirp after changing to an unverified email: status %d, want 403", rec.Code)
+	}
+}
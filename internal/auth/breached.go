@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// BreachedPasswords answers k-anonymity range queries like the Pwned Passwords
+// API: given the first 5 hex characters of a password's SHA-1, it returns the
+// remaining 35 characters of every breached hash with that prefix and how often
+// each was seen. Neither the password nor its full hash leaves the caller.
+type BreachedPasswords interface {
+	Range(ctx context.Context, prefix string) (map[string]int, error)
+}
+
+// IsBreached returns how often password was seen in breaches (0 if never).
+func IsBreached(ctx context.Context, src BreachedPasswords, password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	suffixes, err := src.Range(ctx, hash[:5])
+	if err != nil {
+		return 0, err
+	}
+	return suffixes[hash[5:]], nil
+}
+
+// BreachedDir is a local copy of the Pwned Passwords corpus as one file per
+// prefix (ABCDE.txt holding "SUFFIX:COUNT" lines), the layout written by the
+// official downloader when it is not asked for a single file.
+type BreachedDir struct {
+	dir string
+}
+
+func NewBreachedDir(dir string) (*BreachedDir, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	return &BreachedDir{dir: dir}, nil
+}
+
+func (b *BreachedDir) Range(ctx context.Context, prefix string) (map[string]int, error) {
+	if len(prefix) != 5 {
+		return nil, fmt.Errorf("range prefix must be 5 hex characters, got %q", prefix)
+	}
+	if _, err := hex.DecodeString(prefix + "0"); err != nil {
+		return nil, fmt.Errorf("range prefix must be 5 hex characters, got %q", prefix)
+	}
+
+	f, err := os.Open(filepath.Join(b.dir, strings.ToUpper(prefix)+".txt"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]int{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	suffixes := make(map[string]int)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		suffix, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			continue
+		}
+		suffixes[strings.ToUpper(suffix)] = n
+	}
+	return suffixes, scanner.Err()
+}
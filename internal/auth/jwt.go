@@ -9,15 +9,40 @@ import (
 	"github.com/google/uuid"
 )
 
-func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration) (string, error) {
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// MFAAudience marks tokens that only prove the password step of a two-factor login.
+const MFAAudience = "chirpy-mfa"
+
+// Claims are the access token claims. ID (jti) is unique per token.
+type Claims struct {
+	jwt.RegisteredClaims
+	Role string `json:"role,omitempty"`
+}
 
-	claims := &jwt.RegisteredClaims{
-		Issuer:    "chirpy",
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
-		Subject:   userID.String(),
+func (c *Claims) UserID() (uuid.UUID, error) {
+	return uuid.Parse(c.Subject)
+}
+
+func newClaims(userID uuid.UUID, role string, now time.Time, expiresIn time.Duration) *Claims {
+	return &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "chirpy",
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiresIn)),
+			Subject:   userID.String(),
+			ID:        uuid.NewString(),
+		},
+		Role: role,
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+}
+
+func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration) (string, error) {
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, newClaims(userID, RoleUser, time.Now(), expiresIn))
 	ss, err := token.SignedString([]byte(tokenSecret))
 	if err != nil {
 		return "", err
@@ -26,27 +51,30 @@ func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration) (str
 }
 
 func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (any, error) {
+	claims, err := parseClaims(tokenString, func(token *jwt.Token) (any, error) {
 		return []byte(tokenSecret), nil
 	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return claims.UserID()
+}
+
+func parseClaims(tokenString string, keyFunc jwt.Keyfunc, opts ...jwt.ParserOption) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, keyFunc, opts...)
 
 	if err != nil {
 		// エラーメッセージに "token is expired" が含まれているか判定
 		if strings.Contains(err.Error(), "token is expired") {
-			return uuid.Nil, jwt.ErrTokenExpired
+			return nil, jwt.ErrTokenExpired
 		}
-		return uuid.Nil, fmt.Errorf("invalid token: %w", err)
+		return nil, fmt.Errorf("invalid token: %w", err)
 	}
 
-	claim, ok := token.Claims.(*jwt.RegisteredClaims)
+	claim, ok := token.Claims.(*Claims)
 	if !ok || !token.Valid {
-		return uuid.Nil, jwt.ErrTokenInvalidClaims
-	}
-
-	id, err := uuid.Parse(claim.Subject)
-	if err != nil {
-		return uuid.Nil, err
+		return nil, jwt.ErrTokenInvalidClaims
 	}
 
-	return id, nil
+	return claim, nil
 }
@@ -78,3 +78,54 @@ func TestValidateJWT_WrongSecret(t *testing.T) {
 		t.Fatalf("ValidateJWT did not return error for wrong secret")
 	}
 }
+
+func TestMakeJWT_UniqueTokenID(t *testing.T) {
+	keys, err := GenerateEd25519Keyring()
+	if err != nil {
+		t.Fatalf("GenerateEd25519Keyring returned error: %v", err)
+	}
+
+	userID := uuid.New()
+	seen := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		tokenString, err := keys.MakeJWT(userID, RoleUser, time.Hour)
+		if err != nil {
+			t.Fatalf("MakeJWT returned error: %v", err)
+		}
+		claims, err := keys.ParseJWT(tokenString)
+		if err != nil {
+			t.Fatalf("ParseJWT returned error: %v", err)
+		}
+		if claims.ID == "" {
+			t.Fatalf("token has no jti")
+		}
+		if seen[claims.ID] {
+			t.Fatalf("jti %s issued twice", claims.ID)
+		}
+		seen[claims.ID] = true
+		if claims.IssuedAt == nil {
+			t.Errorf("token has no iat")
+		}
+	}
+}
+
+func TestMakeJWT_RoleClaim(t *testing.T) {
+	keys, err := GenerateEd25519Keyring()
+	if err != nil {
+		t.Fatalf("GenerateEd25519Keyring returned error: %v", err)
+	}
+
+	for _, role := range []string{RoleUser, RoleAdmin} {
+		tokenString, err := keys.MakeJWT(uuid.New(), role, time.Hour)
+		if err != nil {
+			t.Fatalf("MakeJWT returned error: %v", err)
+		}
+		claims, err := keys.ParseJWT(tokenString)
+		if err != nil {
+			t.Fatalf("ParseJWT returned error: %v", err)
+		}
+		if claims.Role != role {
+			t.Errorf("token role %q, want %q", claims.Role, role)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Keyring signs access tokens with the current asymmetric key and verifies
+// tokens signed by the current or a previous key, selected by the "kid" header.
+// Tokens without a "kid" are HS256 tokens from before key rotation and are
+// accepted only when a legacy secret is configured, and only until its deadline.
+type Keyring struct {
+	current      *verificationKey
+	signer       crypto.Signer
+	keys         map[string]*verificationKey
+	order        []string
+	legacySecret []byte
+	legacyUntil  time.Time
+}
+
+type verificationKey struct {
+	kid    string
+	method jwt.SigningMethod
+	public crypto.PublicKey
+}
+
+// JWK is a public key in RFC 7517 form.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewKeyring builds a keyring that signs with signer (Ed25519 or RSA) and also
+// accepts tokens signed by the previous keys.
+func NewKeyring(signer crypto.Signer, previous ...crypto.PublicKey) (*Keyring, error) {
+	k := &Keyring{
+		signer: signer,
+		keys:   make(map[string]*verificationKey),
+	}
+
+	current, err := newVerificationKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	k.current = current
+	k.add(current)
+
+	for _, pub := range previous {
+		vk, err := newVerificationKey(pub)
+		if err != nil {
+			return nil, err
+		}
+		k.add(vk)
+	}
+	return k, nil
+}
+
+// LoadKeyring reads PEM encoded keys from disk. previousPaths may contain
+// private or public keys.
+func LoadKeyring(signingPath string, previousPaths []string) (*Keyring, error) {
+	key, err := readPEMKey(signingPath)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s: signing key must be a private key", signingPath)
+	}
+
+	var previous []crypto.PublicKey
+	for _, path := range previousPaths {
+		key, err := readPEMKey(path)
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := key.(crypto.Signer); ok {
+			key = s.Public()
+		}
+		previous = append(previous, key)
+	}
+
+	return NewKeyring(signer, previous...)
+}
+
+// GenerateEd25519Keyring returns a keyring with a fresh, unpersisted key.
+func GenerateEd25519Keyring() (*Keyring, error) {
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyring(priv)
+}
+
+// WithLegacySecret makes the keyring accept HS256 tokens without a "kid" until the given time.
+func (k *Keyring) WithLegacySecret(secret string, until time.Time) *Keyring {
+	if secret != "" {
+		k.legacySecret = []byte(secret)
+		k.legacyUntil = until
+	}
+	return k
+}
+
+func (k *Keyring) add(vk *verificationKey) {
+	if _, ok := k.keys[vk.kid]; ok {
+		return
+	}
+	k.keys[vk.kid] = vk
+	k.order = append(k.order, vk.kid)
+}
+
+func (k *Keyring) MakeJWT(userID uuid.UUID, role string, expiresIn time.Duration) (string, error) {
+	return k.MakeJWTAt(userID, role, time.Now(), expiresIn)
+}
+
+// MakeJWTAt is MakeJWT with an explicit issue time, which may lie slightly in the future.
+func (k *Keyring) MakeJWTAt(userID uuid.UUID, role string, issuedAt time.Time, expiresIn time.Duration) (string, error) {
+	token := jwt.NewWithClaims(k.current.method, newClaims(userID, role, issuedAt, expiresIn))
+	token.Header["kid"] = k.current.kid
+	return token.SignedString(k.signer)
+}
+
+func (k *Keyring) ValidateJWT(tokenString string) (uuid.UUID, error) {
+	claims, err := k.ParseJWT(tokenString)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return claims.UserID()
+}
+
+// ParseJWT verifies the token and returns its claims, for callers that also
+// need the token ID or issue time. MFA pending tokens are rejected.
+func (k *Keyring) ParseJWT(tokenString string) (*Claims, error) {
+	claims, err := parseClaims(tokenString, k.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(claims.Audience, MFAAudience) {
+		return nil, jwt.ErrTokenInvalidAudience
+	}
+	return claims, nil
+}
+
+// MakeMFAToken issues the short-lived token that proves the password step of a
+// two-factor login. It cannot be used as an access token.
+func (k *Keyring) MakeMFAToken(userID uuid.UUID, expiresIn time.Duration) (string, error) {
+	claims := newClaims(userID, "", time.Now(), expiresIn)
+	claims.Audience = jwt.ClaimStrings{MFAAudience}
+
+	token := jwt.NewWithClaims(k.current.method, claims)
+	token.Header["kid"] = k.current.kid
+	return token.SignedString(k.signer)
+}
+
+// ParseMFAToken verifies a token made by MakeMFAToken. Such tokens always carry
+// a jti and an expiry, which callers use to accept each token only once.
+func (k *Keyring) ParseMFAToken(tokenString string) (*Claims, error) {
+	claims, err := parseClaims(tokenString, k.keyFunc, jwt.WithAudience(MFAAudience), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+	if claims.ID == "" {
+		return nil, jwt.ErrTokenInvalidId
+	}
+	return claims, nil
+}
+
+// keyFunc picks the verification key and refuses algorithms that do not match it,
+// so an attacker cannot downgrade a token to HS256 signed with a public key.
+func (k *Keyring) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if k.legacySecret == nil || token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("token has no kid")
+		}
+		if !time.Now().Before(k.legacyUntil) {
+			return nil, fmt.Errorf("legacy HS256 tokens are no longer accepted")
+		}
+		return k.legacySecret, nil
+	}
+
+	vk, ok := k.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+	if token.Method.Alg() != vk.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for kid %q", token.Method.Alg(), kid)
+	}
+	return vk.public, nil
+}
+
+// JWKS returns the public keys that tokens are verified with, current key first.
+func (k *Keyring) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, kid := range k.order {
+		set.Keys = append(set.Keys, k.keys[kid].jwk())
+	}
+	return set
+}
+
+func newVerificationKey(pub crypto.PublicKey) (*verificationKey, error) {
+	vk := &verificationKey{public: pub}
+	switch pub.(type) {
+	case ed25519.PublicKey:
+		vk.method = jwt.SigningMethodEdDSA
+	case *rsa.PublicKey:
+		vk.method = jwt.SigningMethodRS256
+	default:
+		return nil, fmt.Errorf("unsupported key type %T; use Ed25519 or RSA", pub)
+	}
+	vk.kid = vk.thumbprint()
+	return vk, nil
+}
+
+func (vk *verificationKey) jwk() JWK {
+	enc := base64.RawURLEncoding
+	switch pub := vk.public.(type) {
+	case ed25519.PublicKey:
+		return JWK{Kty: "OKP", Use: "sig", Alg: vk.method.Alg(), Kid: vk.kid, Crv: "Ed25519", X: enc.EncodeToString(pub)}
+	case *rsa.PublicKey:
+		return JWK{Kty: "RSA", Use: "sig", Alg: vk.method.Alg(), Kid: vk.kid, N: enc.EncodeToString(pub.N.Bytes()), E: enc.EncodeToString(big.NewInt(int64(pub.E)).Bytes())}
+	}
+	return JWK{}
+}
+
+// thumbprint is the RFC 7638 JWK thumbprint, used as a stable kid.
+func (vk *verificationKey) thumbprint() string {
+	jwk := vk.jwk()
+	var members any
+	switch jwk.Kty {
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	}
+	b, _ := json.Marshal(members)
+	sum := sha256.Sum256(b)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func readPEMKey(path string) (any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM block found", path)
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	return nil, fmt.Errorf("%s: unsupported PEM block %q", path, block.Type)
+}
@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func newEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey returned error: %v", err)
+	}
+	return priv
+}
+
+func TestKeyring_MakeValidate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey returned error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		signer crypto.Signer
+		alg    string
+	}{
+		{"ed25519", newEd25519Key(t), "EdDSA"},
+		{"rsa", rsaKey, "RS256"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := NewKeyring(tt.signer)
+			if err != nil {
+				t.Fatalf("NewKeyring returned error: %v", err)
+			}
+
+			userID := uuid.New()
+			tokenString, err := keys.MakeJWT(userID, RoleUser, time.Hour)
+			if err != nil {
+				t.Fatalf("MakeJWT returned error: %v", err)
+			}
+
+			token, _, err := jwt.NewParser().ParseUnverified(tokenString, &jwt.RegisteredClaims{})
+			if err != nil {
+				t.Fatalf("ParseUnverified returned error: %v", err)
+			}
+			if token.Method.Alg() != tt.alg {
+				t.Errorf("token alg %s, want %s", token.Method.Alg(), tt.alg)
+			}
+			if token.Header["kid"] != keys.JWKS().Keys[0].Kid {
+				t.Errorf("token kid %v, want %s", token.Header["kid"], keys.JWKS().Keys[0].Kid)
+			}
+
+			got, err := keys.ValidateJWT(tokenString)
+			if err != nil {
+				t.Fatalf("ValidateJWT returned error: %v", err)
+			}
+			if got != userID {
+				t.Errorf("ValidateJWT returned userID %v, want %v", got, userID)
+			}
+
+			expired, _ := keys.MakeJWT(userID, RoleUser, -time.Hour)
+			if _, err := keys.ValidateJWT(expired); err != jwt.ErrTokenExpired {
+				t.Errorf("ValidateJWT for expired token returned %v, want jwt.ErrTokenExpired", err)
+			}
+		})
+	}
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	oldKey := newEd25519Key(t)
+	newKey := newEd25519Key(t)
+
+	before, _ := NewKeyring(oldKey)
+	oldToken, _ := before.MakeJWT(uuid.New(), RoleUser, time.Hour)
+
+	during, err := NewKeyring(newKey, oldKey.Public())
+	if err != nil {
+		t.Fatalf("NewKeyring returned error: %v", err)
+	}
+	if _, err := during.ValidateJWT(oldToken); err != nil {
+		t.Errorf("token from the previous key rejected during rotation: %v", err)
+	}
+	if n := len(during.JWKS().Keys); n != 2 {
+		t.Errorf("JWKS during rotation has %d keys, want 2", n)
+	}
+
+	after, _ := NewKeyring(newKey)
+	if _, err := after.ValidateJWT(oldToken); err == nil {
+		t.Errorf("token from a retired key accepted")
+	}
+
+	newToken, _ := during.MakeJWT(uuid.New(), RoleUser, time.Hour)
+	if _, err := after.ValidateJWT(newToken); err != nil {
+		t.Errorf("token from the current key rejected: %v", err)
+	}
+}
+
+func TestKeyring_LegacyAndAlgorithmConfusion(t *testing.T) {
+	priv := newEd25519Key(t)
+	keys, _ := NewKeyring(priv)
+
+	legacy, _ := MakeJWT(uuid.New(), "legacysecret", time.Hour)
+	if _, err := keys.ValidateJWT(legacy); err == nil {
+		t.Errorf("HS256 token accepted without a legacy secret")
+	}
+	if _, err := keys.WithLegacySecret("legacysecret", time.Now().Add(-time.Minute)).ValidateJWT(legacy); err == nil {
+		t.Errorf("HS256 token accepted after the legacy deadline")
+	}
+	if _, err := keys.WithLegacySecret("legacysecret", time.Now().Add(time.Hour)).ValidateJWT(legacy); err != nil {
+		t.Errorf("HS256 token rejected with the legacy secret: %v", err)
+	}
+
+	// HS256 signed with the public key bytes under the Ed25519 kid must be rejected.
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &jwt.RegisteredClaims{
+		Subject:   uuid.New().String(),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+	forged.Header["kid"] = keys.JWKS().Keys[0].Kid
+	forgedString, _ := forged.SignedString([]byte(priv.Public().(ed25519.PublicKey)))
+	if _, err := keys.ValidateJWT(forgedString); err == nil {
+		t.Errorf("HS256 token with an asymmetric kid accepted")
+	}
+}
+
+func TestKeyring_MFAToken(t *testing.T) {
+	keys, err := NewKeyring(newEd25519Key(t))
+	if err != nil {
+		t.Fatalf("NewKeyring returned error: %v", err)
+	}
+	userID := uuid.New()
+
+	mfaToken, err := keys.MakeMFAToken(userID, time.Minute)
+	if err != nil {
+		t.Fatalf("MakeMFAToken returned error: %v", err)
+	}
+	claims, err := keys.ParseMFAToken(mfaToken)
+	if err != nil {
+		t.Fatalf("ParseMFAToken returned error: %v", err)
+	}
+	if got, _ := claims.UserID(); got != userID {
+		t.Errorf("ParseMFAToken returned user %v, want %v", got, userID)
+	}
+
+	// The pending token must not work as an access token, and vice versa.
+	if _, err := keys.ParseJWT(mfaToken); err == nil {
+		t.Errorf("ParseJWT accepted an MFA pending token")
+	}
+	accessToken, _ := keys.MakeJWT(userID, RoleUser, time.Minute)
+	if _, err := keys.ParseMFAToken(accessToken); err == nil {
+		t.Errorf("ParseMFAToken accepted an access token")
+	}
+}
+
+func TestKeyring_Thumbprint(t *testing.T) {
+	// RFC 8037, appendix A.3.
+	x, _ := base64.RawURLEncoding.DecodeString("11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo")
+	vk, err := newVerificationKey(ed25519.PublicKey(x))
+	if err != nil {
+		t.Fatalf("newVerificationKey returned error: %v", err)
+	}
+	if vk.kid != "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k" {
+		t.Errorf("thumbprint %s, want kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k", vk.kid)
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	dir := t.TempDir()
+
+	writePEM := func(name, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return path
+	}
+
+	current := newEd25519Key(t)
+	der, _ := x509.MarshalPKCS8PrivateKey(current)
+	currentPath := writePEM("current.pem", "PRIVATE KEY", der)
+
+	previous, _ := rsa.GenerateKey(rand.Reader, 2048)
+	der, _ = x509.MarshalPKIXPublicKey(&previous.PublicKey)
+	previousPath := writePEM("previous.pub.pem", "PUBLIC KEY", der)
+
+	keys, err := LoadKeyring(currentPath, []string{previousPath})
+	if err != nil {
+		t.Fatalf("LoadKeyring returned error: %v", err)
+	}
+
+	set := keys.JWKS()
+	if len(set.Keys) != 2 || set.Keys[0].Kty != "OKP" || set.Keys[1].Kty != "RSA" {
+		t.Fatalf("JWKS = %+v, want current OKP key then previous RSA key", set)
+	}
+
+	if _, err := LoadKeyring(previousPath, nil); err == nil {
+		t.Errorf("LoadKeyring accepted a public key as signing key")
+	}
+}
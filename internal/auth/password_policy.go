@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nbutton23/zxcvbn-go"
+)
+
+// Strength is only estimated on this many characters; zxcvbn gets slow on long
+// inputs and anything longer is strong enough anyway.
+const maxScoredLength = 100
+
+// PasswordPolicy decides whether a new password is acceptable.
+type PasswordPolicy struct {
+	MinLength int // in characters
+	MaxLength int // in characters; 0 means no limit
+	MinScore  int // zxcvbn score 0 (guessable) to 4 (very unguessable)
+	Breached  BreachedPasswords
+}
+
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength: 8,
+	MaxLength: 256,
+	MinScore:  2,
+}
+
+// PasswordViolation is one reason a password was rejected, for API clients.
+type PasswordViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Check returns every rule the password breaks; an empty result means it is acceptable.
+// userInputs (email, handle...) make passwords derived from them score lower.
+func (p PasswordPolicy) Check(ctx context.Context, password string, userInputs ...string) ([]PasswordViolation, error) {
+	var violations []PasswordViolation
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		violations = append(violations, PasswordViolation{
+			Code:    "too_short",
+			Message: fmt.Sprintf("password must be at least %d characters", p.MinLength),
+		})
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, PasswordViolation{
+			Code:    "too_long",
+			Message: fmt.Sprintf("password must be at most %d characters", p.MaxLength),
+		})
+	}
+
+	if p.MinScore > 0 && password != "" {
+		scored := password
+		if length > maxScoredLength {
+			scored = string([]rune(password)[:maxScoredLength])
+		}
+		var inputs []string
+		for _, in := range userInputs {
+			inputs = append(inputs, strings.FieldsFunc(strings.ToLower(in), func(r rune) bool {
+				return r == '@' || r == '.' || r == '+' || r == '_' || r == '-'
+			})...)
+		}
+		if score := zxcvbn.PasswordStrength(scored, inputs).Score; score < p.MinScore {
+			violations = append(violations, PasswordViolation{
+				Code:    "too_weak",
+				Message: fmt.Sprintf("password is too easy to guess (strength %d of 4, need %d)", score, p.MinScore),
+			})
+		}
+	}
+
+	if p.Breached != nil && password != "" {
+		count, err := IsBreached(ctx, p.Breached, password)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			violations = append(violations, PasswordViolation{
+				Code:    "breached",
+				Message: "password appears in a known data breach",
+			})
+		}
+	}
+
+	return violations, nil
+}
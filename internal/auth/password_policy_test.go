@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newBreachedDir writes a corpus holding the given passwords in range-file layout.
+func newBreachedDir(t *testing.T, passwords ...string) *BreachedDir {
+	t.Helper()
+	dir := t.TempDir()
+	for _, pw := range passwords {
+		sum := sha1.Sum([]byte(pw))
+		hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+		f, err := os.OpenFile(filepath.Join(dir, hash[:5]+".txt"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			t.Fatalf("write corpus: %v", err)
+		}
+		f.WriteString(hash[5:] + ":42\r\n")
+		f.Close()
+	}
+
+	b, err := NewBreachedDir(dir)
+	if err != nil {
+		t.Fatalf("NewBreachedDir returned error: %v", err)
+	}
+	return b
+}
+
+func TestIsBreached(t *testing.T) {
+	b := newBreachedDir(t, "password1", "hunter2")
+	ctx := context.Background()
+
+	tests := []struct {
+		password string
+		want     int
+	}{
+		{"password1", 42},
+		{"hunter2", 42},
+		{"correct horse battery staple", 0},
+	}
+	for _, tt := range tests {
+		got, err := IsBreached(ctx, b, tt.password)
+		if err != nil {
+			t.Fatalf("IsBreached returned error: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("IsBreached(%q) = %d, want %d", tt.password, got, tt.want)
+		}
+	}
+
+	if _, err := b.Range(ctx, "../x"); err == nil {
+		t.Errorf("Range accepted a non-hex prefix")
+	}
+	if _, err := NewBreachedDir(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Errorf("NewBreachedDir accepted a missing directory")
+	}
+}
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	policy := DefaultPasswordPolicy
+	policy.Breached = newBreachedDir(t, "Tr0ub4dor&3xyz")
+
+	tests := []struct {
+		name     string
+		password string
+		want     []string
+	}{
+		{"strong", "correct horse battery staple", nil},
+		{"empty", "", []string{"too_short"}},
+		{"short", "a1!", []string{"too_short", "too_weak"}},
+		{"common", "password", []string{"too_weak"}},
+		{"derived from email", "saulgoodman", []string{"too_weak"}},
+		{"breached", "Tr0ub4dor&3xyz", []string{"breached"}},
+		{"too long", strings.Repeat("x7!Q", 65), []string{"too_long"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations, err := policy.Check(context.Background(), tt.password, "saul.goodman@bettercall.com")
+			if err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+			var got []string
+			for _, v := range violations {
+				got = append(got, v.Code)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Check(%q) = %v, want %v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
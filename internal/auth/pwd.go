@@ -1,11 +1,65 @@
 package auth
 
 import (
+	"fmt"
+
 	"github.com/alexedwards/argon2id"
 )
 
+// PasswordParams are the Argon2id cost parameters for new password hashes.
+// Existing hashes keep the parameters they were created with (they are encoded
+// in the hash) until NeedsRehash reports them as weaker and they are replaced.
+type PasswordParams struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+}
+
+var DefaultPasswordParams = PasswordParams{
+	Memory:      argon2id.DefaultParams.Memory,
+	Iterations:  argon2id.DefaultParams.Iterations,
+	Parallelism: argon2id.DefaultParams.Parallelism,
+}
+
+func (p PasswordParams) Validate() error {
+	if p.Iterations < 1 || p.Parallelism < 1 {
+		return fmt.Errorf("argon2 iterations and parallelism must be at least 1")
+	}
+	if p.Memory < 8*uint32(p.Parallelism) {
+		return fmt.Errorf("argon2 memory must be at least 8 KiB per lane, got %d KiB for %d lanes", p.Memory, p.Parallelism)
+	}
+	return nil
+}
+
+func (p PasswordParams) argon2id() *argon2id.Params {
+	return &argon2id.Params{
+		Memory:      p.Memory,
+		Iterations:  p.Iterations,
+		Parallelism: p.Parallelism,
+		SaltLength:  argon2id.DefaultParams.SaltLength,
+		KeyLength:   argon2id.DefaultParams.KeyLength,
+	}
+}
+
+func (p PasswordParams) Hash(password string) (string, error) {
+	return argon2id.CreateHash(password, p.argon2id())
+}
+
+// NeedsRehash reports whether hash was made with any parameter weaker than p.
+// Stronger hashes are left alone, so lowering the configuration does not churn.
+func (p PasswordParams) NeedsRehash(hash string) (bool, error) {
+	params, _, _, err := argon2id.DecodeHash(hash)
+	if err != nil {
+		return false, err
+	}
+	return params.Memory < p.Memory ||
+		params.Iterations < p.Iterations ||
+		params.Parallelism < p.Parallelism ||
+		params.KeyLength < argon2id.DefaultParams.KeyLength, nil
+}
+
 func HashPassword(password string) (string, error) {
-	hash, err := argon2id.CreateHash(password, argon2id.DefaultParams)
+	hash, err := DefaultPasswordParams.Hash(password)
 	if err != nil {
 		return "", err
 	}
@@ -116,3 +116,72 @@ func TestCheckPasswordHash_TableDriven(t *testing.T) {
 		})
 	}
 }
+
+func TestPasswordParams_NeedsRehash(t *testing.T) {
+	weak := PasswordParams{Memory: 8 * 1024, Iterations: 1, Parallelism: 1}
+	strong := PasswordParams{Memory: 16 * 1024, Iterations: 3, Parallelism: 2}
+
+	weakHash, err := weak.Hash("secret123")
+	if err != nil {
+		t.Fatalf("Hash returned error: %v", err)
+	}
+	strongHash, err := strong.Hash("secret123")
+	if err != nil {
+		t.Fatalf("Hash returned error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		params PasswordParams
+		hash   string
+		want   bool
+	}{
+		{"same params", weak, weakHash, false},
+		{"raised memory", PasswordParams{Memory: 16 * 1024, Iterations: 1, Parallelism: 1}, weakHash, true},
+		{"raised iterations", PasswordParams{Memory: 8 * 1024, Iterations: 2, Parallelism: 1}, weakHash, true},
+		{"raised parallelism", PasswordParams{Memory: 8 * 1024, Iterations: 1, Parallelism: 2}, weakHash, true},
+		{"lowered params", weak, strongHash, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.params.NeedsRehash(tt.hash)
+			if err != nil {
+				t.Fatalf("NeedsRehash returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("NeedsRehash = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := weak.NeedsRehash("not_a_real_hash"); err == nil {
+		t.Errorf("NeedsRehash accepted a malformed hash")
+	}
+
+	// Hashes made with any parameters still verify.
+	if ok, _ := CheckPasswordHash("secret123", strongHash); !ok {
+		t.Errorf("CheckPasswordHash rejected a hash made with custom params")
+	}
+}
+
+func TestPasswordParams_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  PasswordParams
+		wantErr bool
+	}{
+		{"default", DefaultPasswordParams, false},
+		{"zero iterations", PasswordParams{Memory: 1024, Iterations: 0, Parallelism: 1}, true},
+		{"zero parallelism", PasswordParams{Memory: 1024, Iterations: 1, Parallelism: 0}, true},
+		{"too little memory", PasswordParams{Memory: 8, Iterations: 1, Parallelism: 2}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.params.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"testing"
+)
+
+func TestHashRefreshToken(t *testing.T) {
+	token, err := MakeRefreshToken()
+	if err != nil {
+		t.Fatalf("MakeRefreshToken returned error: %v", err)
+	}
+
+	hash := HashRefreshToken(token)
+	if hash == token {
+		t.Fatalf("HashRefreshToken returned the token unchanged")
+	}
+	if len(hash) != 64 {
+		t.Errorf("HashRefreshToken returned %d hex chars, want 64", len(hash))
+	}
+	if HashRefreshToken(token) != hash {
+		t.Errorf("HashRefreshToken is not deterministic")
+	}
+
+	other, _ := MakeRefreshToken()
+	if HashRefreshToken(other) == hash {
+		t.Errorf("HashRefreshToken returned the same digest for different tokens")
+	}
+
+	// Known SHA-256 test vector.
+	if got := HashRefreshToken("abc"); got != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Errorf("HashRefreshToken(\"abc\") = %s", got)
+	}
+}
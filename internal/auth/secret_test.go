@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+func TestEncryptSecret(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+
+	ciphertext, err := EncryptSecret(key, "JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatalf("EncryptSecret returned error: %v", err)
+	}
+	if again, _ := EncryptSecret(key, "JBSWY3DPEHPK3PXP"); again == ciphertext {
+		t.Errorf("EncryptSecret reused a nonce")
+	}
+
+	plaintext, err := DecryptSecret(key, ciphertext)
+	if err != nil {
+		t.Fatalf("DecryptSecret returned error: %v", err)
+	}
+	if plaintext != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("DecryptSecret = %q", plaintext)
+	}
+
+	if _, err := DecryptSecret(bytes.Repeat([]byte{8}, 32), ciphertext); err == nil {
+		t.Errorf("DecryptSecret succeeded with the wrong key")
+	}
+
+	raw, _ := base64.StdEncoding.DecodeString(ciphertext)
+	raw[len(raw)-1] ^= 1
+	if _, err := DecryptSecret(key, base64.StdEncoding.EncodeToString(raw)); err == nil {
+		t.Errorf("DecryptSecret accepted a tampered ciphertext")
+	}
+
+	if _, err := EncryptSecret([]byte("short"), "x"); err == nil {
+		t.Errorf("EncryptSecret accepted a short key")
+	}
+}
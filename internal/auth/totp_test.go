@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTPCode_RFC6238(t *testing.T) {
+	// RFC 6238 appendix B SHA1 vectors, truncated to 6 digits.
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := TOTPCode(secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("TOTPCode returned error: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret returned error: %v", err)
+	}
+	now := time.Now()
+	code, _ := TOTPCode(secret, now)
+
+	tests := []struct {
+		name string
+		code string
+		at   time.Time
+		want bool
+	}{
+		{"current step", code, now, true},
+		{"previous step", code, now.Add(30 * time.Second), true},
+		{"next step", code, now.Add(-30 * time.Second), true},
+		{"too old", code, now.Add(2 * time.Minute), false},
+		{"wrong length", code[:5], now, false},
+		{"empty", "", now, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateTOTP(secret, tt.code, tt.at)
+			if err != nil {
+				t.Fatalf("ValidateTOTP returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ValidateTOTP = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ValidateTOTP("not base32!", code, now); err == nil {
+		t.Errorf("ValidateTOTP accepted an invalid secret")
+	}
+}
+
+func TestMatchTOTP_Step(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret returned error: %v", err)
+	}
+	at := time.Unix(59, 0)
+	code, _ := TOTPCode(secret, at)
+
+	for _, later := range []time.Time{at, at.Add(30 * time.Second)} {
+		step, ok, err := MatchTOTP(secret, code, later)
+		if err != nil || !ok || step != 1 {
+			t.Errorf("MatchTOTP at %d = %d, %v, %v; want step 1", later.Unix(), step, ok, err)
+		}
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("Chirpy", "saul@bettercall.com", "JBSWY3DPEHPK3PXP")
+	if !strings.HasPrefix(uri, "otpauth://totp/Chirpy:saul@bettercall.com?") {
+		t.Errorf("unexpected URI prefix: %s", uri)
+	}
+	for _, part := range []string{"secret=JBSWY3DPEHPK3PXP", "issuer=Chirpy", "digits=6", "period=30"} {
+		if !strings.Contains(uri, part) {
+			t.Errorf("URI %s does not contain %s", uri, part)
+		}
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes returned error: %v", err)
+	}
+	if len(codes) != 10 {
+		t.Fatalf("GenerateRecoveryCodes returned %d codes, want 10", len(codes))
+	}
+
+	seen := map[string]bool{}
+	for _, c := range codes {
+		if len(c) != 11 || c[5] != '-' {
+			t.Errorf("recovery code %q is not formatted as xxxxx-xxxxx", c)
+		}
+		if seen[c] {
+			t.Errorf("duplicate recovery code %q", c)
+		}
+		seen[c] = true
+	}
+
+	if HashRecoveryCode(codes[0]) != HashRecoveryCode(" "+strings.ToUpper(strings.ReplaceAll(codes[0], "-", ""))+" ") {
+		t.Errorf("HashRecoveryCode is sensitive to case, dashes or spaces")
+	}
+}
@@ -0,0 +1,29 @@
+// Package blobstore keeps user uploads (avatars, header images) outside the database.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// BlobStore saves immutable objects under slash-separated keys and tells where they are served from.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	// Delete succeeds when the key does not exist.
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+var keyRe = regexp.MustCompile(`^[A-Za-z0-9._/-]+$`)
+
+// validKey rejects keys that could escape the store root once joined to a path.
+func validKey(key string) bool {
+	return keyRe.MatchString(key) && path.Clean(key) == key &&
+		!path.IsAbs(key) && key != "." && key != ".." && !strings.HasPrefix(key, "../")
+}
@@ -0,0 +1,70 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"avatars/abc/123_400.jpg", true},
+		{"a", true},
+		{"", false},
+		{".", false},
+		{"..", false},
+		{"../etc/passwd", false},
+		{"a/../../b", false},
+		{"/abs/path", false},
+		{"a//b", false},
+		{"a/b/", false},
+		{`a\b`, false},
+		{"a b", false},
+	}
+	for _, tt := range tests {
+		if got := validKey(tt.key); got != tt.want {
+			t.Errorf("validKey(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s, err := NewLocalStore(filepath.Join(dir, "uploads"), "http://chirpy.test/app/uploads/")
+	if err != nil {
+		t.Fatalf("NewLocalStore returned error: %v", err)
+	}
+
+	key := "avatars/u1/x_48.jpg"
+	if err := s.Put(ctx, key, strings.NewReader("jpeg"), "image/jpeg"); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "uploads", "avatars", "u1", "x_48.jpg"))
+	if err != nil || string(data) != "jpeg" {
+		t.Fatalf("stored file = %q, %v", data, err)
+	}
+	if got := s.URL(key); got != "http://chirpy.test/app/uploads/avatars/u1/x_48.jpg" {
+		t.Errorf("URL = %q", got)
+	}
+
+	if err := s.Put(ctx, "../escape", strings.NewReader("x"), "text/plain"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Put outside the root returned %v, want ErrInvalidKey", err)
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "uploads", "avatars", "u1", "x_48.jpg")); !os.IsNotExist(err) {
+		t.Errorf("file still exists after Delete: %v", err)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of a missing key returned %v, want nil", err)
+	}
+}
@@ -0,0 +1,52 @@
+package blobstore
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// MemoryStore keeps blobs in memory; for tests and DB_URL=memory:// runs.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	baseURL string
+	blobs   map[string][]byte
+}
+
+func NewMemoryStore(baseURL string) *MemoryStore {
+	return &MemoryStore{baseURL: baseURL, blobs: make(map[string][]byte)}
+}
+
+func (s *MemoryStore) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[key] = data
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.blobs, key)
+	return nil
+}
+
+func (s *MemoryStore) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// Get returns a stored blob and whether it exists.
+func (s *MemoryStore) Get(key string) ([]byte, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.blobs[key]
+	return data, ok
+}
@@ -0,0 +1,122 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: account_deletion.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const cancelUserDeletion = `-- name: CancelUserDeletion :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    delete_after = NULL
+WHERE id = $1
+`
+
+func (q *Queries) CancelUserDeletion(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, cancelUserDeletion, id)
+	return err
+}
+
+const listRefreshTokensByUser = `-- name: ListRefreshTokensByUser :many
+SELECT token_hash, created_at, updated_at, user_id, expires_at, revoked_at, family_id, replaced_by, user_agent, ip_address, session_started_at FROM refresh_tokens
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListRefreshTokensByUser(ctx context.Context, userID uuid.UUID) ([]RefreshToken, error) {
+	rows, err := q.db.QueryContext(ctx, listRefreshTokensByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RefreshToken
+	for rows.Next() {
+		var i RefreshToken
+		if err := rows.Scan(
+			&i.TokenHash,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.ExpiresAt,
+			&i.RevokedAt,
+			&i.FamilyID,
+			&i.ReplacedBy,
+			&i.UserAgent,
+			&i.IpAddress,
+			&i.SessionStartedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeDeletedUsers = `-- name: PurgeDeletedUsers :many
+DELETE FROM users
+WHERE delete_after <= $1::timestamp
+RETURNING id, avatar_key, header_key
+`
+
+type PurgeDeletedUsersRow struct {
+	ID        uuid.UUID
+	AvatarKey string
+	HeaderKey string
+}
+
+// Everything the user owns goes with the row (ON DELETE CASCADE); uploads are returned for cleanup.
+func (q *Queries) PurgeDeletedUsers(ctx context.Context, now time.Time) ([]PurgeDeletedUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, purgeDeletedUsers, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PurgeDeletedUsersRow
+	for rows.Next() {
+		var i PurgeDeletedUsersRow
+		if err := rows.Scan(&i.ID, &i.AvatarKey, &i.HeaderKey); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const scheduleUserDeletion = `-- name: ScheduleUserDeletion :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    delete_after = $1
+WHERE id = $2
+`
+
+type ScheduleUserDeletionParams struct {
+	DeleteAfter sql.NullTime
+	ID          uuid.UUID
+}
+
+func (q *Queries) ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) error {
+	_, err := q.db.ExecContext(ctx, scheduleUserDeletion, arg.DeleteAfter, arg.ID)
+	return err
+}
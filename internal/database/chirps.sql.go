@@ -12,24 +12,30 @@ import (
 )
 
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
-VALUES (
-  gen_random_uuid(),
-  NOW(),
-  NOW(),
-  $1,
-  $2
-)
-RETURNING id, created_at, updated_at, body, user_id
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, in_reply_to_id, conversation_id, quoted_id)
+SELECT generated.id, NOW(), NOW(), $1::text, $2::uuid, $3::uuid,
+  COALESCE($4::uuid, generated.id), $5::uuid
+FROM (SELECT gen_random_uuid() AS id) AS generated
+RETURNING id, created_at, updated_at, body, user_id, fanned_out_at, in_reply_to_id, conversation_id, deleted_at, rechirp_of_id, quoted_id
 `
 
 type CreateChirpParams struct {
-	Body   string
-	UserID uuid.UUID
+	Body           string
+	UserID         uuid.UUID
+	InReplyToID    uuid.NullUUID
+	ConversationID uuid.NullUUID
+	QuotedID       uuid.NullUUID
 }
 
+// A chirp without a conversation_id starts its own, named by its ID.
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createChirp,
+		arg.Body,
+		arg.UserID,
+		arg.InReplyToID,
+		arg.ConversationID,
+		arg.QuotedID,
+	)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -37,6 +43,12 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.FannedOutAt,
+		&i.InReplyToID,
+		&i.ConversationID,
+		&i.DeletedAt,
+		&i.RechirpOfID,
+		&i.QuotedID,
 	)
 	return i, err
 }
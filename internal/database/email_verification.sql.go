@@ -0,0 +1,80 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: email_verification.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const consumeEmailVerificationToken = `-- name: ConsumeEmailVerificationToken :one
+UPDATE email_verification_tokens
+SET used_at = $1::timestamp
+WHERE token_hash = $2 AND used_at IS NULL AND expires_at > $1::timestamp
+RETURNING user_id, email
+`
+
+type ConsumeEmailVerificationTokenParams struct {
+	Now       time.Time
+	TokenHash string
+}
+
+type ConsumeEmailVerificationTokenRow struct {
+	UserID uuid.UUID
+	Email  string
+}
+
+func (q *Queries) ConsumeEmailVerificationToken(ctx context.Context, arg ConsumeEmailVerificationTokenParams) (ConsumeEmailVerificationTokenRow, error) {
+	row := q.db.QueryRowContext(ctx, consumeEmailVerificationToken, arg.Now, arg.TokenHash)
+	var i ConsumeEmailVerificationTokenRow
+	err := row.Scan(&i.UserID, &i.Email)
+	return i, err
+}
+
+const createEmailVerificationToken = `-- name: CreateEmailVerificationToken :exec
+INSERT INTO email_verification_tokens (token_hash, created_at, user_id, email, expires_at, used_at)
+VALUES ($1, NOW(), $2, $3, $4, NULL)
+`
+
+type CreateEmailVerificationTokenParams struct {
+	TokenHash string
+	UserID    uuid.UUID
+	Email     string
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreateEmailVerificationToken(ctx context.Context, arg CreateEmailVerificationTokenParams) error {
+	_, err := q.db.ExecContext(ctx, createEmailVerificationToken,
+		arg.TokenHash,
+		arg.UserID,
+		arg.Email,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const markEmailVerified = `-- name: MarkEmailVerified :execrows
+UPDATE users
+SET
+    updated_at = NOW(),
+    email_verified_at = NOW()
+WHERE id = $1 AND email = $2
+`
+
+type MarkEmailVerifiedParams struct {
+	ID    uuid.UUID
+	Email string
+}
+
+func (q *Queries) MarkEmailVerified(ctx context.Context, arg MarkEmailVerifiedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markEmailVerified, arg.ID, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
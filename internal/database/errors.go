@@ -0,0 +1,24 @@
+package database
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// IsUniqueViolation reports whether err comes from a unique constraint, for
+// any backend: Postgres (SQLSTATE 23505), SQLite, or the MemoryStore which
+// mimics the Postgres message.
+func IsUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "duplicate key value violates unique constraint") ||
+		strings.Contains(msg, "UNIQUE constraint failed")
+}
@@ -12,7 +12,7 @@ import (
 )
 
 const getChirp = `-- name: GetChirp :one
-SELECT id, created_at, updated_at, body, user_id FROM chirps
+SELECT id, created_at, updated_at, body, user_id, fanned_out_at, in_reply_to_id, conversation_id, deleted_at, rechirp_of_id, quoted_id FROM chirps
 WHERE id = $1
 `
 
@@ -25,6 +25,12 @@ func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.FannedOutAt,
+		&i.InReplyToID,
+		&i.ConversationID,
+		&i.DeletedAt,
+		&i.RechirpOfID,
+		&i.QuotedID,
 	)
 	return i, err
 }
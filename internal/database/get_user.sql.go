@@ -12,7 +12,7 @@ import (
 )
 
 const getUserFromEmail = `-- name: GetUserFromEmail :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red FROM users
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, tokens_valid_after, role, totp_secret, totp_enabled_at, email_verified_at, handle, display_name, bio, avatar_url, avatar_key, header_url, header_key, delete_after, totp_last_step FROM users
 WHERE email = $1
 `
 
@@ -26,12 +26,26 @@ func (q *Queries) GetUserFromEmail(ctx context.Context, email string) (User, err
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.TokensValidAfter,
+		&i.Role,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.EmailVerifiedAt,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.AvatarKey,
+		&i.HeaderUrl,
+		&i.HeaderKey,
+		&i.DeleteAfter,
+		&i.TotpLastStep,
 	)
 	return i, err
 }
 
 const getUserFromUserID = `-- name: GetUserFromUserID :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red FROM users
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, tokens_valid_after, role, totp_secret, totp_enabled_at, email_verified_at, handle, display_name, bio, avatar_url, avatar_key, header_url, header_key, delete_after, totp_last_step FROM users
 WHERE id = $1
 `
 
@@ -45,6 +59,20 @@ func (q *Queries) GetUserFromUserID(ctx context.Context, id uuid.UUID) (User, er
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.TokensValidAfter,
+		&i.Role,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.EmailVerifiedAt,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.AvatarKey,
+		&i.HeaderUrl,
+		&i.HeaderKey,
+		&i.DeleteAfter,
+		&i.TotpLastStep,
 	)
 	return i, err
 }
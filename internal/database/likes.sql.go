@@ -0,0 +1,221 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: likes.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const countLikesByIDs = `-- name: CountLikesByIDs :many
+SELECT chirp_id, COUNT(*) AS like_count
+FROM likes
+WHERE chirp_id = ANY($1::uuid[])
+GROUP BY chirp_id
+`
+
+type CountLikesByIDsRow struct {
+	ChirpID   uuid.UUID
+	LikeCount int64
+}
+
+func (q *Queries) CountLikesByIDs(ctx context.Context, ids []uuid.UUID) ([]CountLikesByIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, countLikesByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountLikesByIDsRow
+	for rows.Next() {
+		var i CountLikesByIDsRow
+		if err := rows.Scan(&i.ChirpID, &i.LikeCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const likeChirp = `-- name: LikeChirp :execrows
+INSERT INTO likes (user_id, chirp_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT DO NOTHING
+`
+
+type LikeChirpParams struct {
+	UserID  uuid.UUID
+	ChirpID uuid.UUID
+}
+
+func (q *Queries) LikeChirp(ctx context.Context, arg LikeChirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, likeChirp, arg.UserID, arg.ChirpID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listLikedChirpIDs = `-- name: ListLikedChirpIDs :many
+SELECT chirp_id FROM likes
+WHERE user_id = $1
+  AND chirp_id = ANY($2::uuid[])
+`
+
+type ListLikedChirpIDsParams struct {
+	UserID uuid.UUID
+	Ids    []uuid.UUID
+}
+
+// Which of the chirps the user has liked.
+func (q *Queries) ListLikedChirpIDs(ctx context.Context, arg ListLikedChirpIDsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, listLikedChirpIDs, arg.UserID, pq.Array(arg.Ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var chirp_id uuid.UUID
+		if err := rows.Scan(&chirp_id); err != nil {
+			return nil, err
+		}
+		items = append(items, chirp_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLikedChirps = `-- name: ListLikedChirps :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.fanned_out_at, chirps.in_reply_to_id, chirps.conversation_id, chirps.deleted_at, chirps.rechirp_of_id, chirps.quoted_id, likes.created_at AS liked_at
+FROM likes
+JOIN chirps ON chirps.id = likes.chirp_id
+WHERE likes.user_id = $1
+  AND chirps.deleted_at IS NULL
+  AND chirps.user_id NOT IN (SELECT users.id FROM users WHERE users.delete_after IS NOT NULL)
+  AND (
+    $2::timestamp IS NULL
+    OR (likes.created_at, likes.chirp_id) < ($2::timestamp, $3::uuid)
+  )
+ORDER BY likes.created_at DESC, likes.chirp_id DESC
+LIMIT $4
+`
+
+type ListLikedChirpsParams struct {
+	UserID          uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+type ListLikedChirpsRow struct {
+	Chirp   Chirp
+	LikedAt time.Time
+}
+
+// Newest likes first; the cursor is (likes.created_at, chirp id).
+func (q *Queries) ListLikedChirps(ctx context.Context, arg ListLikedChirpsParams) ([]ListLikedChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listLikedChirps,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLikedChirpsRow
+	for rows.Next() {
+		var i ListLikedChirpsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.FannedOutAt,
+			&i.Chirp.InReplyToID,
+			&i.Chirp.ConversationID,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.RechirpOfID,
+			&i.Chirp.QuotedID,
+			&i.LikedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLikesByUser = `-- name: ListLikesByUser :many
+SELECT user_id, chirp_id, created_at FROM likes
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+// Every like, deleted chirps included, for the account export.
+func (q *Queries) ListLikesByUser(ctx context.Context, userID uuid.UUID) ([]Like, error) {
+	rows, err := q.db.QueryContext(ctx, listLikesByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Like
+	for rows.Next() {
+		var i Like
+		if err := rows.Scan(&i.UserID, &i.ChirpID, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const unlikeChirp = `-- name: UnlikeChirp :execrows
+DELETE FROM likes
+WHERE user_id = $1 AND chirp_id = $2
+`
+
+type UnlikeChirpParams struct {
+	UserID  uuid.UUID
+	ChirpID uuid.UUID
+}
+
+func (q *Queries) UnlikeChirp(ctx context.Context, arg UnlikeChirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, unlikeChirp, arg.UserID, arg.ChirpID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,86 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: login_attempts.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const clearLoginAttempts = `-- name: ClearLoginAttempts :exec
+DELETE FROM login_attempts
+WHERE key = $1
+`
+
+func (q *Queries) ClearLoginAttempts(ctx context.Context, key string) error {
+	_, err := q.db.ExecContext(ctx, clearLoginAttempts, key)
+	return err
+}
+
+const getLoginAttempt = `-- name: GetLoginAttempt :one
+SELECT key, failures, last_failed_at, locked_until FROM login_attempts
+WHERE key = $1
+`
+
+func (q *Queries) GetLoginAttempt(ctx context.Context, key string) (LoginAttempt, error) {
+	row := q.db.QueryRowContext(ctx, getLoginAttempt, key)
+	var i LoginAttempt
+	err := row.Scan(
+		&i.Key,
+		&i.Failures,
+		&i.LastFailedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const lockLogin = `-- name: LockLogin :exec
+UPDATE login_attempts
+SET locked_until = $1
+WHERE key = $2
+`
+
+type LockLoginParams struct {
+	LockedUntil sql.NullTime
+	Key         string
+}
+
+func (q *Queries) LockLogin(ctx context.Context, arg LockLoginParams) error {
+	_, err := q.db.ExecContext(ctx, lockLogin, arg.LockedUntil, arg.Key)
+	return err
+}
+
+const recordLoginFailure = `-- name: RecordLoginFailure :one
+INSERT INTO login_attempts (key, failures, last_failed_at, locked_until)
+VALUES ($1, 1, $2::timestamp, NULL)
+ON CONFLICT (key) DO UPDATE SET
+    failures = CASE
+        WHEN login_attempts.last_failed_at < $3 THEN 1
+        ELSE login_attempts.failures + 1
+    END,
+    last_failed_at = $2::timestamp
+RETURNING key, failures, last_failed_at, locked_until
+`
+
+type RecordLoginFailureParams struct {
+	Key         string
+	Now         time.Time
+	ResetBefore time.Time
+}
+
+// The counter starts over when the previous failure is older than reset_before.
+func (q *Queries) RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginAttempt, error) {
+	row := q.db.QueryRowContext(ctx, recordLoginFailure, arg.Key, arg.Now, arg.ResetBefore)
+	var i LoginAttempt
+	err := row.Scan(
+		&i.Key,
+		&i.Failures,
+		&i.LastFailedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
@@ -0,0 +1,1741 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryStore is a concurrent-safe, non-persistent Store.
+// It mirrors the constraints of the SQL schema (unique emails, ON DELETE CASCADE)
+// and returns sql.ErrNoRows for missing rows, so handlers behave the same as with Postgres.
+type MemoryStore struct {
+	mu              sync.RWMutex
+	users           map[uuid.UUID]User
+	chirps          map[uuid.UUID]Chirp
+	refreshTokens   map[string]RefreshToken           // keyed by token_hash
+	recoveryCodes   map[string]MfaRecoveryCode        // keyed by code_hash
+	usedMFATokens   map[string]UsedMfaToken           // keyed by jti
+	resetTokens     map[string]PasswordResetToken     // keyed by token_hash
+	verifyTokens    map[string]EmailVerificationToken // keyed by token_hash
+	loginAttempts   map[string]LoginAttempt
+	resetAttempts   map[string]PasswordResetAttempt
+	webhookEvents   []WebhookEvent
+	follows         map[followKey]Follow
+	timelineEntries map[timelineKey]TimelineEntry
+	likes           map[likeKey]Like
+}
+
+type followKey struct{ follower, followee uuid.UUID }
+
+type timelineKey struct{ user, chirp uuid.UUID }
+
+type likeKey struct{ user, chirp uuid.UUID }
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		users:           make(map[uuid.UUID]User),
+		chirps:          make(map[uuid.UUID]Chirp),
+		refreshTokens:   make(map[string]RefreshToken),
+		recoveryCodes:   make(map[string]MfaRecoveryCode),
+		usedMFATokens:   make(map[string]UsedMfaToken),
+		resetTokens:     make(map[string]PasswordResetToken),
+		verifyTokens:    make(map[string]EmailVerificationToken),
+		loginAttempts:   make(map[string]LoginAttempt),
+		resetAttempts:   make(map[string]PasswordResetAttempt),
+		follows:         make(map[followKey]Follow),
+		timelineEntries: make(map[timelineKey]TimelineEntry),
+		likes:           make(map[likeKey]Like),
+	}
+}
+
+// now matches the microsecond precision of Postgres TIMESTAMP columns.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// Users
+
+func (m *MemoryStore) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, u := range m.users {
+		if u.Email == arg.Email {
+			return User{}, fmt.Errorf("duplicate key value violates unique constraint \"users_email_key\"")
+		}
+	}
+
+	t := now()
+	user := User{
+		ID:             uuid.New(),
+		CreatedAt:      t,
+		UpdatedAt:      t,
+		Email:          arg.Email,
+		HashedPassword: arg.HashedPassword,
+		Role:           "user",
+	}
+	m.users[user.ID] = user
+	return user, nil
+}
+
+func (m *MemoryStore) DeleteAllUsers(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// ON DELETE CASCADE
+	m.users = make(map[uuid.UUID]User)
+	m.chirps = make(map[uuid.UUID]Chirp)
+	m.refreshTokens = make(map[string]RefreshToken)
+	m.recoveryCodes = make(map[string]MfaRecoveryCode)
+	m.usedMFATokens = make(map[string]UsedMfaToken)
+	m.resetTokens = make(map[string]PasswordResetToken)
+	m.verifyTokens = make(map[string]EmailVerificationToken)
+	m.webhookEvents = nil
+	m.follows = make(map[followKey]Follow)
+	m.timelineEntries = make(map[timelineKey]TimelineEntry)
+	m.likes = make(map[likeKey]Like)
+	return nil
+}
+
+func (m *MemoryStore) GetUserFromEmail(ctx context.Context, email string) (User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, u := range m.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return User{}, sql.ErrNoRows
+}
+
+func (m *MemoryStore) GetUserFromUserID(ctx context.Context, id uuid.UUID) (User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	u, ok := m.users[id]
+	if !ok {
+		return User{}, sql.ErrNoRows
+	}
+	return u, nil
+}
+
+func (m *MemoryStore) GetUserFromHandle(ctx context.Context, handle sql.NullString) (User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// NULL never matches, as in SQL.
+	if !handle.Valid {
+		return User{}, sql.ErrNoRows
+	}
+	for _, u := range m.users {
+		if u.Handle == handle {
+			return u, nil
+		}
+	}
+	return User{}, sql.ErrNoRows
+}
+
+func (m *MemoryStore) ListUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var out []User
+	seen := make(map[uuid.UUID]bool)
+	for _, id := range ids {
+		if u, ok := m.users[id]; ok && !seen[id] {
+			seen[id] = true
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) SetChirpyRed(ctx context.Context, arg SetChirpyRedParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[arg.ID]
+	if !ok {
+		return nil
+	}
+	u.IsChirpyRed = arg.IsChirpyRed
+	u.UpdatedAt = now()
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *MemoryStore) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[arg.ID]
+	if !ok {
+		return nil
+	}
+	for _, other := range m.users {
+		if other.ID != u.ID && other.Email == arg.Email {
+			return fmt.Errorf("duplicate key value violates unique constraint \"users_email_key\"")
+		}
+	}
+	if u.Email != arg.Email {
+		u.EmailVerifiedAt = sql.NullTime{}
+	}
+	u.Email = arg.Email
+	u.HashedPassword = arg.HashedPassword
+	u.UpdatedAt = now()
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *MemoryStore) SetTokensValidAfter(ctx context.Context, arg SetTokensValidAfterParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[arg.ID]
+	if !ok {
+		return nil
+	}
+	u.TokensValidAfter = arg.TokensValidAfter
+	u.UpdatedAt = now()
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *MemoryStore) SetUserRole(ctx context.Context, arg SetUserRoleParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if arg.Role != "user" && arg.Role != "admin" {
+		return fmt.Errorf("new row for relation \"users\" violates check constraint \"users_role_check\"")
+	}
+	u, ok := m.users[arg.ID]
+	if !ok {
+		return nil
+	}
+	u.Role = arg.Role
+	u.UpdatedAt = now()
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *MemoryStore) SetUserEmail(ctx context.Context, arg SetUserEmailParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, u := range m.users {
+		if u.Email == arg.Email && u.ID != arg.ID {
+			return fmt.Errorf("duplicate key value violates unique constraint \"users_email_key\"")
+		}
+	}
+	u, ok := m.users[arg.ID]
+	if !ok {
+		return nil
+	}
+	u.Email = arg.Email
+	u.EmailVerifiedAt = sql.NullTime{}
+	u.UpdatedAt = now()
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *MemoryStore) SetUserProfile(ctx context.Context, arg SetUserProfileParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if arg.Handle.Valid {
+		for _, u := range m.users {
+			if u.Handle == arg.Handle && u.ID != arg.ID {
+				return fmt.Errorf("duplicate key value violates unique constraint \"users_handle_key\"")
+			}
+		}
+	}
+	u, ok := m.users[arg.ID]
+	if !ok {
+		return nil
+	}
+	u.Handle = arg.Handle
+	u.DisplayName = arg.DisplayName
+	u.Bio = arg.Bio
+	if u.AvatarUrl != arg.AvatarUrl {
+		u.AvatarKey = ""
+	}
+	u.AvatarUrl = arg.AvatarUrl
+	u.UpdatedAt = now()
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *MemoryStore) PatchUser(ctx context.Context, arg PatchUserParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, u := range m.users {
+		if u.ID == arg.ID {
+			continue
+		}
+		if arg.Email.Valid && u.Email == arg.Email.String {
+			return fmt.Errorf("duplicate key value violates unique constraint \"users_email_key\"")
+		}
+		if arg.Handle.Valid && u.Handle == arg.Handle {
+			return fmt.Errorf("duplicate key value violates unique constraint \"users_handle_key\"")
+		}
+	}
+	u, ok := m.users[arg.ID]
+	if !ok {
+		return nil
+	}
+	u.Handle = arg.Handle
+	u.DisplayName = arg.DisplayName
+	u.Bio = arg.Bio
+	if u.AvatarUrl != arg.AvatarUrl {
+		u.AvatarKey = ""
+	}
+	u.AvatarUrl = arg.AvatarUrl
+	if arg.Email.Valid {
+		u.Email = arg.Email.String
+		u.EmailVerifiedAt = sql.NullTime{}
+	}
+	if arg.HashedPassword.Valid {
+		u.HashedPassword = arg.HashedPassword.String
+	}
+	u.UpdatedAt = now()
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *MemoryStore) SetUserAvatar(ctx context.Context, arg SetUserAvatarParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[arg.ID]
+	if !ok {
+		return nil
+	}
+	u.AvatarUrl = arg.AvatarUrl
+	u.AvatarKey = arg.AvatarKey
+	u.UpdatedAt = now()
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *MemoryStore) SetUserHeader(ctx context.Context, arg SetUserHeaderParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[arg.ID]
+	if !ok {
+		return nil
+	}
+	u.HeaderUrl = arg.HeaderUrl
+	u.HeaderKey = arg.HeaderKey
+	u.UpdatedAt = now()
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *MemoryStore) ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[arg.ID]
+	if !ok {
+		return nil
+	}
+	u.DeleteAfter = arg.DeleteAfter
+	u.UpdatedAt = now()
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *MemoryStore) CancelUserDeletion(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[id]
+	if !ok {
+		return nil
+	}
+	u.DeleteAfter = sql.NullTime{}
+	u.UpdatedAt = now()
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *MemoryStore) PurgeDeletedUsers(ctx context.Context, t time.Time) ([]PurgeDeletedUsersRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var rows []PurgeDeletedUsersRow
+	for _, u := range m.users {
+		if u.DeleteAfter.Valid && !u.DeleteAfter.Time.After(t) {
+			rows = append(rows, PurgeDeletedUsersRow{ID: u.ID, AvatarKey: u.AvatarKey, HeaderKey: u.HeaderKey})
+			m.deleteUser(u.ID)
+		}
+	}
+	return rows, nil
+}
+
+// deleteUser removes a user and, like ON DELETE CASCADE, everything that references it.
+// The caller holds the write lock.
+func (m *MemoryStore) deleteUser(id uuid.UUID) {
+	delete(m.users, id)
+	for k, c := range m.chirps {
+		if c.UserID == id {
+			m.deleteChirp(k)
+		}
+	}
+	for k, rt := range m.refreshTokens {
+		if rt.UserID == id {
+			delete(m.refreshTokens, k)
+		}
+	}
+	for k, rc := range m.recoveryCodes {
+		if rc.UserID == id {
+			delete(m.recoveryCodes, k)
+		}
+	}
+	for k, t := range m.usedMFATokens {
+		if t.UserID == id {
+			delete(m.usedMFATokens, k)
+		}
+	}
+	for k, rt := range m.resetTokens {
+		if rt.UserID == id {
+			delete(m.resetTokens, k)
+		}
+	}
+	for k, vt := range m.verifyTokens {
+		if vt.UserID == id {
+			delete(m.verifyTokens, k)
+		}
+	}
+	events := m.webhookEvents[:0]
+	for _, e := range m.webhookEvents {
+		if e.UserID != id {
+			events = append(events, e)
+		}
+	}
+	m.webhookEvents = events
+	for k := range m.follows {
+		if k.follower == id || k.followee == id {
+			delete(m.follows, k)
+		}
+	}
+	for k, e := range m.timelineEntries {
+		if e.UserID == id || e.AuthorID == id {
+			delete(m.timelineEntries, k)
+		}
+	}
+	for k := range m.likes {
+		if k.user == id {
+			delete(m.likes, k)
+		}
+	}
+}
+
+func (m *MemoryStore) SetUserPassword(ctx context.Context, arg SetUserPasswordParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[arg.ID]
+	if !ok {
+		return nil
+	}
+	u.HashedPassword = arg.HashedPassword
+	u.UpdatedAt = now()
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *MemoryStore) RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[arg.ID]
+	if !ok || u.HashedPassword != arg.OldHash {
+		return 0, nil
+	}
+	u.HashedPassword = arg.NewHash
+	m.users[u.ID] = u
+	return 1, nil
+}
+
+func (m *MemoryStore) MarkEmailVerified(ctx context.Context, arg MarkEmailVerifiedParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[arg.ID]
+	if !ok || u.Email != arg.Email {
+		return 0, nil
+	}
+	t := now()
+	u.EmailVerifiedAt = sql.NullTime{Time: t, Valid: true}
+	u.UpdatedAt = t
+	m.users[u.ID] = u
+	return 1, nil
+}
+
+func (m *MemoryStore) SetUserTOTPSecret(ctx context.Context, arg SetUserTOTPSecretParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[arg.ID]
+	if !ok {
+		return nil
+	}
+	u.TotpSecret = arg.TotpSecret
+	u.TotpEnabledAt = sql.NullTime{}
+	u.TotpLastStep = sql.NullInt64{}
+	u.UpdatedAt = now()
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *MemoryStore) EnableUserTOTP(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[id]
+	if !ok || !u.TotpSecret.Valid {
+		return nil
+	}
+	t := now()
+	u.TotpEnabledAt = sql.NullTime{Time: t, Valid: true}
+	u.UpdatedAt = t
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *MemoryStore) DisableUserTOTP(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[id]
+	if !ok {
+		return nil
+	}
+	u.TotpSecret = sql.NullString{}
+	u.TotpEnabledAt = sql.NullTime{}
+	u.TotpLastStep = sql.NullInt64{}
+	u.UpdatedAt = now()
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *MemoryStore) UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[arg.ID]
+	if !ok || (u.TotpLastStep.Valid && u.TotpLastStep.Int64 >= arg.Step) {
+		return 0, nil
+	}
+	u.TotpLastStep = sql.NullInt64{Int64: arg.Step, Valid: true}
+	m.users[u.ID] = u
+	return 1, nil
+}
+
+// Chirps
+
+func (m *MemoryStore) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[arg.UserID]; !ok {
+		return Chirp{}, fmt.Errorf("insert or update on table \"chirps\" violates foreign key constraint")
+	}
+	if _, ok := m.chirps[arg.InReplyToID.UUID]; arg.InReplyToID.Valid && !ok {
+		return Chirp{}, fmt.Errorf("insert or update on table \"chirps\" violates foreign key constraint")
+	}
+
+	t := now()
+	chirp := Chirp{
+		ID:          uuid.New(),
+		CreatedAt:   t,
+		UpdatedAt:   t,
+		Body:        arg.Body,
+		UserID:      arg.UserID,
+		InReplyToID: arg.InReplyToID,
+		QuotedID:    arg.QuotedID,
+	}
+	chirp.ConversationID = chirp.ID
+	if arg.ConversationID.Valid {
+		chirp.ConversationID = arg.ConversationID.UUID
+	}
+	m.chirps[chirp.ID] = chirp
+	return chirp, nil
+}
+
+func (m *MemoryStore) DeleteChirp(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.deleteChirp(id)
+	return nil
+}
+
+func (m *MemoryStore) deleteChirp(id uuid.UUID) {
+	delete(m.chirps, id)
+	for k, c := range m.chirps {
+		// ON DELETE CASCADE
+		if c.RechirpOfID.Valid && c.RechirpOfID.UUID == id {
+			m.deleteChirp(k)
+			continue
+		}
+		// ON DELETE SET NULL; quoted_id has no foreign key and keeps the ID.
+		if c.InReplyToID.Valid && c.InReplyToID.UUID == id {
+			c.InReplyToID = uuid.NullUUID{}
+		}
+		m.chirps[k] = c
+	}
+	for k := range m.timelineEntries {
+		if k.chirp == id {
+			delete(m.timelineEntries, k)
+		}
+	}
+	for k := range m.likes {
+		if k.chirp == id {
+			delete(m.likes, k)
+		}
+	}
+}
+
+func (m *MemoryStore) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	c, ok := m.chirps[id]
+	if !ok {
+		return Chirp{}, sql.ErrNoRows
+	}
+	return c, nil
+}
+
+func (m *MemoryStore) ListChirpsAsc(ctx context.Context, arg ListChirpsAscParams) ([]Chirp, error) {
+	return m.listChirps(m.byVisibleAuthor(arg.AuthorID), arg.CursorCreatedAt, arg.CursorID, arg.Limit, false), nil
+}
+
+func (m *MemoryStore) ListChirpsDesc(ctx context.Context, arg ListChirpsDescParams) ([]Chirp, error) {
+	return m.listChirps(m.byVisibleAuthor(arg.AuthorID), arg.CursorCreatedAt, arg.CursorID, arg.Limit, true), nil
+}
+
+// byAuthor matches every chirp but tombstones when authorID is NULL.
+func byAuthor(authorID uuid.NullUUID) func(Chirp) bool {
+	return func(c Chirp) bool {
+		return !c.DeletedAt.Valid && (!authorID.Valid || c.UserID == authorID.UUID)
+	}
+}
+
+// byVisibleAuthor is byAuthor without the chirps of accounts waiting for deletion.
+// Like every listChirps predicate it runs under the read lock.
+func (m *MemoryStore) byVisibleAuthor(authorID uuid.NullUUID) func(Chirp) bool {
+	match := byAuthor(authorID)
+	return func(c Chirp) bool {
+		return match(c) && !m.pendingDeletion(c.UserID)
+	}
+}
+
+// pendingDeletion reports whether the user's account is waiting for deletion. The caller holds the lock.
+func (m *MemoryStore) pendingDeletion(userID uuid.UUID) bool {
+	return m.users[userID].DeleteAfter.Valid
+}
+
+// chirpLess orders chirps by (created_at, id) like the keyset queries.
+func chirpLess(a, b Chirp) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID.String() < b.ID.String()
+}
+
+func (m *MemoryStore) listChirps(match func(Chirp) bool, cursorCreatedAt sql.NullTime, cursorID uuid.NullUUID, limit int32, desc bool) []Chirp {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	cursor := Chirp{CreatedAt: cursorCreatedAt.Time, ID: cursorID.UUID}
+
+	var items []Chirp
+	for _, c := range m.chirps {
+		if !match(c) {
+			continue
+		}
+		if cursorCreatedAt.Valid {
+			if !desc && !chirpLess(cursor, c) {
+				continue
+			}
+			if desc && !chirpLess(c, cursor) {
+				continue
+			}
+		}
+		items = append(items, c)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if desc {
+			return chirpLess(items[j], items[i])
+		}
+		return chirpLess(items[i], items[j])
+	})
+
+	if limit >= 0 && len(items) > int(limit) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// Refresh tokens
+
+func (m *MemoryStore) GetRefreshTokenFromToken(ctx context.Context, tokenHash string) (RefreshToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	rt, ok := m.refreshTokens[tokenHash]
+	if !ok {
+		return RefreshToken{}, sql.ErrNoRows
+	}
+	return rt, nil
+}
+
+func (m *MemoryStore) SetRevokeRefreshToken(ctx context.Context, tokenHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rt, ok := m.refreshTokens[tokenHash]
+	if !ok {
+		return nil
+	}
+	t := now()
+	rt.RevokedAt = sql.NullTime{Time: t, Valid: true}
+	rt.UpdatedAt = t
+	m.refreshTokens[tokenHash] = rt
+	return nil
+}
+
+func (m *MemoryStore) StoreRefreshToken(ctx context.Context, arg StoreRefreshTokenParams) (RefreshToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[arg.UserID]; !ok {
+		return RefreshToken{}, fmt.Errorf("insert or update on table \"refresh_tokens\" violates foreign key constraint")
+	}
+	if _, ok := m.refreshTokens[arg.TokenHash]; ok {
+		return RefreshToken{}, fmt.Errorf("duplicate key value violates unique constraint \"refresh_tokens_pkey\"")
+	}
+
+	t := now()
+	rt := RefreshToken{
+		TokenHash:        arg.TokenHash,
+		CreatedAt:        t,
+		UpdatedAt:        t,
+		UserID:           arg.UserID,
+		ExpiresAt:        arg.ExpiresAt,
+		FamilyID:         arg.FamilyID,
+		UserAgent:        arg.UserAgent,
+		IpAddress:        arg.IpAddress,
+		SessionStartedAt: t,
+	}
+	if arg.SessionStartedAt.Valid {
+		rt.SessionStartedAt = arg.SessionStartedAt.Time
+	}
+	m.refreshTokens[rt.TokenHash] = rt
+	return rt, nil
+}
+
+func (m *MemoryStore) RotateRefreshToken(ctx context.Context, arg RotateRefreshTokenParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rt, ok := m.refreshTokens[arg.TokenHash]
+	if !ok || rt.RevokedAt.Valid {
+		return 0, nil
+	}
+	t := now()
+	rt.RevokedAt = sql.NullTime{Time: t, Valid: true}
+	rt.UpdatedAt = t
+	rt.ReplacedBy = arg.ReplacedBy
+	m.refreshTokens[rt.TokenHash] = rt
+	return 1, nil
+}
+
+func (m *MemoryStore) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	m.revokeRefreshTokens(func(rt RefreshToken) bool { return rt.FamilyID == familyID })
+	return nil
+}
+
+func (m *MemoryStore) ListActiveRefreshTokensByUser(ctx context.Context, arg ListActiveRefreshTokensByUserParams) ([]RefreshToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var items []RefreshToken
+	for _, rt := range m.refreshTokens {
+		if rt.UserID == arg.UserID && !rt.RevokedAt.Valid && rt.ExpiresAt.After(arg.Now) {
+			items = append(items, rt)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].SessionStartedAt.After(items[j].SessionStartedAt) })
+	return items, nil
+}
+
+func (m *MemoryStore) ListRefreshTokensByUser(ctx context.Context, userID uuid.UUID) ([]RefreshToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var items []RefreshToken
+	for _, rt := range m.refreshTokens {
+		if rt.UserID == userID {
+			items = append(items, rt)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].CreatedAt.Before(items[j].CreatedAt) })
+	return items, nil
+}
+
+func (m *MemoryStore) RevokeUserRefreshTokenFamily(ctx context.Context, arg RevokeUserRefreshTokenFamilyParams) (int64, error) {
+	return m.revokeRefreshTokens(func(rt RefreshToken) bool {
+		return rt.FamilyID == arg.FamilyID && rt.UserID == arg.UserID
+	}), nil
+}
+
+func (m *MemoryStore) RevokeAllRefreshTokensForUser(ctx context.Context, userID uuid.UUID) error {
+	m.revokeRefreshTokens(func(rt RefreshToken) bool { return rt.UserID == userID })
+	return nil
+}
+
+// revokeRefreshTokens revokes every active token matching match and returns how many were revoked.
+func (m *MemoryStore) revokeRefreshTokens(match func(RefreshToken) bool) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t := now()
+	var n int64
+	for tokenHash, rt := range m.refreshTokens {
+		if rt.RevokedAt.Valid || !match(rt) {
+			continue
+		}
+		rt.RevokedAt = sql.NullTime{Time: t, Valid: true}
+		rt.UpdatedAt = t
+		m.refreshTokens[tokenHash] = rt
+		n++
+	}
+	return n
+}
+
+// MFA recovery codes
+
+func (m *MemoryStore) CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[arg.UserID]; !ok {
+		return fmt.Errorf("insert or update on table \"mfa_recovery_codes\" violates foreign key constraint")
+	}
+	if _, ok := m.recoveryCodes[arg.CodeHash]; ok {
+		return fmt.Errorf("duplicate key value violates unique constraint \"mfa_recovery_codes_pkey\"")
+	}
+	m.recoveryCodes[arg.CodeHash] = MfaRecoveryCode{
+		CodeHash:  arg.CodeHash,
+		CreatedAt: now(),
+		UserID:    arg.UserID,
+	}
+	return nil
+}
+
+func (m *MemoryStore) DeleteRecoveryCodesForUser(ctx context.Context, userID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for codeHash, c := range m.recoveryCodes {
+		if c.UserID == userID {
+			delete(m.recoveryCodes, codeHash)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.recoveryCodes[arg.CodeHash]
+	if !ok || c.UserID != arg.UserID || c.UsedAt.Valid {
+		return 0, nil
+	}
+	c.UsedAt = sql.NullTime{Time: now(), Valid: true}
+	m.recoveryCodes[arg.CodeHash] = c
+	return 1, nil
+}
+
+func (m *MemoryStore) UseMFAToken(ctx context.Context, arg UseMFATokenParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[arg.UserID]; !ok {
+		return 0, fmt.Errorf("insert or update on table \"used_mfa_tokens\" violates foreign key constraint")
+	}
+	if _, ok := m.usedMFATokens[arg.Jti]; ok {
+		return 0, nil
+	}
+	m.usedMFATokens[arg.Jti] = UsedMfaToken(arg)
+	return 1, nil
+}
+
+func (m *MemoryStore) DeleteExpiredMFATokens(ctx context.Context, before time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for jti, t := range m.usedMFATokens {
+		if t.ExpiresAt.Before(before) {
+			delete(m.usedMFATokens, jti)
+		}
+	}
+	return nil
+}
+
+// Password reset tokens
+
+func (m *MemoryStore) CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[arg.UserID]; !ok {
+		return fmt.Errorf("insert or update on table \"password_reset_tokens\" violates foreign key constraint")
+	}
+	if _, ok := m.resetTokens[arg.TokenHash]; ok {
+		return fmt.Errorf("duplicate key value violates unique constraint \"password_reset_tokens_pkey\"")
+	}
+	m.resetTokens[arg.TokenHash] = PasswordResetToken{
+		TokenHash: arg.TokenHash,
+		CreatedAt: now(),
+		UserID:    arg.UserID,
+		ExpiresAt: arg.ExpiresAt,
+	}
+	return nil
+}
+
+func (m *MemoryStore) GetPasswordResetTokenUser(ctx context.Context, arg GetPasswordResetTokenUserParams) (uuid.UUID, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	rt, ok := m.resetTokens[arg.TokenHash]
+	if !ok || rt.UsedAt.Valid || !rt.ExpiresAt.After(arg.Now) {
+		return uuid.Nil, sql.ErrNoRows
+	}
+	return rt.UserID, nil
+}
+
+func (m *MemoryStore) ConsumePasswordResetToken(ctx context.Context, arg ConsumePasswordResetTokenParams) (uuid.UUID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rt, ok := m.resetTokens[arg.TokenHash]
+	if !ok || rt.UsedAt.Valid || !rt.ExpiresAt.After(arg.Now) {
+		return uuid.Nil, sql.ErrNoRows
+	}
+	rt.UsedAt = sql.NullTime{Time: arg.Now, Valid: true}
+	m.resetTokens[arg.TokenHash] = rt
+	return rt.UserID, nil
+}
+
+func (m *MemoryStore) InvalidatePasswordResetTokensForUser(ctx context.Context, userID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t := now()
+	for tokenHash, rt := range m.resetTokens {
+		if rt.UserID != userID || rt.UsedAt.Valid {
+			continue
+		}
+		rt.UsedAt = sql.NullTime{Time: t, Valid: true}
+		m.resetTokens[tokenHash] = rt
+	}
+	return nil
+}
+
+// Email verification tokens
+
+func (m *MemoryStore) CreateEmailVerificationToken(ctx context.Context, arg CreateEmailVerificationTokenParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[arg.UserID]; !ok {
+		return fmt.Errorf("insert or update on table \"email_verification_tokens\" violates foreign key constraint")
+	}
+	if _, ok := m.verifyTokens[arg.TokenHash]; ok {
+		return fmt.Errorf("duplicate key value violates unique constraint \"email_verification_tokens_pkey\"")
+	}
+	m.verifyTokens[arg.TokenHash] = EmailVerificationToken{
+		TokenHash: arg.TokenHash,
+		CreatedAt: now(),
+		UserID:    arg.UserID,
+		Email:     arg.Email,
+		ExpiresAt: arg.ExpiresAt,
+	}
+	return nil
+}
+
+func (m *MemoryStore) ConsumeEmailVerificationToken(ctx context.Context, arg ConsumeEmailVerificationTokenParams) (ConsumeEmailVerificationTokenRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	vt, ok := m.verifyTokens[arg.TokenHash]
+	if !ok || vt.UsedAt.Valid || !vt.ExpiresAt.After(arg.Now) {
+		return ConsumeEmailVerificationTokenRow{}, sql.ErrNoRows
+	}
+	vt.UsedAt = sql.NullTime{Time: arg.Now, Valid: true}
+	m.verifyTokens[arg.TokenHash] = vt
+	return ConsumeEmailVerificationTokenRow{UserID: vt.UserID, Email: vt.Email}, nil
+}
+
+// Login attempts
+
+func (m *MemoryStore) GetLoginAttempt(ctx context.Context, key string) (LoginAttempt, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	a, ok := m.loginAttempts[key]
+	if !ok {
+		return LoginAttempt{}, sql.ErrNoRows
+	}
+	return a, nil
+}
+
+func (m *MemoryStore) RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginAttempt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a, ok := m.loginAttempts[arg.Key]
+	if !ok || a.LastFailedAt.Before(arg.ResetBefore) {
+		a.Key = arg.Key
+		a.Failures = 1
+	} else {
+		a.Failures++
+	}
+	a.LastFailedAt = arg.Now
+	m.loginAttempts[arg.Key] = a
+	return a, nil
+}
+
+func (m *MemoryStore) LockLogin(ctx context.Context, arg LockLoginParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a, ok := m.loginAttempts[arg.Key]
+	if !ok {
+		return nil
+	}
+	a.LockedUntil = arg.LockedUntil
+	m.loginAttempts[arg.Key] = a
+	return nil
+}
+
+func (m *MemoryStore) ClearLoginAttempts(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.loginAttempts, key)
+	return nil
+}
+
+// Password reset attempts
+
+func (m *MemoryStore) GetPasswordResetAttempt(ctx context.Context, key string) (PasswordResetAttempt, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	a, ok := m.resetAttempts[key]
+	if !ok {
+		return PasswordResetAttempt{}, sql.ErrNoRows
+	}
+	return a, nil
+}
+
+func (m *MemoryStore) RecordPasswordResetRequest(ctx context.Context, arg RecordPasswordResetRequestParams) (PasswordResetAttempt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a, ok := m.resetAttempts[arg.Key]
+	if !ok || a.LastRequestedAt.Before(arg.ResetBefore) {
+		a.Key = arg.Key
+		a.Requests = 1
+	} else {
+		a.Requests++
+	}
+	a.LastRequestedAt = arg.Now
+	m.resetAttempts[arg.Key] = a
+	return a, nil
+}
+
+func (m *MemoryStore) LockPasswordReset(ctx context.Context, arg LockPasswordResetParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a, ok := m.resetAttempts[arg.Key]
+	if !ok {
+		return nil
+	}
+	a.LockedUntil = arg.LockedUntil
+	m.resetAttempts[arg.Key] = a
+	return nil
+}
+
+// Webhook events
+
+func (m *MemoryStore) CreateWebhookEvent(ctx context.Context, arg CreateWebhookEventParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[arg.UserID]; !ok {
+		return fmt.Errorf("insert or update on table \"webhook_events\" violates foreign key constraint")
+	}
+	m.webhookEvents = append(m.webhookEvents, WebhookEvent{
+		ID:        uuid.New(),
+		CreatedAt: now(),
+		UserID:    arg.UserID,
+		Event:     arg.Event,
+		Payload:   arg.Payload,
+	})
+	return nil
+}
+
+func (m *MemoryStore) ListWebhookEventsByUser(ctx context.Context, userID uuid.UUID) ([]WebhookEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var items []WebhookEvent
+	for _, e := range m.webhookEvents {
+		if e.UserID == userID {
+			items = append(items, e)
+		}
+	}
+	return items, nil
+}
+
+// Follows
+
+func (m *MemoryStore) FollowUser(ctx context.Context, arg FollowUserParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, followerOK := m.users[arg.FollowerID]
+	_, followeeOK := m.users[arg.FolloweeID]
+	if !followerOK || !followeeOK {
+		return 0, fmt.Errorf("insert or update on table \"follows\" violates foreign key constraint")
+	}
+	if arg.FollowerID == arg.FolloweeID {
+		return 0, fmt.Errorf("new row for relation \"follows\" violates check constraint \"follows_check\"")
+	}
+	k := followKey{arg.FollowerID, arg.FolloweeID}
+	if _, ok := m.follows[k]; ok {
+		return 0, nil // ON CONFLICT DO NOTHING
+	}
+	m.follows[k] = Follow{FollowerID: arg.FollowerID, FolloweeID: arg.FolloweeID, CreatedAt: now()}
+	return 1, nil
+}
+
+func (m *MemoryStore) UnfollowUser(ctx context.Context, arg UnfollowUserParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	k := followKey{arg.FollowerID, arg.FolloweeID}
+	if _, ok := m.follows[k]; !ok {
+		return 0, nil
+	}
+	delete(m.follows, k)
+	return 1, nil
+}
+
+func (m *MemoryStore) CountFollowers(ctx context.Context, followeeID uuid.UUID) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var n int64
+	for k := range m.follows {
+		if k.followee == followeeID {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *MemoryStore) CountFollowing(ctx context.Context, followerID uuid.UUID) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var n int64
+	for k := range m.follows {
+		if k.follower == followerID {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *MemoryStore) ListFollowers(ctx context.Context, arg ListFollowersParams) ([]ListFollowersRow, error) {
+	rows := m.listFollows(func(f Follow) (uuid.UUID, bool) {
+		return f.FollowerID, f.FolloweeID == arg.UserID
+	}, arg.CursorCreatedAt, arg.CursorID, arg.Limit)
+	var out []ListFollowersRow
+	for _, row := range rows {
+		out = append(out, ListFollowersRow(row))
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) ListFollowing(ctx context.Context, arg ListFollowingParams) ([]ListFollowingRow, error) {
+	rows := m.listFollows(func(f Follow) (uuid.UUID, bool) {
+		return f.FolloweeID, f.FollowerID == arg.UserID
+	}, arg.CursorCreatedAt, arg.CursorID, arg.Limit)
+	var out []ListFollowingRow
+	for _, row := range rows {
+		out = append(out, ListFollowingRow(row))
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) ListFollowsByUser(ctx context.Context, userID uuid.UUID) ([]Follow, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var items []Follow
+	for _, f := range m.follows {
+		if f.FollowerID == userID || f.FolloweeID == userID {
+			items = append(items, f)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].CreatedAt.Before(items[j].CreatedAt) })
+	return items, nil
+}
+
+// listFollows returns the other side of the matching follows, newest first,
+// keyed by (follows.created_at, other id) like the keyset queries.
+func (m *MemoryStore) listFollows(other func(Follow) (uuid.UUID, bool), cursorCreatedAt sql.NullTime, cursorID uuid.NullUUID, limit int32) []ListFollowersRow {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	less := func(a, b ListFollowersRow) bool {
+		if !a.FollowedAt.Equal(b.FollowedAt) {
+			return a.FollowedAt.Before(b.FollowedAt)
+		}
+		return a.ID.String() < b.ID.String()
+	}
+	cursor := ListFollowersRow{FollowedAt: cursorCreatedAt.Time, ID: cursorID.UUID}
+
+	var items []ListFollowersRow
+	for _, f := range m.follows {
+		id, ok := other(f)
+		if !ok {
+			continue
+		}
+		u, ok := m.users[id]
+		if !ok || u.DeleteAfter.Valid {
+			continue
+		}
+		row := ListFollowersRow{ID: u.ID, Handle: u.Handle, DisplayName: u.DisplayName, AvatarUrl: u.AvatarUrl, FollowedAt: f.CreatedAt}
+		if cursorCreatedAt.Valid && !less(row, cursor) {
+			continue
+		}
+		items = append(items, row)
+	}
+
+	sort.Slice(items, func(i, j int) bool { return less(items[j], items[i]) })
+	if limit >= 0 && len(items) > int(limit) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// Timelines
+
+func (m *MemoryStore) FanOutChirp(ctx context.Context, id uuid.UUID) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.chirps[id]
+	if !ok {
+		return 0, nil
+	}
+	var n int64
+	for k := range m.follows {
+		if k.followee == c.UserID && m.addTimelineEntry(k.follower, c) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// addTimelineEntry is INSERT ... ON CONFLICT DO NOTHING; it reports whether a row was added.
+func (m *MemoryStore) addTimelineEntry(userID uuid.UUID, c Chirp) bool {
+	k := timelineKey{userID, c.ID}
+	if _, ok := m.timelineEntries[k]; ok {
+		return false
+	}
+	m.timelineEntries[k] = TimelineEntry{UserID: userID, ChirpID: c.ID, AuthorID: c.UserID, CreatedAt: c.CreatedAt}
+	return true
+}
+
+func (m *MemoryStore) MarkChirpFannedOut(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.chirps[id]
+	if !ok {
+		return nil
+	}
+	c.FannedOutAt = sql.NullTime{Time: now(), Valid: true}
+	m.chirps[id] = c
+	return nil
+}
+
+func (m *MemoryStore) BackfillTimeline(ctx context.Context, arg BackfillTimelineParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[arg.UserID]; !ok {
+		return fmt.Errorf("insert or update on table \"timeline_entries\" violates foreign key constraint")
+	}
+	var items []Chirp
+	for _, c := range m.chirps {
+		if c.UserID == arg.FolloweeID && !c.DeletedAt.Valid {
+			items = append(items, c)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return chirpLess(items[j], items[i]) })
+	if len(items) > int(arg.Limit) {
+		items = items[:arg.Limit]
+	}
+	for _, c := range items {
+		m.addTimelineEntry(arg.UserID, c)
+	}
+	return nil
+}
+
+func (m *MemoryStore) DeleteTimelineEntriesByAuthor(ctx context.Context, arg DeleteTimelineEntriesByAuthorParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for k, e := range m.timelineEntries {
+		if e.UserID == arg.UserID && e.AuthorID == arg.AuthorID {
+			delete(m.timelineEntries, k)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) ListTimelineEntries(ctx context.Context, arg ListTimelineEntriesParams) ([]Chirp, error) {
+	// The predicate runs under listChirps' read lock.
+	inTimeline := func(c Chirp) bool {
+		_, ok := m.timelineEntries[timelineKey{arg.UserID, c.ID}]
+		return ok && !m.pendingDeletion(c.UserID)
+	}
+	return m.listChirps(inTimeline, arg.CursorCreatedAt, arg.CursorID, arg.Limit, true), nil
+}
+
+func (m *MemoryStore) ListUnfannedTimelineChirps(ctx context.Context, arg ListUnfannedTimelineChirpsParams) ([]Chirp, error) {
+	unfanned := func(c Chirp) bool {
+		_, follows := m.follows[followKey{arg.UserID, c.UserID}]
+		return follows && !c.FannedOutAt.Valid && !c.DeletedAt.Valid && !m.pendingDeletion(c.UserID)
+	}
+	return m.listChirps(unfanned, arg.CursorCreatedAt, arg.CursorID, arg.Limit, true), nil
+}
+
+func (m *MemoryStore) ListUnfannedChirps(ctx context.Context) ([]Chirp, error) {
+	unfanned := func(c Chirp) bool { return !c.FannedOutAt.Valid }
+	return m.listChirps(unfanned, sql.NullTime{}, uuid.NullUUID{}, -1, false), nil
+}
+
+func (m *MemoryStore) DeleteTimelineEntriesForChirp(ctx context.Context, chirpID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for k := range m.timelineEntries {
+		if k.chirp == chirpID {
+			delete(m.timelineEntries, k)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) DeleteAllTimelineEntries(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.timelineEntries = make(map[timelineKey]TimelineEntry)
+	return nil
+}
+
+func (m *MemoryStore) RebuildTimelineEntries(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var n int64
+	for _, c := range m.chirps {
+		if !c.FannedOutAt.Valid || c.DeletedAt.Valid {
+			continue
+		}
+		for k := range m.follows {
+			if k.followee == c.UserID && m.addTimelineEntry(k.follower, c) {
+				n++
+			}
+		}
+	}
+	return n, nil
+}
+
+// Replies
+
+func (m *MemoryStore) TombstoneChirp(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.chirps[id]
+	if !ok {
+		return nil
+	}
+	t := now()
+	c.Body = ""
+	c.DeletedAt = sql.NullTime{Time: t, Valid: true}
+	c.UpdatedAt = t
+	m.chirps[id] = c
+	return nil
+}
+
+func (m *MemoryStore) CountReplies(ctx context.Context, id uuid.UUID) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var n int64
+	for _, c := range m.chirps {
+		if c.InReplyToID.Valid && c.InReplyToID.UUID == id {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *MemoryStore) CountRepliesByIDs(ctx context.Context, ids []uuid.UUID) ([]CountRepliesByIDsRow, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	wanted := make(map[uuid.UUID]bool)
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	counts := make(map[uuid.UUID]int64)
+	for _, c := range m.chirps {
+		if c.InReplyToID.Valid && wanted[c.InReplyToID.UUID] {
+			counts[c.InReplyToID.UUID]++
+		}
+	}
+	var out []CountRepliesByIDsRow
+	for id, n := range counts {
+		out = append(out, CountRepliesByIDsRow{InReplyToID: uuid.NullUUID{UUID: id, Valid: true}, ReplyCount: n})
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) ListChirpAncestors(ctx context.Context, arg ListChirpAncestorsParams) ([]Chirp, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var items []Chirp
+	c, ok := m.chirps[arg.ID]
+	for ok && c.InReplyToID.Valid && len(items) < int(arg.MaxDepth) {
+		c, ok = m.chirps[c.InReplyToID.UUID]
+		if ok {
+			items = append(items, c)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return chirpLess(items[i], items[j]) })
+	return items, nil
+}
+
+func (m *MemoryStore) ListReplies(ctx context.Context, arg ListRepliesParams) ([]Chirp, error) {
+	return m.listChirps(byParent(arg.ID), arg.CursorCreatedAt, arg.CursorID, arg.Limit, false), nil
+}
+
+func byParent(id uuid.UUID) func(Chirp) bool {
+	return func(c Chirp) bool {
+		return c.InReplyToID.Valid && c.InReplyToID.UUID == id
+	}
+}
+
+func (m *MemoryStore) ListDescendants(ctx context.Context, arg ListDescendantsParams) ([]Chirp, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	first := Chirp{CreatedAt: arg.FirstCreatedAt, ID: arg.FirstID}
+	last := Chirp{CreatedAt: arg.LastCreatedAt, ID: arg.LastID}
+
+	var level []Chirp
+	for _, c := range m.chirps {
+		if byParent(arg.ID)(c) && !chirpLess(c, first) && !chirpLess(last, c) {
+			level = append(level, c)
+		}
+	}
+	var items []Chirp
+	for depth := 1; depth <= int(arg.MaxDepth) && len(level) > 0; depth++ {
+		if depth > 1 {
+			items = append(items, level...)
+		}
+		var next []Chirp
+		for _, parent := range level {
+			for _, c := range m.chirps {
+				if byParent(parent.ID)(c) {
+					next = append(next, c)
+				}
+			}
+		}
+		level = next
+	}
+
+	sort.Slice(items, func(i, j int) bool { return chirpLess(items[i], items[j]) })
+	if len(items) > int(arg.Limit) {
+		items = items[:arg.Limit]
+	}
+	return items, nil
+}
+
+// Likes
+
+func (m *MemoryStore) LikeChirp(ctx context.Context, arg LikeChirpParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, userOK := m.users[arg.UserID]
+	_, chirpOK := m.chirps[arg.ChirpID]
+	if !userOK || !chirpOK {
+		return 0, fmt.Errorf("insert or update on table \"likes\" violates foreign key constraint")
+	}
+	k := likeKey{arg.UserID, arg.ChirpID}
+	if _, ok := m.likes[k]; ok {
+		return 0, nil // ON CONFLICT DO NOTHING
+	}
+	m.likes[k] = Like{UserID: arg.UserID, ChirpID: arg.ChirpID, CreatedAt: now()}
+	return 1, nil
+}
+
+func (m *MemoryStore) UnlikeChirp(ctx context.Context, arg UnlikeChirpParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	k := likeKey{arg.UserID, arg.ChirpID}
+	if _, ok := m.likes[k]; !ok {
+		return 0, nil
+	}
+	delete(m.likes, k)
+	return 1, nil
+}
+
+func (m *MemoryStore) CountLikesByIDs(ctx context.Context, ids []uuid.UUID) ([]CountLikesByIDsRow, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	want := map[uuid.UUID]bool{}
+	for _, id := range ids {
+		want[id] = true
+	}
+	counts := map[uuid.UUID]int64{}
+	for k := range m.likes {
+		if want[k.chirp] {
+			counts[k.chirp]++
+		}
+	}
+	var rows []CountLikesByIDsRow
+	for id, n := range counts {
+		rows = append(rows, CountLikesByIDsRow{ChirpID: id, LikeCount: n})
+	}
+	return rows, nil
+}
+
+func (m *MemoryStore) ListLikedChirpIDs(ctx context.Context, arg ListLikedChirpIDsParams) ([]uuid.UUID, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var ids []uuid.UUID
+	for _, id := range arg.Ids {
+		if _, ok := m.likes[likeKey{arg.UserID, id}]; ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (m *MemoryStore) ListLikesByUser(ctx context.Context, userID uuid.UUID) ([]Like, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var items []Like
+	for _, l := range m.likes {
+		if l.UserID == userID {
+			items = append(items, l)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].CreatedAt.Before(items[j].CreatedAt) })
+	return items, nil
+}
+
+// ListLikedChirps returns the liked chirps newest like first, keyed by
+// (likes.created_at, chirp id) like the keyset query.
+func (m *MemoryStore) ListLikedChirps(ctx context.Context, arg ListLikedChirpsParams) ([]ListLikedChirpsRow, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	less := func(a, b ListLikedChirpsRow) bool {
+		if !a.LikedAt.Equal(b.LikedAt) {
+			return a.LikedAt.Before(b.LikedAt)
+		}
+		return a.Chirp.ID.String() < b.Chirp.ID.String()
+	}
+	cursor := ListLikedChirpsRow{LikedAt: arg.CursorCreatedAt.Time, Chirp: Chirp{ID: arg.CursorID.UUID}}
+
+	var items []ListLikedChirpsRow
+	for k, l := range m.likes {
+		if k.user != arg.UserID {
+			continue
+		}
+		c, ok := m.chirps[k.chirp]
+		if !ok || c.DeletedAt.Valid || m.pendingDeletion(c.UserID) {
+			continue
+		}
+		row := ListLikedChirpsRow{Chirp: c, LikedAt: l.CreatedAt}
+		if arg.CursorCreatedAt.Valid && !less(row, cursor) {
+			continue
+		}
+		items = append(items, row)
+	}
+
+	sort.Slice(items, func(i, j int) bool { return less(items[j], items[i]) })
+	if len(items) > int(arg.Limit) {
+		items = items[:arg.Limit]
+	}
+	return items, nil
+}
+
+// Rechirps
+
+func (m *MemoryStore) CreateRechirp(ctx context.Context, arg CreateRechirpParams) (Chirp, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, userOK := m.users[arg.UserID]
+	_, chirpOK := m.chirps[arg.RechirpOfID]
+	if !userOK || !chirpOK {
+		return Chirp{}, fmt.Errorf("insert or update on table \"chirps\" violates foreign key constraint")
+	}
+	for _, c := range m.chirps {
+		if c.UserID == arg.UserID && c.RechirpOfID.Valid && c.RechirpOfID.UUID == arg.RechirpOfID {
+			return Chirp{}, sql.ErrNoRows // ON CONFLICT DO NOTHING
+		}
+	}
+
+	t := now()
+	chirp := Chirp{
+		ID:          uuid.New(),
+		CreatedAt:   t,
+		UpdatedAt:   t,
+		UserID:      arg.UserID,
+		RechirpOfID: uuid.NullUUID{UUID: arg.RechirpOfID, Valid: true},
+	}
+	chirp.ConversationID = chirp.ID
+	m.chirps[chirp.ID] = chirp
+	return chirp, nil
+}
+
+func (m *MemoryStore) GetRechirp(ctx context.Context, arg GetRechirpParams) (Chirp, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, c := range m.chirps {
+		if c.UserID == arg.UserID && c.RechirpOfID.Valid && c.RechirpOfID.UUID == arg.RechirpOfID {
+			return c, nil
+		}
+	}
+	return Chirp{}, sql.ErrNoRows
+}
+
+func (m *MemoryStore) DeleteRechirp(ctx context.Context, arg DeleteRechirpParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var n int64
+	for id, c := range m.chirps {
+		if c.UserID == arg.UserID && c.RechirpOfID.Valid && c.RechirpOfID.UUID == arg.RechirpOfID {
+			m.deleteChirp(id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *MemoryStore) DeleteRechirpsOf(ctx context.Context, rechirpOfID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, c := range m.chirps {
+		if c.RechirpOfID.Valid && c.RechirpOfID.UUID == rechirpOfID {
+			m.deleteChirp(id)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) CountRechirpsByIDs(ctx context.Context, ids []uuid.UUID) ([]CountRechirpsByIDsRow, error) {
+	counts := m.countReferences(ids, func(c Chirp) uuid.NullUUID { return c.RechirpOfID })
+	var rows []CountRechirpsByIDsRow
+	for id, n := range counts {
+		rows = append(rows, CountRechirpsByIDsRow{RechirpOfID: uuid.NullUUID{UUID: id, Valid: true}, RechirpCount: n})
+	}
+	return rows, nil
+}
+
+func (m *MemoryStore) CountQuotesByIDs(ctx context.Context, ids []uuid.UUID) ([]CountQuotesByIDsRow, error) {
+	counts := m.countReferences(ids, func(c Chirp) uuid.NullUUID {
+		if c.DeletedAt.Valid {
+			return uuid.NullUUID{}
+		}
+		return c.QuotedID
+	})
+	var rows []CountQuotesByIDsRow
+	for id, n := range counts {
+		rows = append(rows, CountQuotesByIDsRow{QuotedID: uuid.NullUUID{UUID: id, Valid: true}, QuoteCount: n})
+	}
+	return rows, nil
+}
+
+// countReferences counts, for each of ids, the chirps whose ref is that ID.
+func (m *MemoryStore) countReferences(ids []uuid.UUID, ref func(Chirp) uuid.NullUUID) map[uuid.UUID]int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	want := map[uuid.UUID]bool{}
+	for _, id := range ids {
+		want[id] = true
+	}
+	counts := map[uuid.UUID]int64{}
+	for _, c := range m.chirps {
+		if r := ref(c); r.Valid && want[r.UUID] {
+			counts[r.UUID]++
+		}
+	}
+	return counts
+}
+
+func (m *MemoryStore) ListChirpsByIDs(ctx context.Context, ids []uuid.UUID) ([]Chirp, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var items []Chirp
+	for _, id := range ids {
+		if c, ok := m.chirps[id]; ok {
+			items = append(items, c)
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,191 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMemoryStore_Users(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStore()
+
+	user, err := m.CreateUser(ctx, CreateUserParams{Email: "a@example.com", HashedPassword: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser returned error: %v", err)
+	}
+
+	if _, err := m.CreateUser(ctx, CreateUserParams{Email: "a@example.com", HashedPassword: "hash"}); !IsUniqueViolation(err) {
+		t.Fatalf("CreateUser with a duplicate email returned %v, want a unique violation", err)
+	}
+
+	got, err := m.GetUserFromEmail(ctx, "a@example.com")
+	if err != nil {
+		t.Fatalf("GetUserFromEmail returned error: %v", err)
+	}
+	if got.ID != user.ID {
+		t.Errorf("GetUserFromEmail returned id %v, want %v", got.ID, user.ID)
+	}
+
+	if _, err := m.GetUserFromUserID(ctx, uuid.New()); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUserFromUserID returned %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestMemoryStore_DeleteAllUsersCascades(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStore()
+
+	user, _ := m.CreateUser(ctx, CreateUserParams{Email: "a@example.com", HashedPassword: "hash"})
+	chirp, err := m.CreateChirp(ctx, CreateChirpParams{Body: "hello", UserID: user.ID})
+	if err != nil {
+		t.Fatalf("CreateChirp returned error: %v", err)
+	}
+	if _, err := m.StoreRefreshToken(ctx, StoreRefreshTokenParams{TokenHash: "t", UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("StoreRefreshToken returned error: %v", err)
+	}
+
+	if err := m.DeleteAllUsers(ctx); err != nil {
+		t.Fatalf("DeleteAllUsers returned error: %v", err)
+	}
+
+	if _, err := m.GetChirp(ctx, chirp.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetChirp after DeleteAllUsers returned %v, want sql.ErrNoRows", err)
+	}
+	if _, err := m.GetRefreshTokenFromToken(ctx, "t"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetRefreshTokenFromToken after DeleteAllUsers returned %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestMemoryStore_PurgeDeletedUsers(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStore()
+
+	gone, _ := m.CreateUser(ctx, CreateUserParams{Email: "a@example.com", HashedPassword: "hash"})
+	kept, _ := m.CreateUser(ctx, CreateUserParams{Email: "b@example.com", HashedPassword: "hash"})
+	chirp, _ := m.CreateChirp(ctx, CreateChirpParams{Body: "hello", UserID: gone.ID})
+	if err := m.CreateWebhookEvent(ctx, CreateWebhookEventParams{UserID: gone.ID, Event: "user.upgraded", Payload: "{}"}); err != nil {
+		t.Fatalf("CreateWebhookEvent returned error: %v", err)
+	}
+	m.ScheduleUserDeletion(ctx, ScheduleUserDeletionParams{DeleteAfter: sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}, ID: gone.ID})
+	m.ScheduleUserDeletion(ctx, ScheduleUserDeletionParams{DeleteAfter: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}, ID: kept.ID})
+
+	rows, err := m.PurgeDeletedUsers(ctx, time.Now())
+	if err != nil || len(rows) != 1 || rows[0].ID != gone.ID {
+		t.Fatalf("PurgeDeletedUsers = %+v, %v; want only the expired user", rows, err)
+	}
+	if _, err := m.GetChirp(ctx, chirp.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("chirp of a purged user still exists")
+	}
+	if events, _ := m.ListWebhookEventsByUser(ctx, gone.ID); len(events) != 0 {
+		t.Errorf("webhook events of a purged user still exist")
+	}
+	if _, err := m.GetUserFromUserID(ctx, kept.ID); err != nil {
+		t.Errorf("user still in the grace period was purged: %v", err)
+	}
+}
+
+func TestMemoryStore_ListChirpsKeyset(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStore()
+
+	alice, _ := m.CreateUser(ctx, CreateUserParams{Email: "alice@example.com", HashedPassword: "hash"})
+	bob, _ := m.CreateUser(ctx, CreateUserParams{Email: "bob@example.com", HashedPassword: "hash"})
+
+	for i := 0; i < 5; i++ {
+		if _, err := m.CreateChirp(ctx, CreateChirpParams{Body: "alice", UserID: alice.ID}); err != nil {
+			t.Fatalf("CreateChirp returned error: %v", err)
+		}
+		if _, err := m.CreateChirp(ctx, CreateChirpParams{Body: "bob", UserID: bob.ID}); err != nil {
+			t.Fatalf("CreateChirp returned error: %v", err)
+		}
+	}
+
+	all, _ := m.ListChirpsAsc(ctx, ListChirpsAscParams{Limit: 100})
+	if len(all) != 10 {
+		t.Fatalf("ListChirpsAsc returned %d chirps, want 10", len(all))
+	}
+
+	// Walk the listing two at a time in both directions and compare with the full result.
+	var asc []Chirp
+	var cursor Chirp
+	for {
+		arg := ListChirpsAscParams{Limit: 2}
+		if len(asc) > 0 {
+			arg.CursorCreatedAt = sql.NullTime{Time: cursor.CreatedAt, Valid: true}
+			arg.CursorID = uuid.NullUUID{UUID: cursor.ID, Valid: true}
+		}
+		page, _ := m.ListChirpsAsc(ctx, arg)
+		if len(page) == 0 {
+			break
+		}
+		asc = append(asc, page...)
+		cursor = page[len(page)-1]
+	}
+	for i := range all {
+		if asc[i].ID != all[i].ID {
+			t.Fatalf("ascending page walk differs at %d", i)
+		}
+	}
+
+	var desc []Chirp
+	for {
+		arg := ListChirpsDescParams{Limit: 3}
+		if len(desc) > 0 {
+			arg.CursorCreatedAt = sql.NullTime{Time: cursor.CreatedAt, Valid: true}
+			arg.CursorID = uuid.NullUUID{UUID: cursor.ID, Valid: true}
+		}
+		page, _ := m.ListChirpsDesc(ctx, arg)
+		if len(page) == 0 {
+			break
+		}
+		desc = append(desc, page...)
+		cursor = page[len(page)-1]
+	}
+	for i := range all {
+		if desc[i].ID != all[len(all)-1-i].ID {
+			t.Fatalf("descending page walk differs at %d", i)
+		}
+	}
+
+	byBob, _ := m.ListChirpsAsc(ctx, ListChirpsAscParams{AuthorID: uuid.NullUUID{UUID: bob.ID, Valid: true}, Limit: 100})
+	if len(byBob) != 5 {
+		t.Fatalf("ListChirpsAsc by author returned %d chirps, want 5", len(byBob))
+	}
+	for _, c := range byBob {
+		if c.UserID != bob.ID {
+			t.Errorf("ListChirpsAsc by author returned chirp of %v", c.UserID)
+		}
+	}
+}
+
+func TestMemoryStore_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStore()
+
+	user, _ := m.CreateUser(ctx, CreateUserParams{Email: "a@example.com", HashedPassword: "hash"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			m.CreateChirp(ctx, CreateChirpParams{Body: "hello", UserID: user.ID})
+		}()
+		go func() {
+			defer wg.Done()
+			m.ListChirpsDesc(ctx, ListChirpsDescParams{Limit: 10})
+		}()
+	}
+	wg.Wait()
+
+	all, _ := m.ListChirpsAsc(ctx, ListChirpsAscParams{Limit: 100})
+	if len(all) != 50 {
+		t.Errorf("ListChirpsAsc returned %d chirps, want 50", len(all))
+	}
+}
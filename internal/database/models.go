@@ -12,27 +12,123 @@ import (
 )
 
 type Chirp struct {
-	ID        uuid.UUID
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Body           string
+	UserID         uuid.UUID
+	FannedOutAt    sql.NullTime
+	InReplyToID    uuid.NullUUID
+	ConversationID uuid.UUID
+	DeletedAt      sql.NullTime
+	RechirpOfID    uuid.NullUUID
+	QuotedID       uuid.NullUUID
+}
+
+type EmailVerificationToken struct {
+	TokenHash string
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	Email     string
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+}
+
+type Follow struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+	CreatedAt  time.Time
+}
+
+type Like struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type LoginAttempt struct {
+	Key          string
+	Failures     int32
+	LastFailedAt time.Time
+	LockedUntil  sql.NullTime
+}
+
+type MfaRecoveryCode struct {
+	CodeHash  string
 	CreatedAt time.Time
-	UpdatedAt time.Time
-	Body      string
 	UserID    uuid.UUID
+	UsedAt    sql.NullTime
+}
+
+type PasswordResetAttempt struct {
+	Key             string
+	Requests        int32
+	LastRequestedAt time.Time
+	LockedUntil     sql.NullTime
+}
+
+type PasswordResetToken struct {
+	TokenHash string
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
 }
 
 type RefreshToken struct {
-	Token     string
+	TokenHash        string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	UserID           uuid.UUID
+	ExpiresAt        time.Time
+	RevokedAt        sql.NullTime
+	FamilyID         uuid.UUID
+	ReplacedBy       sql.NullString
+	UserAgent        string
+	IpAddress        string
+	SessionStartedAt time.Time
+}
+
+type TimelineEntry struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	AuthorID  uuid.UUID
 	CreatedAt time.Time
-	UpdatedAt time.Time
+}
+
+type UsedMfaToken struct {
+	Jti       string
 	UserID    uuid.UUID
 	ExpiresAt time.Time
-	RevokedAt sql.NullTime
 }
 
 type User struct {
-	ID             uuid.UUID
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	Email          string
-	HashedPassword string
-	IsChirpyRed    bool
+	ID               uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Email            string
+	HashedPassword   string
+	IsChirpyRed      bool
+	TokensValidAfter sql.NullTime
+	Role             string
+	TotpSecret       sql.NullString
+	TotpEnabledAt    sql.NullTime
+	EmailVerifiedAt  sql.NullTime
+	Handle           sql.NullString
+	DisplayName      string
+	Bio              string
+	AvatarUrl        string
+	AvatarKey        string
+	HeaderUrl        string
+	HeaderKey        string
+	DeleteAfter      sql.NullTime
+	TotpLastStep     sql.NullInt64
+}
+
+type WebhookEvent struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	Event     string
+	Payload   string
 }
@@ -0,0 +1,116 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: password_reset.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const consumePasswordResetToken = `-- name: ConsumePasswordResetToken :one
+UPDATE password_reset_tokens
+SET used_at = $1::timestamp
+WHERE token_hash = $2 AND used_at IS NULL AND expires_at > $1::timestamp
+RETURNING user_id
+`
+
+type ConsumePasswordResetTokenParams struct {
+	Now       time.Time
+	TokenHash string
+}
+
+func (q *Queries) ConsumePasswordResetToken(ctx context.Context, arg ConsumePasswordResetTokenParams) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, consumePasswordResetToken, arg.Now, arg.TokenHash)
+	var user_id uuid.UUID
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
+const createPasswordResetToken = `-- name: CreatePasswordResetToken :exec
+INSERT INTO password_reset_tokens (token_hash, created_at, user_id, expires_at, used_at)
+VALUES ($1, NOW(), $2, $3, NULL)
+`
+
+type CreatePasswordResetTokenParams struct {
+	TokenHash string
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) error {
+	_, err := q.db.ExecContext(ctx, createPasswordResetToken, arg.TokenHash, arg.UserID, arg.ExpiresAt)
+	return err
+}
+
+const getPasswordResetTokenUser = `-- name: GetPasswordResetTokenUser :one
+SELECT user_id FROM password_reset_tokens
+WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2::timestamp
+`
+
+type GetPasswordResetTokenUserParams struct {
+	TokenHash string
+	Now       time.Time
+}
+
+// Looks up a usable token without consuming it.
+func (q *Queries) GetPasswordResetTokenUser(ctx context.Context, arg GetPasswordResetTokenUserParams) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, getPasswordResetTokenUser, arg.TokenHash, arg.Now)
+	var user_id uuid.UUID
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
+const invalidatePasswordResetTokensForUser = `-- name: InvalidatePasswordResetTokensForUser :exec
+UPDATE password_reset_tokens
+SET used_at = NOW()
+WHERE user_id = $1 AND used_at IS NULL
+`
+
+func (q *Queries) InvalidatePasswordResetTokensForUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, invalidatePasswordResetTokensForUser, userID)
+	return err
+}
+
+const rehashUserPassword = `-- name: RehashUserPassword :execrows
+UPDATE users
+SET hashed_password = $1
+WHERE id = $2 AND hashed_password = $3
+`
+
+type RehashUserPasswordParams struct {
+	NewHash string
+	ID      uuid.UUID
+	OldHash string
+}
+
+// Compare-and-set: a password changed since the old hash was read is left alone.
+func (q *Queries) RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rehashUserPassword, arg.NewHash, arg.ID, arg.OldHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setUserPassword = `-- name: SetUserPassword :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    hashed_password = $1
+WHERE id = $2
+`
+
+type SetUserPasswordParams struct {
+	HashedPassword string
+	ID             uuid.UUID
+}
+
+func (q *Queries) SetUserPassword(ctx context.Context, arg SetUserPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, setUserPassword, arg.HashedPassword, arg.ID)
+	return err
+}
@@ -0,0 +1,76 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: password_reset_attempts.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const getPasswordResetAttempt = `-- name: GetPasswordResetAttempt :one
+SELECT key, requests, last_requested_at, locked_until FROM password_reset_attempts
+WHERE key = $1
+`
+
+func (q *Queries) GetPasswordResetAttempt(ctx context.Context, key string) (PasswordResetAttempt, error) {
+	row := q.db.QueryRowContext(ctx, getPasswordResetAttempt, key)
+	var i PasswordResetAttempt
+	err := row.Scan(
+		&i.Key,
+		&i.Requests,
+		&i.LastRequestedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const lockPasswordReset = `-- name: LockPasswordReset :exec
+UPDATE password_reset_attempts
+SET locked_until = $1
+WHERE key = $2
+`
+
+type LockPasswordResetParams struct {
+	LockedUntil sql.NullTime
+	Key         string
+}
+
+func (q *Queries) LockPasswordReset(ctx context.Context, arg LockPasswordResetParams) error {
+	_, err := q.db.ExecContext(ctx, lockPasswordReset, arg.LockedUntil, arg.Key)
+	return err
+}
+
+const recordPasswordResetRequest = `-- name: RecordPasswordResetRequest :one
+INSERT INTO password_reset_attempts (key, requests, last_requested_at, locked_until)
+VALUES ($1, 1, $2::timestamp, NULL)
+ON CONFLICT (key) DO UPDATE SET
+    requests = CASE
+        WHEN password_reset_attempts.last_requested_at < $3 THEN 1
+        ELSE password_reset_attempts.requests + 1
+    END,
+    last_requested_at = $2::timestamp
+RETURNING key, requests, last_requested_at, locked_until
+`
+
+type RecordPasswordResetRequestParams struct {
+	Key         string
+	Now         time.Time
+	ResetBefore time.Time
+}
+
+// The counter starts over when the previous request is older than reset_before.
+func (q *Queries) RecordPasswordResetRequest(ctx context.Context, arg RecordPasswordResetRequestParams) (PasswordResetAttempt, error) {
+	row := q.db.QueryRowContext(ctx, recordPasswordResetRequest, arg.Key, arg.Now, arg.ResetBefore)
+	var i PasswordResetAttempt
+	err := row.Scan(
+		&i.Key,
+		&i.Requests,
+		&i.LastRequestedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
@@ -0,0 +1,147 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Querier interface {
+	// Copies the latest chirps of a newly followed account into the follower's timeline.
+	BackfillTimeline(ctx context.Context, arg BackfillTimelineParams) error
+	CancelUserDeletion(ctx context.Context, id uuid.UUID) error
+	ClearLoginAttempts(ctx context.Context, key string) error
+	ConsumeEmailVerificationToken(ctx context.Context, arg ConsumeEmailVerificationTokenParams) (ConsumeEmailVerificationTokenRow, error)
+	ConsumePasswordResetToken(ctx context.Context, arg ConsumePasswordResetTokenParams) (uuid.UUID, error)
+	CountFollowers(ctx context.Context, followeeID uuid.UUID) (int64, error)
+	CountFollowing(ctx context.Context, followerID uuid.UUID) (int64, error)
+	CountLikesByIDs(ctx context.Context, ids []uuid.UUID) ([]CountLikesByIDsRow, error)
+	CountQuotesByIDs(ctx context.Context, ids []uuid.UUID) ([]CountQuotesByIDsRow, error)
+	CountRechirpsByIDs(ctx context.Context, ids []uuid.UUID) ([]CountRechirpsByIDsRow, error)
+	CountReplies(ctx context.Context, id uuid.UUID) (int64, error)
+	CountRepliesByIDs(ctx context.Context, ids []uuid.UUID) ([]CountRepliesByIDsRow, error)
+	// A chirp without a conversation_id starts its own, named by its ID.
+	CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error)
+	CreateEmailVerificationToken(ctx context.Context, arg CreateEmailVerificationTokenParams) error
+	CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) error
+	// Returns no row if the user has already rechirped the chirp.
+	CreateRechirp(ctx context.Context, arg CreateRechirpParams) (Chirp, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateWebhookEvent(ctx context.Context, arg CreateWebhookEventParams) error
+	DeleteAllTimelineEntries(ctx context.Context) error
+	DeleteAllUsers(ctx context.Context) error
+	DeleteChirp(ctx context.Context, id uuid.UUID) error
+	DeleteExpiredMFATokens(ctx context.Context, expiresAt time.Time) error
+	DeleteRechirp(ctx context.Context, arg DeleteRechirpParams) (int64, error)
+	// Removes the rechirps of a chirp that is kept as a tombstone.
+	DeleteRechirpsOf(ctx context.Context, rechirpOfID uuid.UUID) error
+	DeleteRecoveryCodesForUser(ctx context.Context, userID uuid.UUID) error
+	DeleteTimelineEntriesByAuthor(ctx context.Context, arg DeleteTimelineEntriesByAuthorParams) error
+	DeleteTimelineEntriesForChirp(ctx context.Context, chirpID uuid.UUID) error
+	DisableUserTOTP(ctx context.Context, id uuid.UUID) error
+	EnableUserTOTP(ctx context.Context, id uuid.UUID) error
+	// Copies a chirp into the timeline of every follower of its author.
+	FanOutChirp(ctx context.Context, id uuid.UUID) (int64, error)
+	FollowUser(ctx context.Context, arg FollowUserParams) (int64, error)
+	GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error)
+	GetLoginAttempt(ctx context.Context, key string) (LoginAttempt, error)
+	GetPasswordResetAttempt(ctx context.Context, key string) (PasswordResetAttempt, error)
+	// Looks up a usable token without consuming it.
+	GetPasswordResetTokenUser(ctx context.Context, arg GetPasswordResetTokenUserParams) (uuid.UUID, error)
+	GetRechirp(ctx context.Context, arg GetRechirpParams) (Chirp, error)
+	GetRefreshTokenFromToken(ctx context.Context, tokenHash string) (RefreshToken, error)
+	GetUserFromEmail(ctx context.Context, email string) (User, error)
+	GetUserFromHandle(ctx context.Context, handle sql.NullString) (User, error)
+	GetUserFromUserID(ctx context.Context, id uuid.UUID) (User, error)
+	InvalidatePasswordResetTokensForUser(ctx context.Context, userID uuid.UUID) error
+	LikeChirp(ctx context.Context, arg LikeChirpParams) (int64, error)
+	ListActiveRefreshTokensByUser(ctx context.Context, arg ListActiveRefreshTokensByUserParams) ([]RefreshToken, error)
+	// The parents of a chirp up to max_depth levels. A parent is older than its
+	// replies, so oldest first is root first.
+	ListChirpAncestors(ctx context.Context, arg ListChirpAncestorsParams) ([]Chirp, error)
+	// Chirps of accounts waiting for deletion are hidden, like their profiles.
+	ListChirpsAsc(ctx context.Context, arg ListChirpsAscParams) ([]Chirp, error)
+	ListChirpsByIDs(ctx context.Context, ids []uuid.UUID) ([]Chirp, error)
+	ListChirpsDesc(ctx context.Context, arg ListChirpsDescParams) ([]Chirp, error)
+	// The replies below one page of ListReplies, which is the replies to id between the keys
+	// (first_created_at, first_id) and (last_created_at, last_id), up to max_depth levels in all
+	// counting the page, oldest first. The page itself is not returned, so the limit only cuts
+	// deeper levels. A reply is never older than its parent, so it cannot cut a parent off its replies.
+	ListDescendants(ctx context.Context, arg ListDescendantsParams) ([]Chirp, error)
+	// Newest follows first; the cursor is (follows.created_at, follower id).
+	ListFollowers(ctx context.Context, arg ListFollowersParams) ([]ListFollowersRow, error)
+	// Newest follows first; the cursor is (follows.created_at, followee id).
+	ListFollowing(ctx context.Context, arg ListFollowingParams) ([]ListFollowingRow, error)
+	// Both directions, for the account export.
+	ListFollowsByUser(ctx context.Context, userID uuid.UUID) ([]Follow, error)
+	// Which of the chirps the user has liked.
+	ListLikedChirpIDs(ctx context.Context, arg ListLikedChirpIDsParams) ([]uuid.UUID, error)
+	// Newest likes first; the cursor is (likes.created_at, chirp id).
+	ListLikedChirps(ctx context.Context, arg ListLikedChirpsParams) ([]ListLikedChirpsRow, error)
+	// Every like, deleted chirps included, for the account export.
+	ListLikesByUser(ctx context.Context, userID uuid.UUID) ([]Like, error)
+	ListRefreshTokensByUser(ctx context.Context, userID uuid.UUID) ([]RefreshToken, error)
+	// Direct replies, oldest first.
+	ListReplies(ctx context.Context, arg ListRepliesParams) ([]Chirp, error)
+	// One page of the materialized timeline, newest first, without accounts waiting for deletion.
+	ListTimelineEntries(ctx context.Context, arg ListTimelineEntriesParams) ([]Chirp, error)
+	ListUnfannedChirps(ctx context.Context) ([]Chirp, error)
+	// Chirps of followed accounts that are not fanned out, newest first: those of
+	// accounts above the fan-out threshold and those still waiting for fan-out.
+	ListUnfannedTimelineChirps(ctx context.Context, arg ListUnfannedTimelineChirpsParams) ([]Chirp, error)
+	ListUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]User, error)
+	ListWebhookEventsByUser(ctx context.Context, userID uuid.UUID) ([]WebhookEvent, error)
+	LockLogin(ctx context.Context, arg LockLoginParams) error
+	LockPasswordReset(ctx context.Context, arg LockPasswordResetParams) error
+	MarkChirpFannedOut(ctx context.Context, id uuid.UUID) error
+	MarkEmailVerified(ctx context.Context, arg MarkEmailVerifiedParams) (int64, error)
+	// PATCH /api/users in one statement. The profile fields are always written; email and
+	// hashed_password only when not NULL. A new address has to be verified again.
+	PatchUser(ctx context.Context, arg PatchUserParams) error
+	// Everything the user owns goes with the row (ON DELETE CASCADE); uploads are returned for cleanup.
+	PurgeDeletedUsers(ctx context.Context, now time.Time) ([]PurgeDeletedUsersRow, error)
+	// Materializes every fanned-out chirp into the timelines of its author's current followers.
+	RebuildTimelineEntries(ctx context.Context) (int64, error)
+	// The counter starts over when the previous failure is older than reset_before.
+	RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginAttempt, error)
+	// The counter starts over when the previous request is older than reset_before.
+	RecordPasswordResetRequest(ctx context.Context, arg RecordPasswordResetRequestParams) (PasswordResetAttempt, error)
+	// Compare-and-set: a password changed since the old hash was read is left alone.
+	RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error)
+	RevokeAllRefreshTokensForUser(ctx context.Context, userID uuid.UUID) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeUserRefreshTokenFamily(ctx context.Context, arg RevokeUserRefreshTokenFamilyParams) (int64, error)
+	RotateRefreshToken(ctx context.Context, arg RotateRefreshTokenParams) (int64, error)
+	ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) error
+	SetChirpyRed(ctx context.Context, arg SetChirpyRedParams) error
+	SetRevokeRefreshToken(ctx context.Context, tokenHash string) error
+	SetTokensValidAfter(ctx context.Context, arg SetTokensValidAfterParams) error
+	SetUserAvatar(ctx context.Context, arg SetUserAvatarParams) error
+	// A new address has to be verified again.
+	SetUserEmail(ctx context.Context, arg SetUserEmailParams) error
+	SetUserHeader(ctx context.Context, arg SetUserHeaderParams) error
+	SetUserPassword(ctx context.Context, arg SetUserPasswordParams) error
+	SetUserProfile(ctx context.Context, arg SetUserProfileParams) error
+	SetUserRole(ctx context.Context, arg SetUserRoleParams) error
+	SetUserTOTPSecret(ctx context.Context, arg SetUserTOTPSecretParams) error
+	StoreRefreshToken(ctx context.Context, arg StoreRefreshTokenParams) (RefreshToken, error)
+	// Keeps a deleted chirp that has replies, without its body.
+	TombstoneChirp(ctx context.Context, id uuid.UUID) error
+	UnfollowUser(ctx context.Context, arg UnfollowUserParams) (int64, error)
+	UnlikeChirp(ctx context.Context, arg UnlikeChirpParams) (int64, error)
+	// A new address has to be verified again.
+	UpdateUser(ctx context.Context, arg UpdateUserParams) error
+	UseMFAToken(ctx context.Context, arg UseMFATokenParams) (int64, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error)
+	// Accepts each time step once: a code for a step at or before the last accepted one is a replay.
+	UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (int64, error)
+}
+
+var _ Querier = (*Queries)(nil)
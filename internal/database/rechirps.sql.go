@@ -0,0 +1,214 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: rechirps.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const countQuotesByIDs = `-- name: CountQuotesByIDs :many
+SELECT quoted_id, COUNT(*) AS quote_count
+FROM chirps
+WHERE quoted_id = ANY($1::uuid[]) AND deleted_at IS NULL
+GROUP BY quoted_id
+`
+
+type CountQuotesByIDsRow struct {
+	QuotedID   uuid.NullUUID
+	QuoteCount int64
+}
+
+func (q *Queries) CountQuotesByIDs(ctx context.Context, ids []uuid.UUID) ([]CountQuotesByIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, countQuotesByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountQuotesByIDsRow
+	for rows.Next() {
+		var i CountQuotesByIDsRow
+		if err := rows.Scan(&i.QuotedID, &i.QuoteCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countRechirpsByIDs = `-- name: CountRechirpsByIDs :many
+SELECT rechirp_of_id, COUNT(*) AS rechirp_count
+FROM chirps
+WHERE rechirp_of_id = ANY($1::uuid[])
+GROUP BY rechirp_of_id
+`
+
+type CountRechirpsByIDsRow struct {
+	RechirpOfID  uuid.NullUUID
+	RechirpCount int64
+}
+
+func (q *Queries) CountRechirpsByIDs(ctx context.Context, ids []uuid.UUID) ([]CountRechirpsByIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, countRechirpsByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountRechirpsByIDsRow
+	for rows.Next() {
+		var i CountRechirpsByIDsRow
+		if err := rows.Scan(&i.RechirpOfID, &i.RechirpCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createRechirp = `-- name: CreateRechirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, conversation_id, rechirp_of_id)
+SELECT generated.id, NOW(), NOW(), '', $1::uuid, generated.id, $2::uuid
+FROM (SELECT gen_random_uuid() AS id) AS generated
+ON CONFLICT DO NOTHING
+RETURNING id, created_at, updated_at, body, user_id, fanned_out_at, in_reply_to_id, conversation_id, deleted_at, rechirp_of_id, quoted_id
+`
+
+type CreateRechirpParams struct {
+	UserID      uuid.UUID
+	RechirpOfID uuid.UUID
+}
+
+// Returns no row if the user has already rechirped the chirp.
+func (q *Queries) CreateRechirp(ctx context.Context, arg CreateRechirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, createRechirp, arg.UserID, arg.RechirpOfID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.FannedOutAt,
+		&i.InReplyToID,
+		&i.ConversationID,
+		&i.DeletedAt,
+		&i.RechirpOfID,
+		&i.QuotedID,
+	)
+	return i, err
+}
+
+const deleteRechirp = `-- name: DeleteRechirp :execrows
+DELETE FROM chirps
+WHERE user_id = $1::uuid AND rechirp_of_id = $2::uuid
+`
+
+type DeleteRechirpParams struct {
+	UserID      uuid.UUID
+	RechirpOfID uuid.UUID
+}
+
+func (q *Queries) DeleteRechirp(ctx context.Context, arg DeleteRechirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteRechirp, arg.UserID, arg.RechirpOfID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteRechirpsOf = `-- name: DeleteRechirpsOf :exec
+DELETE FROM chirps
+WHERE rechirp_of_id = $1::uuid
+`
+
+// Removes the rechirps of a chirp that is kept as a tombstone.
+func (q *Queries) DeleteRechirpsOf(ctx context.Context, rechirpOfID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteRechirpsOf, rechirpOfID)
+	return err
+}
+
+const getRechirp = `-- name: GetRechirp :one
+SELECT id, created_at, updated_at, body, user_id, fanned_out_at, in_reply_to_id, conversation_id, deleted_at, rechirp_of_id, quoted_id FROM chirps
+WHERE user_id = $1::uuid AND rechirp_of_id = $2::uuid
+`
+
+type GetRechirpParams struct {
+	UserID      uuid.UUID
+	RechirpOfID uuid.UUID
+}
+
+func (q *Queries) GetRechirp(ctx context.Context, arg GetRechirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getRechirp, arg.UserID, arg.RechirpOfID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.FannedOutAt,
+		&i.InReplyToID,
+		&i.ConversationID,
+		&i.DeletedAt,
+		&i.RechirpOfID,
+		&i.QuotedID,
+	)
+	return i, err
+}
+
+const listChirpsByIDs = `-- name: ListChirpsByIDs :many
+SELECT id, created_at, updated_at, body, user_id, fanned_out_at, in_reply_to_id, conversation_id, deleted_at, rechirp_of_id, quoted_id FROM chirps
+WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) ListChirpsByIDs(ctx context.Context, ids []uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.FannedOutAt,
+			&i.InReplyToID,
+			&i.ConversationID,
+			&i.DeletedAt,
+			&i.RechirpOfID,
+			&i.QuotedID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
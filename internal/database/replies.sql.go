@@ -0,0 +1,267 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: replies.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const countReplies = `-- name: CountReplies :one
+SELECT COUNT(*) FROM chirps
+WHERE in_reply_to_id = $1::uuid
+`
+
+func (q *Queries) CountReplies(ctx context.Context, id uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countReplies, id)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countRepliesByIDs = `-- name: CountRepliesByIDs :many
+SELECT in_reply_to_id, COUNT(*) AS reply_count
+FROM chirps
+WHERE in_reply_to_id = ANY($1::uuid[])
+GROUP BY in_reply_to_id
+`
+
+type CountRepliesByIDsRow struct {
+	InReplyToID uuid.NullUUID
+	ReplyCount  int64
+}
+
+func (q *Queries) CountRepliesByIDs(ctx context.Context, ids []uuid.UUID) ([]CountRepliesByIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, countRepliesByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountRepliesByIDsRow
+	for rows.Next() {
+		var i CountRepliesByIDsRow
+		if err := rows.Scan(&i.InReplyToID, &i.ReplyCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpAncestors = `-- name: ListChirpAncestors :many
+WITH RECURSIVE ancestors (id, in_reply_to_id, depth) AS (
+  SELECT parent.id, parent.in_reply_to_id, 1
+  FROM chirps AS parent
+  WHERE parent.id = (SELECT child.in_reply_to_id FROM chirps AS child WHERE child.id = $1::uuid)
+  UNION ALL
+  SELECT chirps.id, chirps.in_reply_to_id, ancestors.depth + 1
+  FROM chirps
+  JOIN ancestors ON chirps.id = ancestors.in_reply_to_id
+  WHERE ancestors.depth < $2::int
+)
+SELECT id, created_at, updated_at, body, user_id, fanned_out_at, in_reply_to_id, conversation_id, deleted_at, rechirp_of_id, quoted_id FROM chirps
+WHERE id IN (SELECT ancestors.id FROM ancestors)
+ORDER BY created_at ASC, id ASC
+`
+
+type ListChirpAncestorsParams struct {
+	ID       uuid.UUID
+	MaxDepth int32
+}
+
+// The parents of a chirp up to max_depth levels. A parent is older than its
+// replies, so oldest first is root first.
+func (q *Queries) ListChirpAncestors(ctx context.Context, arg ListChirpAncestorsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpAncestors, arg.ID, arg.MaxDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.FannedOutAt,
+			&i.InReplyToID,
+			&i.ConversationID,
+			&i.DeletedAt,
+			&i.RechirpOfID,
+			&i.QuotedID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDescendants = `-- name: ListDescendants :many
+WITH RECURSIVE descendants (id, depth) AS (
+  SELECT chirps.id, 1
+  FROM chirps
+  WHERE chirps.in_reply_to_id = $2::uuid
+    AND (chirps.created_at, chirps.id) >= ($3::timestamp, $4::uuid)
+    AND (chirps.created_at, chirps.id) <= ($5::timestamp, $6::uuid)
+  UNION ALL
+  SELECT chirps.id, descendants.depth + 1
+  FROM chirps
+  JOIN descendants ON chirps.in_reply_to_id = descendants.id
+  WHERE descendants.depth < $7::int
+)
+SELECT id, created_at, updated_at, body, user_id, fanned_out_at, in_reply_to_id, conversation_id, deleted_at, rechirp_of_id, quoted_id FROM chirps
+WHERE id IN (SELECT descendants.id FROM descendants WHERE descendants.depth > 1)
+ORDER BY created_at ASC, id ASC
+LIMIT $1
+`
+
+type ListDescendantsParams struct {
+	Limit          int32
+	ID             uuid.UUID
+	FirstCreatedAt time.Time
+	FirstID        uuid.UUID
+	LastCreatedAt  time.Time
+	LastID         uuid.UUID
+	MaxDepth       int32
+}
+
+// The replies below one page of ListReplies, which is the replies to id between the keys
+// (first_created_at, first_id) and (last_created_at, last_id), up to max_depth levels in all
+// counting the page, oldest first. The page itself is not returned, so the limit only cuts
+// deeper levels. A reply is never older than its parent, so it cannot cut a parent off its replies.
+func (q *Queries) ListDescendants(ctx context.Context, arg ListDescendantsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listDescendants,
+		arg.Limit,
+		arg.ID,
+		arg.FirstCreatedAt,
+		arg.FirstID,
+		arg.LastCreatedAt,
+		arg.LastID,
+		arg.MaxDepth,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.FannedOutAt,
+			&i.InReplyToID,
+			&i.ConversationID,
+			&i.DeletedAt,
+			&i.RechirpOfID,
+			&i.QuotedID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReplies = `-- name: ListReplies :many
+SELECT id, created_at, updated_at, body, user_id, fanned_out_at, in_reply_to_id, conversation_id, deleted_at, rechirp_of_id, quoted_id FROM chirps
+WHERE in_reply_to_id = $1::uuid
+  AND (
+    $2::timestamp IS NULL
+    OR (created_at, id) > ($2::timestamp, $3::uuid)
+  )
+ORDER BY created_at ASC, id ASC
+LIMIT $4
+`
+
+type ListRepliesParams struct {
+	ID              uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+// Direct replies, oldest first.
+func (q *Queries) ListReplies(ctx context.Context, arg ListRepliesParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listReplies,
+		arg.ID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.FannedOutAt,
+			&i.InReplyToID,
+			&i.ConversationID,
+			&i.DeletedAt,
+			&i.RechirpOfID,
+			&i.QuotedID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const tombstoneChirp = `-- name: TombstoneChirp :exec
+UPDATE chirps
+SET body = '', deleted_at = NOW(), updated_at = NOW()
+WHERE id = $1
+`
+
+// Keeps a deleted chirp that has replies, without its body.
+func (q *Queries) TombstoneChirp(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, tombstoneChirp, id)
+	return err
+}
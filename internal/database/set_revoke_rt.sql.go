@@ -12,10 +12,10 @@ import (
 const setRevokeRefreshToken = `-- name: SetRevokeRefreshToken :exec
 UPDATE refresh_tokens
 SET revoked_at = NOW(), updated_at = NOW()
-WHERE token = $1
+WHERE token_hash = $1
 `
 
-func (q *Queries) SetRevokeRefreshToken(ctx context.Context, token string) error {
-	_, err := q.db.ExecContext(ctx, setRevokeRefreshToken, token)
+func (q *Queries) SetRevokeRefreshToken(ctx context.Context, tokenHash string) error {
+	_, err := q.db.ExecContext(ctx, setRevokeRefreshToken, tokenHash)
 	return err
 }
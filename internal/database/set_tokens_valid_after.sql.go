@@ -0,0 +1,31 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: set_tokens_valid_after.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+const setTokensValidAfter = `-- name: SetTokensValidAfter :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    tokens_valid_after = $1
+WHERE id = $2
+`
+
+type SetTokensValidAfterParams struct {
+	TokensValidAfter sql.NullTime
+	ID               uuid.UUID
+}
+
+func (q *Queries) SetTokensValidAfter(ctx context.Context, arg SetTokensValidAfterParams) error {
+	_, err := q.db.ExecContext(ctx, setTokensValidAfter, arg.TokensValidAfter, arg.ID)
+	return err
+}
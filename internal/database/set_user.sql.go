@@ -16,7 +16,8 @@ UPDATE users
 SET 
     updated_at = NOW(),
     email = $1, 
-    hashed_password = $2 
+    hashed_password = $2,
+    email_verified_at = CASE WHEN email = $1 THEN email_verified_at END
 WHERE id = $3
 `
 
@@ -26,6 +27,7 @@ type UpdateUserParams struct {
 	ID             uuid.UUID
 }
 
+// A new address has to be verified again.
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
 	_, err := q.db.ExecContext(ctx, updateUser, arg.Email, arg.HashedPassword, arg.ID)
 	return err
@@ -0,0 +1,32 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: set_user_email.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const setUserEmail = `-- name: SetUserEmail :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    email = $1,
+    email_verified_at = NULL
+WHERE id = $2
+`
+
+type SetUserEmailParams struct {
+	Email string
+	ID    uuid.UUID
+}
+
+// A new address has to be verified again.
+func (q *Queries) SetUserEmail(ctx context.Context, arg SetUserEmailParams) error {
+	_, err := q.db.ExecContext(ctx, setUserEmail, arg.Email, arg.ID)
+	return err
+}
@@ -0,0 +1,223 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: chirps.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createChirp = `-- name: CreateChirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, in_reply_to_id, conversation_id, quoted_id)
+VALUES (
+  ?1,
+  ?2,
+  ?2,
+  ?3,
+  ?4,
+  ?5,
+  ?6,
+  ?7
+)
+RETURNING id, created_at, updated_at, body, user_id, fanned_out_at, in_reply_to_id, conversation_id, deleted_at, rechirp_of_id, quoted_id
+`
+
+type CreateChirpParams struct {
+	ID             uuid.UUID
+	Now            time.Time
+	Body           string
+	UserID         uuid.UUID
+	InReplyToID    uuid.NullUUID
+	ConversationID uuid.UUID
+	QuotedID       uuid.NullUUID
+}
+
+func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, createChirp,
+		arg.ID,
+		arg.Now,
+		arg.Body,
+		arg.UserID,
+		arg.InReplyToID,
+		arg.ConversationID,
+		arg.QuotedID,
+	)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.FannedOutAt,
+		&i.InReplyToID,
+		&i.ConversationID,
+		&i.DeletedAt,
+		&i.RechirpOfID,
+		&i.QuotedID,
+	)
+	return i, err
+}
+
+const deleteChirp = `-- name: DeleteChirp :exec
+DELETE FROM chirps
+WHERE id = ?
+`
+
+func (q *Queries) DeleteChirp(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteChirp, id)
+	return err
+}
+
+const getChirp = `-- name: GetChirp :one
+SELECT id, created_at, updated_at, body, user_id, fanned_out_at, in_reply_to_id, conversation_id, deleted_at, rechirp_of_id, quoted_id FROM chirps
+WHERE id = ?
+`
+
+func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirp, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.FannedOutAt,
+		&i.InReplyToID,
+		&i.ConversationID,
+		&i.DeletedAt,
+		&i.RechirpOfID,
+		&i.QuotedID,
+	)
+	return i, err
+}
+
+const listChirpsAsc = `-- name: ListChirpsAsc :many
+SELECT id, created_at, updated_at, body, user_id, fanned_out_at, in_reply_to_id, conversation_id, deleted_at, rechirp_of_id, quoted_id FROM chirps
+WHERE chirps.deleted_at IS NULL
+  AND (CAST(?1 AS TEXT) IS NULL OR chirps.user_id = CAST(?1 AS TEXT))
+  AND chirps.user_id NOT IN (SELECT users.id FROM users WHERE users.delete_after IS NOT NULL)
+  AND (
+    chirps.created_at > ?2
+    OR (chirps.created_at = ?2 AND chirps.id > CAST(?3 AS TEXT))
+    OR ?2 IS NULL
+  )
+ORDER BY chirps.created_at ASC, chirps.id ASC
+LIMIT ?4
+`
+
+type ListChirpsAscParams struct {
+	AuthorID        sql.NullString
+	CursorCreatedAt sql.NullTime
+	CursorID        sql.NullString
+	Limit           int64
+}
+
+// Chirps of accounts waiting for deletion are hidden, like their profiles.
+func (q *Queries) ListChirpsAsc(ctx context.Context, arg ListChirpsAscParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsAsc,
+		arg.AuthorID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.FannedOutAt,
+			&i.InReplyToID,
+			&i.ConversationID,
+			&i.DeletedAt,
+			&i.RechirpOfID,
+			&i.QuotedID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsDesc = `-- name: ListChirpsDesc :many
+SELECT id, created_at, updated_at, body, user_id, fanned_out_at, in_reply_to_id, conversation_id, deleted_at, rechirp_of_id, quoted_id FROM chirps
+WHERE chirps.deleted_at IS NULL
+  AND (CAST(?1 AS TEXT) IS NULL OR chirps.user_id = CAST(?1 AS TEXT))
+  AND chirps.user_id NOT IN (SELECT users.id FROM users WHERE users.delete_after IS NOT NULL)
+  AND (
+    chirps.created_at < ?2
+    OR (chirps.created_at = ?2 AND chirps.id < CAST(?3 AS TEXT))
+    OR ?2 IS NULL
+  )
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT ?4
+`
+
+type ListChirpsDescParams struct {
+	AuthorID        sql.NullString
+	CursorCreatedAt sql.NullTime
+	CursorID        sql.NullString
+	Limit           int64
+}
+
+func (q *Queries) ListChirpsDesc(ctx context.Context, arg ListChirpsDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsDesc,
+		arg.AuthorID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.FannedOutAt,
+			&i.InReplyToID,
+			&i.ConversationID,
+			&i.DeletedAt,
+			&i.RechirpOfID,
+			&i.QuotedID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,62 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: email_verification_tokens.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const consumeEmailVerificationToken = `-- name: ConsumeEmailVerificationToken :one
+UPDATE email_verification_tokens
+SET used_at = ?1
+WHERE token_hash = ?2 AND used_at IS NULL AND expires_at > ?1
+RETURNING user_id, email
+`
+
+type ConsumeEmailVerificationTokenParams struct {
+	Now       sql.NullTime
+	TokenHash string
+}
+
+type ConsumeEmailVerificationTokenRow struct {
+	UserID uuid.UUID
+	Email  string
+}
+
+func (q *Queries) ConsumeEmailVerificationToken(ctx context.Context, arg ConsumeEmailVerificationTokenParams) (ConsumeEmailVerificationTokenRow, error) {
+	row := q.db.QueryRowContext(ctx, consumeEmailVerificationToken, arg.Now, arg.TokenHash)
+	var i ConsumeEmailVerificationTokenRow
+	err := row.Scan(&i.UserID, &i.Email)
+	return i, err
+}
+
+const createEmailVerificationToken = `-- name: CreateEmailVerificationToken :exec
+INSERT INTO email_verification_tokens (token_hash, created_at, user_id, email, expires_at, used_at)
+VALUES (?1, ?2, ?3, ?4, ?5, NULL)
+`
+
+type CreateEmailVerificationTokenParams struct {
+	TokenHash string
+	Now       time.Time
+	UserID    uuid.UUID
+	Email     string
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreateEmailVerificationToken(ctx context.Context, arg CreateEmailVerificationTokenParams) error {
+	_, err := q.db.ExecContext(ctx, createEmailVerificationToken,
+		arg.TokenHash,
+		arg.Now,
+		arg.UserID,
+		arg.Email,
+		arg.ExpiresAt,
+	)
+	return err
+}
@@ -0,0 +1,103 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: login_attempts.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const clearLoginAttempts = `-- name: ClearLoginAttempts :exec
+DELETE FROM login_attempts
+WHERE key = ?
+`
+
+func (q *Queries) ClearLoginAttempts(ctx context.Context, key string) error {
+	_, err := q.db.ExecContext(ctx, clearLoginAttempts, key)
+	return err
+}
+
+const ensureLoginAttempt = `-- name: EnsureLoginAttempt :exec
+INSERT INTO login_attempts (key, failures, last_failed_at, locked_until)
+VALUES (?1, 0, ?2, NULL)
+ON CONFLICT (key) DO NOTHING
+`
+
+type EnsureLoginAttemptParams struct {
+	Key string
+	Now time.Time
+}
+
+// SQLite part of RecordLoginFailure: create the row with no failures, then count in IncrementLoginFailures.
+func (q *Queries) EnsureLoginAttempt(ctx context.Context, arg EnsureLoginAttemptParams) error {
+	_, err := q.db.ExecContext(ctx, ensureLoginAttempt, arg.Key, arg.Now)
+	return err
+}
+
+const getLoginAttempt = `-- name: GetLoginAttempt :one
+SELECT "key", failures, last_failed_at, locked_until FROM login_attempts
+WHERE key = ?
+`
+
+func (q *Queries) GetLoginAttempt(ctx context.Context, key string) (LoginAttempt, error) {
+	row := q.db.QueryRowContext(ctx, getLoginAttempt, key)
+	var i LoginAttempt
+	err := row.Scan(
+		&i.Key,
+		&i.Failures,
+		&i.LastFailedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const incrementLoginFailures = `-- name: IncrementLoginFailures :one
+UPDATE login_attempts
+SET
+    failures = CASE
+        WHEN last_failed_at < ?1 THEN 1
+        ELSE failures + 1
+    END,
+    last_failed_at = ?2
+WHERE key = ?3
+RETURNING "key", failures, last_failed_at, locked_until
+`
+
+type IncrementLoginFailuresParams struct {
+	ResetBefore time.Time
+	Now         time.Time
+	Key         string
+}
+
+// The counter starts over when the previous failure is older than reset_before.
+func (q *Queries) IncrementLoginFailures(ctx context.Context, arg IncrementLoginFailuresParams) (LoginAttempt, error) {
+	row := q.db.QueryRowContext(ctx, incrementLoginFailures, arg.ResetBefore, arg.Now, arg.Key)
+	var i LoginAttempt
+	err := row.Scan(
+		&i.Key,
+		&i.Failures,
+		&i.LastFailedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const lockLogin = `-- name: LockLogin :exec
+UPDATE login_attempts
+SET locked_until = ?1
+WHERE key = ?2
+`
+
+type LockLoginParams struct {
+	LockedUntil sql.NullTime
+	Key         string
+}
+
+func (q *Queries) LockLogin(ctx context.Context, arg LockLoginParams) error {
+	_, err := q.db.ExecContext(ctx, lockLogin, arg.LockedUntil, arg.Key)
+	return err
+}
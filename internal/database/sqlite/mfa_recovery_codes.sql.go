@@ -0,0 +1,90 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: mfa_recovery_codes.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createRecoveryCode = `-- name: CreateRecoveryCode :exec
+INSERT INTO mfa_recovery_codes (code_hash, created_at, user_id, used_at)
+VALUES (?1, ?2, ?3, NULL)
+`
+
+type CreateRecoveryCodeParams struct {
+	CodeHash string
+	Now      time.Time
+	UserID   uuid.UUID
+}
+
+func (q *Queries) CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error {
+	_, err := q.db.ExecContext(ctx, createRecoveryCode, arg.CodeHash, arg.Now, arg.UserID)
+	return err
+}
+
+const deleteExpiredMFATokens = `-- name: DeleteExpiredMFATokens :exec
+DELETE FROM used_mfa_tokens
+WHERE expires_at < ?1
+`
+
+func (q *Queries) DeleteExpiredMFATokens(ctx context.Context, before time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredMFATokens, before)
+	return err
+}
+
+const deleteRecoveryCodesForUser = `-- name: DeleteRecoveryCodesForUser :exec
+DELETE FROM mfa_recovery_codes
+WHERE user_id = ?
+`
+
+func (q *Queries) DeleteRecoveryCodesForUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteRecoveryCodesForUser, userID)
+	return err
+}
+
+const useMFAToken = `-- name: UseMFAToken :execrows
+INSERT INTO used_mfa_tokens (jti, user_id, expires_at)
+VALUES (?1, ?2, ?3)
+ON CONFLICT (jti) DO NOTHING
+`
+
+type UseMFATokenParams struct {
+	Jti       string
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+}
+
+func (q *Queries) UseMFAToken(ctx context.Context, arg UseMFATokenParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, useMFAToken, arg.Jti, arg.UserID, arg.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const useRecoveryCode = `-- name: UseRecoveryCode :execrows
+UPDATE mfa_recovery_codes
+SET used_at = ?1
+WHERE code_hash = ?2 AND user_id = ?3 AND used_at IS NULL
+`
+
+type UseRecoveryCodeParams struct {
+	Now      sql.NullTime
+	CodeHash string
+	UserID   uuid.UUID
+}
+
+func (q *Queries) UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, useRecoveryCode, arg.Now, arg.CodeHash, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
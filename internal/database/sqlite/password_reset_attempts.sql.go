@@ -0,0 +1,93 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: password_reset_attempts.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const ensurePasswordResetAttempt = `-- name: EnsurePasswordResetAttempt :exec
+INSERT INTO password_reset_attempts (key, requests, last_requested_at, locked_until)
+VALUES (?1, 0, ?2, NULL)
+ON CONFLICT (key) DO NOTHING
+`
+
+type EnsurePasswordResetAttemptParams struct {
+	Key string
+	Now time.Time
+}
+
+// SQLite part of RecordPasswordResetRequest: create the row with no requests, then count in IncrementPasswordResetRequests.
+func (q *Queries) EnsurePasswordResetAttempt(ctx context.Context, arg EnsurePasswordResetAttemptParams) error {
+	_, err := q.db.ExecContext(ctx, ensurePasswordResetAttempt, arg.Key, arg.Now)
+	return err
+}
+
+const getPasswordResetAttempt = `-- name: GetPasswordResetAttempt :one
+SELECT "key", requests, last_requested_at, locked_until FROM password_reset_attempts
+WHERE key = ?
+`
+
+func (q *Queries) GetPasswordResetAttempt(ctx context.Context, key string) (PasswordResetAttempt, error) {
+	row := q.db.QueryRowContext(ctx, getPasswordResetAttempt, key)
+	var i PasswordResetAttempt
+	err := row.Scan(
+		&i.Key,
+		&i.Requests,
+		&i.LastRequestedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const incrementPasswordResetRequests = `-- name: IncrementPasswordResetRequests :one
+UPDATE password_reset_attempts
+SET
+    requests = CASE
+        WHEN last_requested_at < ?1 THEN 1
+        ELSE requests + 1
+    END,
+    last_requested_at = ?2
+WHERE key = ?3
+RETURNING "key", requests, last_requested_at, locked_until
+`
+
+type IncrementPasswordResetRequestsParams struct {
+	ResetBefore time.Time
+	Now         time.Time
+	Key         string
+}
+
+// The counter starts over when the previous request is older than reset_before.
+func (q *Queries) IncrementPasswordResetRequests(ctx context.Context, arg IncrementPasswordResetRequestsParams) (PasswordResetAttempt, error) {
+	row := q.db.QueryRowContext(ctx, incrementPasswordResetRequests, arg.ResetBefore, arg.Now, arg.Key)
+	var i PasswordResetAttempt
+	err := row.Scan(
+		&i.Key,
+		&i.Requests,
+		&i.LastRequestedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const lockPasswordReset = `-- name: LockPasswordReset :exec
+UPDATE password_reset_attempts
+SET locked_until = ?1
+WHERE key = ?2
+`
+
+type LockPasswordResetParams struct {
+	LockedUntil sql.NullTime
+	Key         string
+}
+
+func (q *Queries) LockPasswordReset(ctx context.Context, arg LockPasswordResetParams) error {
+	_, err := q.db.ExecContext(ctx, lockPasswordReset, arg.LockedUntil, arg.Key)
+	return err
+}
@@ -0,0 +1,89 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: password_reset_tokens.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const consumePasswordResetToken = `-- name: ConsumePasswordResetToken :one
+UPDATE password_reset_tokens
+SET used_at = ?1
+WHERE token_hash = ?2 AND used_at IS NULL AND expires_at > ?1
+RETURNING user_id
+`
+
+type ConsumePasswordResetTokenParams struct {
+	Now       sql.NullTime
+	TokenHash string
+}
+
+func (q *Queries) ConsumePasswordResetToken(ctx context.Context, arg ConsumePasswordResetTokenParams) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, consumePasswordResetToken, arg.Now, arg.TokenHash)
+	var user_id uuid.UUID
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
+const createPasswordResetToken = `-- name: CreatePasswordResetToken :exec
+INSERT INTO password_reset_tokens (token_hash, created_at, user_id, expires_at, used_at)
+VALUES (?1, ?2, ?3, ?4, NULL)
+`
+
+type CreatePasswordResetTokenParams struct {
+	TokenHash string
+	Now       time.Time
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) error {
+	_, err := q.db.ExecContext(ctx, createPasswordResetToken,
+		arg.TokenHash,
+		arg.Now,
+		arg.UserID,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const getPasswordResetTokenUser = `-- name: GetPasswordResetTokenUser :one
+SELECT user_id FROM password_reset_tokens
+WHERE token_hash = ?1 AND used_at IS NULL AND expires_at > ?2
+`
+
+type GetPasswordResetTokenUserParams struct {
+	TokenHash string
+	Now       time.Time
+}
+
+// Looks up a usable token without consuming it.
+func (q *Queries) GetPasswordResetTokenUser(ctx context.Context, arg GetPasswordResetTokenUserParams) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, getPasswordResetTokenUser, arg.TokenHash, arg.Now)
+	var user_id uuid.UUID
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
+const invalidatePasswordResetTokensForUser = `-- name: InvalidatePasswordResetTokensForUser :exec
+UPDATE password_reset_tokens
+SET used_at = ?1
+WHERE user_id = ?2 AND used_at IS NULL
+`
+
+type InvalidatePasswordResetTokensForUserParams struct {
+	Now    sql.NullTime
+	UserID uuid.UUID
+}
+
+func (q *Queries) InvalidatePasswordResetTokensForUser(ctx context.Context, arg InvalidatePasswordResetTokensForUserParams) error {
+	_, err := q.db.ExecContext(ctx, invalidatePasswordResetTokensForUser, arg.Now, arg.UserID)
+	return err
+}
@@ -0,0 +1,259 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: rechirps.sql
+
+package sqlite
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const countQuotesByIDs = `-- name: CountQuotesByIDs :many
+SELECT quoted_id, COUNT(*) AS quote_count
+FROM chirps
+WHERE quoted_id IN (/*SLICE:ids*/?) AND deleted_at IS NULL
+GROUP BY quoted_id
+`
+
+type CountQuotesByIDsRow struct {
+	QuotedID   uuid.NullUUID
+	QuoteCount int64
+}
+
+func (q *Queries) CountQuotesByIDs(ctx context.Context, ids []uuid.NullUUID) ([]CountQuotesByIDsRow, error) {
+	query := countQuotesByIDs
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountQuotesByIDsRow
+	for rows.Next() {
+		var i CountQuotesByIDsRow
+		if err := rows.Scan(&i.QuotedID, &i.QuoteCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countRechirpsByIDs = `-- name: CountRechirpsByIDs :many
+SELECT rechirp_of_id, COUNT(*) AS rechirp_count
+FROM chirps
+WHERE rechirp_of_id IN (/*SLICE:ids*/?)
+GROUP BY rechirp_of_id
+`
+
+type CountRechirpsByIDsRow struct {
+	RechirpOfID  uuid.NullUUID
+	RechirpCount int64
+}
+
+func (q *Queries) CountRechirpsByIDs(ctx context.Context, ids []uuid.NullUUID) ([]CountRechirpsByIDsRow, error) {
+	query := countRechirpsByIDs
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountRechirpsByIDsRow
+	for rows.Next() {
+		var i CountRechirpsByIDsRow
+		if err := rows.Scan(&i.RechirpOfID, &i.RechirpCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createRechirp = `-- name: CreateRechirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, conversation_id, rechirp_of_id)
+VALUES (
+  ?1,
+  ?2,
+  ?2,
+  '',
+  ?3,
+  ?1,
+  CAST(?4 AS TEXT)
+)
+ON CONFLICT DO NOTHING
+RETURNING id, created_at, updated_at, body, user_id, fanned_out_at, in_reply_to_id, conversation_id, deleted_at, rechirp_of_id, quoted_id
+`
+
+type CreateRechirpParams struct {
+	ID          uuid.UUID
+	Now         time.Time
+	UserID      uuid.UUID
+	RechirpOfID string
+}
+
+// Returns no row if the user has already rechirped the chirp.
+func (q *Queries) CreateRechirp(ctx context.Context, arg CreateRechirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, createRechirp,
+		arg.ID,
+		arg.Now,
+		arg.UserID,
+		arg.RechirpOfID,
+	)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.FannedOutAt,
+		&i.InReplyToID,
+		&i.ConversationID,
+		&i.DeletedAt,
+		&i.RechirpOfID,
+		&i.QuotedID,
+	)
+	return i, err
+}
+
+const deleteRechirp = `-- name: DeleteRechirp :execrows
+DELETE FROM chirps
+WHERE user_id = ?1 AND rechirp_of_id = CAST(?2 AS TEXT)
+`
+
+type DeleteRechirpParams struct {
+	UserID      uuid.UUID
+	RechirpOfID string
+}
+
+func (q *Queries) DeleteRechirp(ctx context.Context, arg DeleteRechirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteRechirp, arg.UserID, arg.RechirpOfID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteRechirpsOf = `-- name: DeleteRechirpsOf :exec
+DELETE FROM chirps
+WHERE rechirp_of_id = CAST(?1 AS TEXT)
+`
+
+// Removes the rechirps of a chirp that is kept as a tombstone.
+func (q *Queries) DeleteRechirpsOf(ctx context.Context, rechirpOfID string) error {
+	_, err := q.db.ExecContext(ctx, deleteRechirpsOf, rechirpOfID)
+	return err
+}
+
+const getRechirp = `-- name: GetRechirp :one
+SELECT id, created_at, updated_at, body, user_id, fanned_out_at, in_reply_to_id, conversation_id, deleted_at, rechirp_of_id, quoted_id FROM chirps
+WHERE user_id = ?1 AND rechirp_of_id = CAST(?2 AS TEXT)
+`
+
+type GetRechirpParams struct {
+	UserID      uuid.UUID
+	RechirpOfID string
+}
+
+func (q *Queries) GetRechirp(ctx context.Context, arg GetRechirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getRechirp, arg.UserID, arg.RechirpOfID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.FannedOutAt,
+		&i.InReplyToID,
+		&i.ConversationID,
+		&i.DeletedAt,
+		&i.RechirpOfID,
+		&i.QuotedID,
+	)
+	return i, err
+}
+
+const listChirpsByIDs = `-- name: ListChirpsByIDs :many
+SELECT id, created_at, updated_at, body, user_id, fanned_out_at, in_reply_to_id, conversation_id, deleted_at, rechirp_of_id, quoted_id FROM chirps
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) ListChirpsByIDs(ctx context.Context, ids []uuid.UUID) ([]Chirp, error) {
+	query := listChirpsByIDs
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.FannedOutAt,
+			&i.InReplyToID,
+			&i.ConversationID,
+			&i.DeletedAt,
+			&i.RechirpOfID,
+			&i.QuotedID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
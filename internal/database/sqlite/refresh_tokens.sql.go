@@ -0,0 +1,269 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: refresh_tokens.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const getRefreshTokenFromToken = `-- name: GetRefreshTokenFromToken :one
+SELECT token_hash, created_at, updated_at, user_id, expires_at, revoked_at, family_id, replaced_by, user_agent, ip_address, session_started_at FROM refresh_tokens
+WHERE token_hash = ?
+`
+
+func (q *Queries) GetRefreshTokenFromToken(ctx context.Context, tokenHash string) (RefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, getRefreshTokenFromToken, tokenHash)
+	var i RefreshToken
+	err := row.Scan(
+		&i.TokenHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.FamilyID,
+		&i.ReplacedBy,
+		&i.UserAgent,
+		&i.IpAddress,
+		&i.SessionStartedAt,
+	)
+	return i, err
+}
+
+const listActiveRefreshTokensByUser = `-- name: ListActiveRefreshTokensByUser :many
+SELECT token_hash, created_at, updated_at, user_id, expires_at, revoked_at, family_id, replaced_by, user_agent, ip_address, session_started_at FROM refresh_tokens
+WHERE user_id = ?1 AND revoked_at IS NULL AND expires_at > ?2
+ORDER BY session_started_at DESC
+`
+
+type ListActiveRefreshTokensByUserParams struct {
+	UserID uuid.UUID
+	Now    time.Time
+}
+
+func (q *Queries) ListActiveRefreshTokensByUser(ctx context.Context, arg ListActiveRefreshTokensByUserParams) ([]RefreshToken, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveRefreshTokensByUser, arg.UserID, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RefreshToken
+	for rows.Next() {
+		var i RefreshToken
+		if err := rows.Scan(
+			&i.TokenHash,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.ExpiresAt,
+			&i.RevokedAt,
+			&i.FamilyID,
+			&i.ReplacedBy,
+			&i.UserAgent,
+			&i.IpAddress,
+			&i.SessionStartedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRefreshTokensByUser = `-- name: ListRefreshTokensByUser :many
+SELECT token_hash, created_at, updated_at, user_id, expires_at, revoked_at, family_id, replaced_by, user_agent, ip_address, session_started_at FROM refresh_tokens
+WHERE user_id = ?
+ORDER BY created_at
+`
+
+func (q *Queries) ListRefreshTokensByUser(ctx context.Context, userID uuid.UUID) ([]RefreshToken, error) {
+	rows, err := q.db.QueryContext(ctx, listRefreshTokensByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RefreshToken
+	for rows.Next() {
+		var i RefreshToken
+		if err := rows.Scan(
+			&i.TokenHash,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.ExpiresAt,
+			&i.RevokedAt,
+			&i.FamilyID,
+			&i.ReplacedBy,
+			&i.UserAgent,
+			&i.IpAddress,
+			&i.SessionStartedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeAllRefreshTokensForUser = `-- name: RevokeAllRefreshTokensForUser :exec
+UPDATE refresh_tokens
+SET revoked_at = ?1, updated_at = ?1
+WHERE user_id = ?2 AND revoked_at IS NULL
+`
+
+type RevokeAllRefreshTokensForUserParams struct {
+	Now    sql.NullTime
+	UserID uuid.UUID
+}
+
+func (q *Queries) RevokeAllRefreshTokensForUser(ctx context.Context, arg RevokeAllRefreshTokensForUserParams) error {
+	_, err := q.db.ExecContext(ctx, revokeAllRefreshTokensForUser, arg.Now, arg.UserID)
+	return err
+}
+
+const revokeRefreshTokenFamily = `-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens
+SET revoked_at = ?1, updated_at = ?1
+WHERE family_id = ?2 AND revoked_at IS NULL
+`
+
+type RevokeRefreshTokenFamilyParams struct {
+	Now      sql.NullTime
+	FamilyID uuid.UUID
+}
+
+func (q *Queries) RevokeRefreshTokenFamily(ctx context.Context, arg RevokeRefreshTokenFamilyParams) error {
+	_, err := q.db.ExecContext(ctx, revokeRefreshTokenFamily, arg.Now, arg.FamilyID)
+	return err
+}
+
+const revokeUserRefreshTokenFamily = `-- name: RevokeUserRefreshTokenFamily :execrows
+UPDATE refresh_tokens
+SET revoked_at = ?1, updated_at = ?1
+WHERE family_id = ?2 AND user_id = ?3 AND revoked_at IS NULL
+`
+
+type RevokeUserRefreshTokenFamilyParams struct {
+	Now      sql.NullTime
+	FamilyID uuid.UUID
+	UserID   uuid.UUID
+}
+
+func (q *Queries) RevokeUserRefreshTokenFamily(ctx context.Context, arg RevokeUserRefreshTokenFamilyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokeUserRefreshTokenFamily, arg.Now, arg.FamilyID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const rotateRefreshToken = `-- name: RotateRefreshToken :execrows
+UPDATE refresh_tokens
+SET revoked_at = ?1, updated_at = ?1, replaced_by = ?2
+WHERE token_hash = ?3 AND revoked_at IS NULL
+`
+
+type RotateRefreshTokenParams struct {
+	Now        sql.NullTime
+	ReplacedBy sql.NullString
+	TokenHash  string
+}
+
+func (q *Queries) RotateRefreshToken(ctx context.Context, arg RotateRefreshTokenParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rotateRefreshToken, arg.Now, arg.ReplacedBy, arg.TokenHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setRevokeRefreshToken = `-- name: SetRevokeRefreshToken :exec
+UPDATE refresh_tokens
+SET revoked_at = ?1, updated_at = ?1
+WHERE token_hash = ?2
+`
+
+type SetRevokeRefreshTokenParams struct {
+	Now       sql.NullTime
+	TokenHash string
+}
+
+func (q *Queries) SetRevokeRefreshToken(ctx context.Context, arg SetRevokeRefreshTokenParams) error {
+	_, err := q.db.ExecContext(ctx, setRevokeRefreshToken, arg.Now, arg.TokenHash)
+	return err
+}
+
+const storeRefreshToken = `-- name: StoreRefreshToken :one
+INSERT INTO refresh_tokens (token_hash, created_at, updated_at, user_id, expires_at, revoked_at, family_id, user_agent, ip_address, session_started_at)
+VALUES (
+  ?1,
+  ?2,
+  ?2,
+  ?3,
+  ?4,
+  NULL,
+  ?5,
+  ?6,
+  ?7,
+  ?8
+)
+RETURNING token_hash, created_at, updated_at, user_id, expires_at, revoked_at, family_id, replaced_by, user_agent, ip_address, session_started_at
+`
+
+type StoreRefreshTokenParams struct {
+	TokenHash        string
+	Now              time.Time
+	UserID           uuid.UUID
+	ExpiresAt        time.Time
+	FamilyID         uuid.UUID
+	UserAgent        string
+	IpAddress        string
+	SessionStartedAt time.Time
+}
+
+func (q *Queries) StoreRefreshToken(ctx context.Context, arg StoreRefreshTokenParams) (RefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, storeRefreshToken,
+		arg.TokenHash,
+		arg.Now,
+		arg.UserID,
+		arg.ExpiresAt,
+		arg.FamilyID,
+		arg.UserAgent,
+		arg.IpAddress,
+		arg.SessionStartedAt,
+	)
+	var i RefreshToken
+	err := row.Scan(
+		&i.TokenHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.FamilyID,
+		&i.ReplacedBy,
+		&i.UserAgent,
+		&i.IpAddress,
+		&i.SessionStartedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,283 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: replies.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const countReplies = `-- name: CountReplies :one
+SELECT COUNT(*) FROM chirps
+WHERE in_reply_to_id = CAST(?1 AS TEXT)
+`
+
+func (q *Queries) CountReplies(ctx context.Context, id string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countReplies, id)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countRepliesByIDs = `-- name: CountRepliesByIDs :many
+SELECT in_reply_to_id, COUNT(*) AS reply_count
+FROM chirps
+WHERE in_reply_to_id IN (/*SLICE:ids*/?)
+GROUP BY in_reply_to_id
+`
+
+type CountRepliesByIDsRow struct {
+	InReplyToID uuid.NullUUID
+	ReplyCount  int64
+}
+
+func (q *Queries) CountRepliesByIDs(ctx context.Context, ids []uuid.NullUUID) ([]CountRepliesByIDsRow, error) {
+	query := countRepliesByIDs
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountRepliesByIDsRow
+	for rows.Next() {
+		var i CountRepliesByIDsRow
+		if err := rows.Scan(&i.InReplyToID, &i.ReplyCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpAncestors = `-- name: ListChirpAncestors :many
+WITH RECURSIVE ancestors (id, in_reply_to_id, depth) AS (
+  SELECT parent.id, parent.in_reply_to_id, 1
+  FROM chirps AS parent
+  WHERE parent.id = (SELECT child.in_reply_to_id FROM chirps AS child WHERE child.id = ?1)
+  UNION ALL
+  SELECT chirps.id, chirps.in_reply_to_id, ancestors.depth + 1
+  FROM chirps
+  JOIN ancestors ON chirps.id = ancestors.in_reply_to_id
+  WHERE ancestors.depth < CAST(?2 AS INTEGER)
+)
+SELECT id, created_at, updated_at, body, user_id, fanned_out_at, in_reply_to_id, conversation_id, deleted_at, rechirp_of_id, quoted_id FROM chirps
+WHERE id IN (SELECT ancestors.id FROM ancestors)
+ORDER BY created_at ASC, id ASC
+`
+
+type ListChirpAncestorsParams struct {
+	ID       uuid.UUID
+	MaxDepth int64
+}
+
+// The parents of a chirp up to max_depth levels. A parent is older than its
+// replies, so oldest first is root first.
+func (q *Queries) ListChirpAncestors(ctx context.Context, arg ListChirpAncestorsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpAncestors, arg.ID, arg.MaxDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.FannedOutAt,
+			&i.InReplyToID,
+			&i.ConversationID,
+			&i.DeletedAt,
+			&i.RechirpOfID,
+			&i.QuotedID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDescendants = `-- name: ListDescendants :many
+WITH RECURSIVE descendants (id, depth) AS (
+  SELECT chirps.id, 1
+  FROM chirps
+  WHERE chirps.in_reply_to_id = CAST(?2 AS TEXT)
+    AND (chirps.created_at > ?3 OR (chirps.created_at = ?3 AND chirps.id >= CAST(?4 AS TEXT)))
+    AND (chirps.created_at < ?5 OR (chirps.created_at = ?5 AND chirps.id <= CAST(?6 AS TEXT)))
+  UNION ALL
+  SELECT chirps.id, descendants.depth + 1
+  FROM chirps
+  JOIN descendants ON chirps.in_reply_to_id = descendants.id
+  WHERE descendants.depth < CAST(?7 AS INTEGER)
+)
+SELECT id, created_at, updated_at, body, user_id, fanned_out_at, in_reply_to_id, conversation_id, deleted_at, rechirp_of_id, quoted_id FROM chirps
+WHERE id IN (SELECT descendants.id FROM descendants WHERE descendants.depth > 1)
+ORDER BY created_at ASC, id ASC
+LIMIT ?1
+`
+
+type ListDescendantsParams struct {
+	Limit          int64
+	ID             string
+	FirstCreatedAt time.Time
+	FirstID        string
+	LastCreatedAt  time.Time
+	LastID         string
+	MaxDepth       int64
+}
+
+// The replies below one page of ListReplies, which is the replies to id between the keys
+// (first_created_at, first_id) and (last_created_at, last_id), up to max_depth levels in all
+// counting the page, oldest first. The page itself is not returned, so the limit only cuts
+// deeper levels. A reply is never older than its parent, so it cannot cut a parent off its replies.
+func (q *Queries) ListDescendants(ctx context.Context, arg ListDescendantsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listDescendants,
+		arg.Limit,
+		arg.ID,
+		arg.FirstCreatedAt,
+		arg.FirstID,
+		arg.LastCreatedAt,
+		arg.LastID,
+		arg.MaxDepth,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.FannedOutAt,
+			&i.InReplyToID,
+			&i.ConversationID,
+			&i.DeletedAt,
+			&i.RechirpOfID,
+			&i.QuotedID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReplies = `-- name: ListReplies :many
+SELECT id, created_at, updated_at, body, user_id, fanned_out_at, in_reply_to_id, conversation_id, deleted_at, rechirp_of_id, quoted_id FROM chirps
+WHERE in_reply_to_id = CAST(?1 AS TEXT)
+  AND (
+    created_at > ?2
+    OR (created_at = ?2 AND id > CAST(?3 AS TEXT))
+    OR ?2 IS NULL
+  )
+ORDER BY created_at ASC, id ASC
+LIMIT ?4
+`
+
+type ListRepliesParams struct {
+	ID              string
+	CursorCreatedAt sql.NullTime
+	CursorID        sql.NullString
+	Limit           int64
+}
+
+// Direct replies, oldest first.
+func (q *Queries) ListReplies(ctx context.Context, arg ListRepliesParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listReplies,
+		arg.ID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.FannedOutAt,
+			&i.InReplyToID,
+			&i.ConversationID,
+			&i.DeletedAt,
+			&i.RechirpOfID,
+			&i.QuotedID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const tombstoneChirp = `-- name: TombstoneChirp :exec
+UPDATE chirps
+SET body = '', deleted_at = ?1, updated_at = ?1
+WHERE id = ?2
+`
+
+type TombstoneChirpParams struct {
+	Now sql.NullTime
+	ID  uuid.UUID
+}
+
+// Keeps a deleted chirp that has replies, without its body.
+func (q *Queries) TombstoneChirp(ctx context.Context, arg TombstoneChirpParams) error {
+	_, err := q.db.ExecContext(ctx, tombstoneChirp, arg.Now, arg.ID)
+	return err
+}
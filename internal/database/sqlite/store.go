@@ -0,0 +1,851 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Tadateki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// Store adapts the SQLite queries to database.Store.
+// SQLite has no gen_random_uuid() or NOW(), so IDs and timestamps are generated here.
+type Store struct {
+	q *Queries
+}
+
+var _ database.Store = (*Store)(nil)
+
+func NewStore(db DBTX) *Store {
+	return &Store{q: New(db)}
+}
+
+// now is stored in UTC so that timestamps compare correctly as text.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func nullUUID(id uuid.NullUUID) sql.NullString {
+	if !id.Valid {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: id.UUID.String(), Valid: true}
+}
+
+// nullUUIDs converts IDs for a sqlc.slice over a nullable UUID column.
+func nullUUIDs(ids []uuid.UUID) []uuid.NullUUID {
+	var out []uuid.NullUUID
+	for _, id := range ids {
+		out = append(out, uuid.NullUUID{UUID: id, Valid: true})
+	}
+	return out
+}
+
+func nullTime(t sql.NullTime) sql.NullTime {
+	if !t.Valid {
+		return t
+	}
+	return sql.NullTime{Time: t.Time.UTC(), Valid: true}
+}
+
+func chirps(items []Chirp) []database.Chirp {
+	var out []database.Chirp
+	for _, c := range items {
+		out = append(out, database.Chirp(c))
+	}
+	return out
+}
+
+func users(items []User) []database.User {
+	var out []database.User
+	for _, u := range items {
+		out = append(out, database.User(u))
+	}
+	return out
+}
+
+// loginAttempt converts a row; SQLite INTEGER columns are int64.
+func loginAttempt(a LoginAttempt) database.LoginAttempt {
+	return database.LoginAttempt{
+		Key:          a.Key,
+		Failures:     int32(a.Failures),
+		LastFailedAt: a.LastFailedAt,
+		LockedUntil:  a.LockedUntil,
+	}
+}
+
+// passwordResetAttempt converts a row; SQLite INTEGER columns are int64.
+func passwordResetAttempt(a PasswordResetAttempt) database.PasswordResetAttempt {
+	return database.PasswordResetAttempt{
+		Key:             a.Key,
+		Requests:        int32(a.Requests),
+		LastRequestedAt: a.LastRequestedAt,
+		LockedUntil:     a.LockedUntil,
+	}
+}
+
+// Users
+
+func (s *Store) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	u, err := s.q.CreateUser(ctx, CreateUserParams{
+		ID:             uuid.New(),
+		Now:            now(),
+		Email:          arg.Email,
+		HashedPassword: arg.HashedPassword,
+	})
+	return database.User(u), err
+}
+
+func (s *Store) DeleteAllUsers(ctx context.Context) error {
+	return s.q.DeleteAllUsers(ctx)
+}
+
+func (s *Store) GetUserFromEmail(ctx context.Context, email string) (database.User, error) {
+	u, err := s.q.GetUserFromEmail(ctx, email)
+	return database.User(u), err
+}
+
+func (s *Store) GetUserFromUserID(ctx context.Context, id uuid.UUID) (database.User, error) {
+	u, err := s.q.GetUserFromUserID(ctx, id)
+	return database.User(u), err
+}
+
+func (s *Store) GetUserFromHandle(ctx context.Context, handle sql.NullString) (database.User, error) {
+	u, err := s.q.GetUserFromHandle(ctx, handle)
+	return database.User(u), err
+}
+
+func (s *Store) ListUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]database.User, error) {
+	items, err := s.q.ListUsersByIDs(ctx, ids)
+	return users(items), err
+}
+
+func (s *Store) SetChirpyRed(ctx context.Context, arg database.SetChirpyRedParams) error {
+	return s.q.SetChirpyRed(ctx, SetChirpyRedParams{
+		Now:         now(),
+		IsChirpyRed: arg.IsChirpyRed,
+		ID:          arg.ID,
+	})
+}
+
+func (s *Store) UpdateUser(ctx context.Context, arg database.UpdateUserParams) error {
+	return s.q.UpdateUser(ctx, UpdateUserParams{
+		Now:            now(),
+		Email:          arg.Email,
+		HashedPassword: arg.HashedPassword,
+		ID:             arg.ID,
+	})
+}
+
+func (s *Store) SetTokensValidAfter(ctx context.Context, arg database.SetTokensValidAfterParams) error {
+	return s.q.SetTokensValidAfter(ctx, SetTokensValidAfterParams{
+		Now:              now(),
+		TokensValidAfter: nullTime(arg.TokensValidAfter),
+		ID:               arg.ID,
+	})
+}
+
+func (s *Store) SetUserRole(ctx context.Context, arg database.SetUserRoleParams) error {
+	return s.q.SetUserRole(ctx, SetUserRoleParams{
+		Now:  now(),
+		Role: arg.Role,
+		ID:   arg.ID,
+	})
+}
+
+func (s *Store) SetUserEmail(ctx context.Context, arg database.SetUserEmailParams) error {
+	return s.q.SetUserEmail(ctx, SetUserEmailParams{
+		Now:   now(),
+		Email: arg.Email,
+		ID:    arg.ID,
+	})
+}
+
+func (s *Store) SetUserProfile(ctx context.Context, arg database.SetUserProfileParams) error {
+	return s.q.SetUserProfile(ctx, SetUserProfileParams{
+		Now:         now(),
+		Handle:      arg.Handle,
+		DisplayName: arg.DisplayName,
+		Bio:         arg.Bio,
+		AvatarUrl:   arg.AvatarUrl,
+		ID:          arg.ID,
+	})
+}
+
+func (s *Store) PatchUser(ctx context.Context, arg database.PatchUserParams) error {
+	return s.q.PatchUser(ctx, PatchUserParams{
+		Now:            now(),
+		Handle:         arg.Handle,
+		DisplayName:    arg.DisplayName,
+		Bio:            arg.Bio,
+		AvatarUrl:      arg.AvatarUrl,
+		Email:          arg.Email,
+		HashedPassword: arg.HashedPassword,
+		ID:             arg.ID,
+	})
+}
+
+func (s *Store) SetUserAvatar(ctx context.Context, arg database.SetUserAvatarParams) error {
+	return s.q.SetUserAvatar(ctx, SetUserAvatarParams{
+		Now:       now(),
+		AvatarUrl: arg.AvatarUrl,
+		AvatarKey: arg.AvatarKey,
+		ID:        arg.ID,
+	})
+}
+
+func (s *Store) SetUserHeader(ctx context.Context, arg database.SetUserHeaderParams) error {
+	return s.q.SetUserHeader(ctx, SetUserHeaderParams{
+		Now:       now(),
+		HeaderUrl: arg.HeaderUrl,
+		HeaderKey: arg.HeaderKey,
+		ID:        arg.ID,
+	})
+}
+
+func (s *Store) ScheduleUserDeletion(ctx context.Context, arg database.ScheduleUserDeletionParams) error {
+	return s.q.ScheduleUserDeletion(ctx, ScheduleUserDeletionParams{
+		Now:         now(),
+		DeleteAfter: arg.DeleteAfter,
+		ID:          arg.ID,
+	})
+}
+
+func (s *Store) CancelUserDeletion(ctx context.Context, id uuid.UUID) error {
+	return s.q.CancelUserDeletion(ctx, CancelUserDeletionParams{
+		Now: now(),
+		ID:  id,
+	})
+}
+
+func (s *Store) PurgeDeletedUsers(ctx context.Context, t time.Time) ([]database.PurgeDeletedUsersRow, error) {
+	items, err := s.q.PurgeDeletedUsers(ctx, sql.NullTime{Time: t.UTC(), Valid: true})
+	var out []database.PurgeDeletedUsersRow
+	for _, row := range items {
+		out = append(out, database.PurgeDeletedUsersRow(row))
+	}
+	return out, err
+}
+
+func (s *Store) SetUserPassword(ctx context.Context, arg database.SetUserPasswordParams) error {
+	return s.q.SetUserPassword(ctx, SetUserPasswordParams{
+		Now:            now(),
+		HashedPassword: arg.HashedPassword,
+		ID:             arg.ID,
+	})
+}
+
+func (s *Store) RehashUserPassword(ctx context.Context, arg database.RehashUserPasswordParams) (int64, error) {
+	return s.q.RehashUserPassword(ctx, RehashUserPasswordParams(arg))
+}
+
+func (s *Store) MarkEmailVerified(ctx context.Context, arg database.MarkEmailVerifiedParams) (int64, error) {
+	return s.q.MarkEmailVerified(ctx, MarkEmailVerifiedParams{
+		Now:   now(),
+		ID:    arg.ID,
+		Email: arg.Email,
+	})
+}
+
+func (s *Store) SetUserTOTPSecret(ctx context.Context, arg database.SetUserTOTPSecretParams) error {
+	return s.q.SetUserTOTPSecret(ctx, SetUserTOTPSecretParams{
+		Now:        now(),
+		TotpSecret: arg.TotpSecret,
+		ID:         arg.ID,
+	})
+}
+
+func (s *Store) EnableUserTOTP(ctx context.Context, id uuid.UUID) error {
+	return s.q.EnableUserTOTP(ctx, EnableUserTOTPParams{Now: now(), ID: id})
+}
+
+func (s *Store) DisableUserTOTP(ctx context.Context, id uuid.UUID) error {
+	return s.q.DisableUserTOTP(ctx, DisableUserTOTPParams{Now: now(), ID: id})
+}
+
+func (s *Store) UseTOTPStep(ctx context.Context, arg database.UseTOTPStepParams) (int64, error) {
+	return s.q.UseTOTPStep(ctx, UseTOTPStepParams(arg))
+}
+
+// Chirps
+
+func (s *Store) CreateChirp(ctx context.Context, arg database.CreateChirpParams) (database.Chirp, error) {
+	id := uuid.New()
+	conversationID := id
+	if arg.ConversationID.Valid {
+		conversationID = arg.ConversationID.UUID
+	}
+	c, err := s.q.CreateChirp(ctx, CreateChirpParams{
+		ID:             id,
+		Now:            now(),
+		Body:           arg.Body,
+		UserID:         arg.UserID,
+		InReplyToID:    arg.InReplyToID,
+		ConversationID: conversationID,
+		QuotedID:       arg.QuotedID,
+	})
+	return database.Chirp(c), err
+}
+
+func (s *Store) DeleteChirp(ctx context.Context, id uuid.UUID) error {
+	return s.q.DeleteChirp(ctx, id)
+}
+
+func (s *Store) GetChirp(ctx context.Context, id uuid.UUID) (database.Chirp, error) {
+	c, err := s.q.GetChirp(ctx, id)
+	return database.Chirp(c), err
+}
+
+func (s *Store) ListChirpsAsc(ctx context.Context, arg database.ListChirpsAscParams) ([]database.Chirp, error) {
+	items, err := s.q.ListChirpsAsc(ctx, ListChirpsAscParams{
+		AuthorID:        nullUUID(arg.AuthorID),
+		CursorCreatedAt: nullTime(arg.CursorCreatedAt),
+		CursorID:        nullUUID(arg.CursorID),
+		Limit:           int64(arg.Limit),
+	})
+	return chirps(items), err
+}
+
+func (s *Store) ListChirpsDesc(ctx context.Context, arg database.ListChirpsDescParams) ([]database.Chirp, error) {
+	items, err := s.q.ListChirpsDesc(ctx, ListChirpsDescParams{
+		AuthorID:        nullUUID(arg.AuthorID),
+		CursorCreatedAt: nullTime(arg.CursorCreatedAt),
+		CursorID:        nullUUID(arg.CursorID),
+		Limit:           int64(arg.Limit),
+	})
+	return chirps(items), err
+}
+
+// Refresh tokens
+
+func (s *Store) GetRefreshTokenFromToken(ctx context.Context, tokenHash string) (database.RefreshToken, error) {
+	rt, err := s.q.GetRefreshTokenFromToken(ctx, tokenHash)
+	return database.RefreshToken(rt), err
+}
+
+func (s *Store) SetRevokeRefreshToken(ctx context.Context, tokenHash string) error {
+	return s.q.SetRevokeRefreshToken(ctx, SetRevokeRefreshTokenParams{
+		Now:       sql.NullTime{Time: now(), Valid: true},
+		TokenHash: tokenHash,
+	})
+}
+
+func (s *Store) StoreRefreshToken(ctx context.Context, arg database.StoreRefreshTokenParams) (database.RefreshToken, error) {
+	t := now()
+	sessionStartedAt := t
+	if arg.SessionStartedAt.Valid {
+		sessionStartedAt = arg.SessionStartedAt.Time.UTC()
+	}
+	rt, err := s.q.StoreRefreshToken(ctx, StoreRefreshTokenParams{
+		TokenHash:        arg.TokenHash,
+		Now:              t,
+		UserID:           arg.UserID,
+		ExpiresAt:        arg.ExpiresAt.UTC(),
+		FamilyID:         arg.FamilyID,
+		UserAgent:        arg.UserAgent,
+		IpAddress:        arg.IpAddress,
+		SessionStartedAt: sessionStartedAt,
+	})
+	return database.RefreshToken(rt), err
+}
+
+func (s *Store) RotateRefreshToken(ctx context.Context, arg database.RotateRefreshTokenParams) (int64, error) {
+	return s.q.RotateRefreshToken(ctx, RotateRefreshTokenParams{
+		Now:        sql.NullTime{Time: now(), Valid: true},
+		ReplacedBy: arg.ReplacedBy,
+		TokenHash:  arg.TokenHash,
+	})
+}
+
+func (s *Store) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	return s.q.RevokeRefreshTokenFamily(ctx, RevokeRefreshTokenFamilyParams{
+		Now:      sql.NullTime{Time: now(), Valid: true},
+		FamilyID: familyID,
+	})
+}
+
+func (s *Store) ListActiveRefreshTokensByUser(ctx context.Context, arg database.ListActiveRefreshTokensByUserParams) ([]database.RefreshToken, error) {
+	items, err := s.q.ListActiveRefreshTokensByUser(ctx, ListActiveRefreshTokensByUserParams{
+		UserID: arg.UserID,
+		Now:    arg.Now.UTC(),
+	})
+	var out []database.RefreshToken
+	for _, rt := range items {
+		out = append(out, database.RefreshToken(rt))
+	}
+	return out, err
+}
+
+func (s *Store) ListRefreshTokensByUser(ctx context.Context, userID uuid.UUID) ([]database.RefreshToken, error) {
+	items, err := s.q.ListRefreshTokensByUser(ctx, userID)
+	var out []database.RefreshToken
+	for _, rt := range items {
+		out = append(out, database.RefreshToken(rt))
+	}
+	return out, err
+}
+
+func (s *Store) RevokeUserRefreshTokenFamily(ctx context.Context, arg database.RevokeUserRefreshTokenFamilyParams) (int64, error) {
+	return s.q.RevokeUserRefreshTokenFamily(ctx, RevokeUserRefreshTokenFamilyParams{
+		Now:      sql.NullTime{Time: now(), Valid: true},
+		FamilyID: arg.FamilyID,
+		UserID:   arg.UserID,
+	})
+}
+
+func (s *Store) RevokeAllRefreshTokensForUser(ctx context.Context, userID uuid.UUID) error {
+	return s.q.RevokeAllRefreshTokensForUser(ctx, RevokeAllRefreshTokensForUserParams{
+		Now:    sql.NullTime{Time: now(), Valid: true},
+		UserID: userID,
+	})
+}
+
+// MFA recovery codes
+
+func (s *Store) CreateRecoveryCode(ctx context.Context, arg database.CreateRecoveryCodeParams) error {
+	return s.q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+		CodeHash: arg.CodeHash,
+		Now:      now(),
+		UserID:   arg.UserID,
+	})
+}
+
+func (s *Store) DeleteRecoveryCodesForUser(ctx context.Context, userID uuid.UUID) error {
+	return s.q.DeleteRecoveryCodesForUser(ctx, userID)
+}
+
+func (s *Store) UseRecoveryCode(ctx context.Context, arg database.UseRecoveryCodeParams) (int64, error) {
+	return s.q.UseRecoveryCode(ctx, UseRecoveryCodeParams{
+		Now:      sql.NullTime{Time: now(), Valid: true},
+		CodeHash: arg.CodeHash,
+		UserID:   arg.UserID,
+	})
+}
+
+func (s *Store) UseMFAToken(ctx context.Context, arg database.UseMFATokenParams) (int64, error) {
+	return s.q.UseMFAToken(ctx, UseMFATokenParams{
+		Jti:       arg.Jti,
+		UserID:    arg.UserID,
+		ExpiresAt: arg.ExpiresAt.UTC(),
+	})
+}
+
+func (s *Store) DeleteExpiredMFATokens(ctx context.Context, before time.Time) error {
+	return s.q.DeleteExpiredMFATokens(ctx, before.UTC())
+}
+
+// Password reset tokens
+
+func (s *Store) CreatePasswordResetToken(ctx context.Context, arg database.CreatePasswordResetTokenParams) error {
+	return s.q.CreatePasswordResetToken(ctx, CreatePasswordResetTokenParams{
+		TokenHash: arg.TokenHash,
+		Now:       now(),
+		UserID:    arg.UserID,
+		ExpiresAt: arg.ExpiresAt.UTC(),
+	})
+}
+
+func (s *Store) GetPasswordResetTokenUser(ctx context.Context, arg database.GetPasswordResetTokenUserParams) (uuid.UUID, error) {
+	return s.q.GetPasswordResetTokenUser(ctx, GetPasswordResetTokenUserParams{
+		TokenHash: arg.TokenHash,
+		Now:       arg.Now.UTC(),
+	})
+}
+
+func (s *Store) ConsumePasswordResetToken(ctx context.Context, arg database.ConsumePasswordResetTokenParams) (uuid.UUID, error) {
+	return s.q.ConsumePasswordResetToken(ctx, ConsumePasswordResetTokenParams{
+		Now:       sql.NullTime{Time: arg.Now.UTC(), Valid: true},
+		TokenHash: arg.TokenHash,
+	})
+}
+
+func (s *Store) InvalidatePasswordResetTokensForUser(ctx context.Context, userID uuid.UUID) error {
+	return s.q.InvalidatePasswordResetTokensForUser(ctx, InvalidatePasswordResetTokensForUserParams{
+		Now:    sql.NullTime{Time: now(), Valid: true},
+		UserID: userID,
+	})
+}
+
+// Email verification tokens
+
+func (s *Store) CreateEmailVerificationToken(ctx context.Context, arg database.CreateEmailVerificationTokenParams) error {
+	return s.q.CreateEmailVerificationToken(ctx, CreateEmailVerificationTokenParams{
+		TokenHash: arg.TokenHash,
+		Now:       now(),
+		UserID:    arg.UserID,
+		Email:     arg.Email,
+		ExpiresAt: arg.ExpiresAt.UTC(),
+	})
+}
+
+func (s *Store) ConsumeEmailVerificationToken(ctx context.Context, arg database.ConsumeEmailVerificationTokenParams) (database.ConsumeEmailVerificationTokenRow, error) {
+	row, err := s.q.ConsumeEmailVerificationToken(ctx, ConsumeEmailVerificationTokenParams{
+		Now:       sql.NullTime{Time: arg.Now.UTC(), Valid: true},
+		TokenHash: arg.TokenHash,
+	})
+	return database.ConsumeEmailVerificationTokenRow(row), err
+}
+
+// Login attempts
+
+func (s *Store) GetLoginAttempt(ctx context.Context, key string) (database.LoginAttempt, error) {
+	a, err := s.q.GetLoginAttempt(ctx, key)
+	return loginAttempt(a), err
+}
+
+// RecordLoginFailure is an upsert in Postgres; sqlc cannot parse SQLite's
+// ON CONFLICT DO UPDATE with parameters, so the row is created first and
+// then incremented by a single atomic UPDATE.
+func (s *Store) RecordLoginFailure(ctx context.Context, arg database.RecordLoginFailureParams) (database.LoginAttempt, error) {
+	t := arg.Now.UTC()
+	err := s.q.EnsureLoginAttempt(ctx, EnsureLoginAttemptParams{Key: arg.Key, Now: t})
+	if err != nil {
+		return database.LoginAttempt{}, err
+	}
+	a, err := s.q.IncrementLoginFailures(ctx, IncrementLoginFailuresParams{
+		ResetBefore: arg.ResetBefore.UTC(),
+		Now:         t,
+		Key:         arg.Key,
+	})
+	return loginAttempt(a), err
+}
+
+func (s *Store) LockLogin(ctx context.Context, arg database.LockLoginParams) error {
+	return s.q.LockLogin(ctx, LockLoginParams{
+		LockedUntil: nullTime(arg.LockedUntil),
+		Key:         arg.Key,
+	})
+}
+
+func (s *Store) ClearLoginAttempts(ctx context.Context, key string) error {
+	return s.q.ClearLoginAttempts(ctx, key)
+}
+
+// Password reset attempts
+
+func (s *Store) GetPasswordResetAttempt(ctx context.Context, key string) (database.PasswordResetAttempt, error) {
+	a, err := s.q.GetPasswordResetAttempt(ctx, key)
+	return passwordResetAttempt(a), err
+}
+
+// RecordPasswordResetRequest is an upsert in Postgres, split in two like RecordLoginFailure.
+func (s *Store) RecordPasswordResetRequest(ctx context.Context, arg database.RecordPasswordResetRequestParams) (database.PasswordResetAttempt, error) {
+	t := arg.Now.UTC()
+	err := s.q.EnsurePasswordResetAttempt(ctx, EnsurePasswordResetAttemptParams{Key: arg.Key, Now: t})
+	if err != nil {
+		return database.PasswordResetAttempt{}, err
+	}
+	a, err := s.q.IncrementPasswordResetRequests(ctx, IncrementPasswordResetRequestsParams{
+		ResetBefore: arg.ResetBefore.UTC(),
+		Now:         t,
+		Key:         arg.Key,
+	})
+	return passwordResetAttempt(a), err
+}
+
+func (s *Store) LockPasswordReset(ctx context.Context, arg database.LockPasswordResetParams) error {
+	return s.q.LockPasswordReset(ctx, LockPasswordResetParams{
+		LockedUntil: nullTime(arg.LockedUntil),
+		Key:         arg.Key,
+	})
+}
+
+// Webhook events
+
+func (s *Store) CreateWebhookEvent(ctx context.Context, arg database.CreateWebhookEventParams) error {
+	return s.q.CreateWebhookEvent(ctx, CreateWebhookEventParams{
+		ID:      uuid.New(),
+		Now:     now(),
+		UserID:  arg.UserID,
+		Event:   arg.Event,
+		Payload: arg.Payload,
+	})
+}
+
+func (s *Store) ListWebhookEventsByUser(ctx context.Context, userID uuid.UUID) ([]database.WebhookEvent, error) {
+	items, err := s.q.ListWebhookEventsByUser(ctx, userID)
+	var out []database.WebhookEvent
+	for _, e := range items {
+		out = append(out, database.WebhookEvent(e))
+	}
+	return out, err
+}
+
+// Follows
+
+func (s *Store) FollowUser(ctx context.Context, arg database.FollowUserParams) (int64, error) {
+	return s.q.FollowUser(ctx, FollowUserParams{
+		FollowerID: arg.FollowerID,
+		FolloweeID: arg.FolloweeID,
+		Now:        now(),
+	})
+}
+
+func (s *Store) UnfollowUser(ctx context.Context, arg database.UnfollowUserParams) (int64, error) {
+	return s.q.UnfollowUser(ctx, UnfollowUserParams(arg))
+}
+
+func (s *Store) CountFollowers(ctx context.Context, followeeID uuid.UUID) (int64, error) {
+	return s.q.CountFollowers(ctx, followeeID)
+}
+
+func (s *Store) CountFollowing(ctx context.Context, followerID uuid.UUID) (int64, error) {
+	return s.q.CountFollowing(ctx, followerID)
+}
+
+func (s *Store) ListFollowers(ctx context.Context, arg database.ListFollowersParams) ([]database.ListFollowersRow, error) {
+	items, err := s.q.ListFollowers(ctx, ListFollowersParams{
+		UserID:          arg.UserID,
+		CursorCreatedAt: nullTime(arg.CursorCreatedAt),
+		CursorID:        nullUUID(arg.CursorID),
+		Limit:           int64(arg.Limit),
+	})
+	var out []database.ListFollowersRow
+	for _, row := range items {
+		out = append(out, database.ListFollowersRow(row))
+	}
+	return out, err
+}
+
+func (s *Store) ListFollowing(ctx context.Context, arg database.ListFollowingParams) ([]database.ListFollowingRow, error) {
+	items, err := s.q.ListFollowing(ctx, ListFollowingParams{
+		UserID:          arg.UserID,
+		CursorCreatedAt: nullTime(arg.CursorCreatedAt),
+		CursorID:        nullUUID(arg.CursorID),
+		Limit:           int64(arg.Limit),
+	})
+	var out []database.ListFollowingRow
+	for _, row := range items {
+		out = append(out, database.ListFollowingRow(row))
+	}
+	return out, err
+}
+
+func (s *Store) ListFollowsByUser(ctx context.Context, userID uuid.UUID) ([]database.Follow, error) {
+	items, err := s.q.ListFollowsByUser(ctx, userID)
+	var out []database.Follow
+	for _, f := range items {
+		out = append(out, database.Follow(f))
+	}
+	return out, err
+}
+
+// Timelines
+
+func (s *Store) FanOutChirp(ctx context.Context, id uuid.UUID) (int64, error) {
+	return s.q.FanOutChirp(ctx, id)
+}
+
+func (s *Store) MarkChirpFannedOut(ctx context.Context, id uuid.UUID) error {
+	return s.q.MarkChirpFannedOut(ctx, MarkChirpFannedOutParams{
+		Now: sql.NullTime{Time: now(), Valid: true},
+		ID:  id,
+	})
+}
+
+func (s *Store) BackfillTimeline(ctx context.Context, arg database.BackfillTimelineParams) error {
+	return s.q.BackfillTimeline(ctx, BackfillTimelineParams{
+		UserID:     arg.UserID.String(),
+		FolloweeID: arg.FolloweeID,
+		Limit:      int64(arg.Limit),
+	})
+}
+
+func (s *Store) DeleteTimelineEntriesByAuthor(ctx context.Context, arg database.DeleteTimelineEntriesByAuthorParams) error {
+	return s.q.DeleteTimelineEntriesByAuthor(ctx, DeleteTimelineEntriesByAuthorParams(arg))
+}
+
+func (s *Store) ListTimelineEntries(ctx context.Context, arg database.ListTimelineEntriesParams) ([]database.Chirp, error) {
+	items, err := s.q.ListTimelineEntries(ctx, ListTimelineEntriesParams{
+		UserID:          arg.UserID,
+		CursorCreatedAt: nullTime(arg.CursorCreatedAt),
+		CursorID:        nullUUID(arg.CursorID),
+		Limit:           int64(arg.Limit),
+	})
+	return chirps(items), err
+}
+
+func (s *Store) ListUnfannedTimelineChirps(ctx context.Context, arg database.ListUnfannedTimelineChirpsParams) ([]database.Chirp, error) {
+	items, err := s.q.ListUnfannedTimelineChirps(ctx, ListUnfannedTimelineChirpsParams{
+		UserID:          arg.UserID,
+		CursorCreatedAt: nullTime(arg.CursorCreatedAt),
+		CursorID:        nullUUID(arg.CursorID),
+		Limit:           int64(arg.Limit),
+	})
+	return chirps(items), err
+}
+
+func (s *Store) ListUnfannedChirps(ctx context.Context) ([]database.Chirp, error) {
+	items, err := s.q.ListUnfannedChirps(ctx)
+	return chirps(items), err
+}
+
+func (s *Store) DeleteTimelineEntriesForChirp(ctx context.Context, chirpID uuid.UUID) error {
+	return s.q.DeleteTimelineEntriesForChirp(ctx, chirpID)
+}
+
+func (s *Store) DeleteAllTimelineEntries(ctx context.Context) error {
+	return s.q.DeleteAllTimelineEntries(ctx)
+}
+
+func (s *Store) RebuildTimelineEntries(ctx context.Context) (int64, error) {
+	return s.q.RebuildTimelineEntries(ctx)
+}
+
+// Replies
+
+func (s *Store) TombstoneChirp(ctx context.Context, id uuid.UUID) error {
+	return s.q.TombstoneChirp(ctx, TombstoneChirpParams{
+		Now: sql.NullTime{Time: now(), Valid: true},
+		ID:  id,
+	})
+}
+
+func (s *Store) CountReplies(ctx context.Context, id uuid.UUID) (int64, error) {
+	return s.q.CountReplies(ctx, id.String())
+}
+
+func (s *Store) CountRepliesByIDs(ctx context.Context, ids []uuid.UUID) ([]database.CountRepliesByIDsRow, error) {
+	items, err := s.q.CountRepliesByIDs(ctx, nullUUIDs(ids))
+	var out []database.CountRepliesByIDsRow
+	for _, row := range items {
+		out = append(out, database.CountRepliesByIDsRow(row))
+	}
+	return out, err
+}
+
+func (s *Store) ListChirpAncestors(ctx context.Context, arg database.ListChirpAncestorsParams) ([]database.Chirp, error) {
+	items, err := s.q.ListChirpAncestors(ctx, ListChirpAncestorsParams{
+		ID:       arg.ID,
+		MaxDepth: int64(arg.MaxDepth),
+	})
+	return chirps(items), err
+}
+
+func (s *Store) ListReplies(ctx context.Context, arg database.ListRepliesParams) ([]database.Chirp, error) {
+	items, err := s.q.ListReplies(ctx, ListRepliesParams{
+		ID:              arg.ID.String(),
+		CursorCreatedAt: nullTime(arg.CursorCreatedAt),
+		CursorID:        nullUUID(arg.CursorID),
+		Limit:           int64(arg.Limit),
+	})
+	return chirps(items), err
+}
+
+func (s *Store) ListDescendants(ctx context.Context, arg database.ListDescendantsParams) ([]database.Chirp, error) {
+	items, err := s.q.ListDescendants(ctx, ListDescendantsParams{
+		Limit:          int64(arg.Limit),
+		ID:             arg.ID.String(),
+		FirstCreatedAt: arg.FirstCreatedAt.UTC(),
+		FirstID:        arg.FirstID.String(),
+		LastCreatedAt:  arg.LastCreatedAt.UTC(),
+		LastID:         arg.LastID.String(),
+		MaxDepth:       int64(arg.MaxDepth),
+	})
+	return chirps(items), err
+}
+
+// Likes
+
+func (s *Store) LikeChirp(ctx context.Context, arg database.LikeChirpParams) (int64, error) {
+	return s.q.LikeChirp(ctx, LikeChirpParams{
+		UserID:  arg.UserID,
+		ChirpID: arg.ChirpID,
+		Now:     now(),
+	})
+}
+
+func (s *Store) UnlikeChirp(ctx context.Context, arg database.UnlikeChirpParams) (int64, error) {
+	return s.q.UnlikeChirp(ctx, UnlikeChirpParams(arg))
+}
+
+func (s *Store) CountLikesByIDs(ctx context.Context, ids []uuid.UUID) ([]database.CountLikesByIDsRow, error) {
+	items, err := s.q.CountLikesByIDs(ctx, ids)
+	var out []database.CountLikesByIDsRow
+	for _, row := range items {
+		out = append(out, database.CountLikesByIDsRow(row))
+	}
+	return out, err
+}
+
+func (s *Store) ListLikedChirpIDs(ctx context.Context, arg database.ListLikedChirpIDsParams) ([]uuid.UUID, error) {
+	return s.q.ListLikedChirpIDs(ctx, ListLikedChirpIDsParams(arg))
+}
+
+func (s *Store) ListLikesByUser(ctx context.Context, userID uuid.UUID) ([]database.Like, error) {
+	items, err := s.q.ListLikesByUser(ctx, userID)
+	var out []database.Like
+	for _, l := range items {
+		out = append(out, database.Like(l))
+	}
+	return out, err
+}
+
+func (s *Store) ListLikedChirps(ctx context.Context, arg database.ListLikedChirpsParams) ([]database.ListLikedChirpsRow, error) {
+	items, err := s.q.ListLikedChirps(ctx, ListLikedChirpsParams{
+		UserID:          arg.UserID,
+		CursorCreatedAt: nullTime(arg.CursorCreatedAt),
+		CursorID:        nullUUID(arg.CursorID),
+		Limit:           int64(arg.Limit),
+	})
+	var out []database.ListLikedChirpsRow
+	for _, row := range items {
+		out = append(out, database.ListLikedChirpsRow{Chirp: database.Chirp(row.Chirp), LikedAt: row.LikedAt})
+	}
+	return out, err
+}
+
+// Rechirps
+
+func (s *Store) CreateRechirp(ctx context.Context, arg database.CreateRechirpParams) (database.Chirp, error) {
+	c, err := s.q.CreateRechirp(ctx, CreateRechirpParams{
+		ID:          uuid.New(),
+		Now:         now(),
+		UserID:      arg.UserID,
+		RechirpOfID: arg.RechirpOfID.String(),
+	})
+	return database.Chirp(c), err
+}
+
+func (s *Store) GetRechirp(ctx context.Context, arg database.GetRechirpParams) (database.Chirp, error) {
+	c, err := s.q.GetRechirp(ctx, GetRechirpParams{
+		UserID:      arg.UserID,
+		RechirpOfID: arg.RechirpOfID.String(),
+	})
+	return database.Chirp(c), err
+}
+
+func (s *Store) DeleteRechirp(ctx context.Context, arg database.DeleteRechirpParams) (int64, error) {
+	return s.q.DeleteRechirp(ctx, DeleteRechirpParams{
+		UserID:      arg.UserID,
+		RechirpOfID: arg.RechirpOfID.String(),
+	})
+}
+
+func (s *Store) DeleteRechirpsOf(ctx context.Context, rechirpOfID uuid.UUID) error {
+	return s.q.DeleteRechirpsOf(ctx, rechirpOfID.String())
+}
+
+func (s *Store) CountRechirpsByIDs(ctx context.Context, ids []uuid.UUID) ([]database.CountRechirpsByIDsRow, error) {
+	items, err := s.q.CountRechirpsByIDs(ctx, nullUUIDs(ids))
+	var out []database.CountRechirpsByIDsRow
+	for _, row := range items {
+		out = append(out, database.CountRechirpsByIDsRow(row))
+	}
+	return out, err
+}
+
+func (s *Store) CountQuotesByIDs(ctx context.Context, ids []uuid.UUID) ([]database.CountQuotesByIDsRow, error) {
+	items, err := s.q.CountQuotesByIDs(ctx, nullUUIDs(ids))
+	var out []database.CountQuotesByIDsRow
+	for _, row := range items {
+		out = append(out, database.CountQuotesByIDsRow(row))
+	}
+	return out, err
+}
+
+func (s *Store) ListChirpsByIDs(ctx context.Context, ids []uuid.UUID) ([]database.Chirp, error) {
+	items, err := s.q.ListChirpsByIDs(ctx, ids)
+	return chirps(items), err
+}
@@ -0,0 +1,942 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tadateki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// newTestStore opens a fresh database file and applies the Up section of every schema file.
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	db, err := Open(filepath.Join(t.TempDir(), "chirpy.db"))
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	files, err := filepath.Glob("../../../sql/sqlite/schema/*.sql")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no schema files found: %v", err)
+	}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatalf("read %s: %v", f, err)
+		}
+		up, _, _ := strings.Cut(string(b), "-- +goose Down")
+		if _, err := db.Exec(up); err != nil {
+			t.Fatalf("apply %s: %v", f, err)
+		}
+	}
+
+	return NewStore(db)
+}
+
+func TestStore_UsersAndTokens(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	user, err := s.CreateUser(ctx, database.CreateUserParams{Email: "a@example.com", HashedPassword: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser returned error: %v", err)
+	}
+	if _, err := s.CreateUser(ctx, database.CreateUserParams{Email: "a@example.com", HashedPassword: "hash"}); !database.IsUniqueViolation(err) {
+		t.Fatalf("CreateUser with a duplicate email returned %v, want a unique violation", err)
+	}
+
+	got, err := s.GetUserFromEmail(ctx, "a@example.com")
+	if err != nil {
+		t.Fatalf("GetUserFromEmail returned error: %v", err)
+	}
+	if got.ID != user.ID || !got.CreatedAt.Equal(user.CreatedAt) {
+		t.Errorf("GetUserFromEmail returned %+v, want %+v", got, user)
+	}
+
+	if err := s.SetChirpyRed(ctx, database.SetChirpyRedParams{IsChirpyRed: true, ID: user.ID}); err != nil {
+		t.Fatalf("SetChirpyRed returned error: %v", err)
+	}
+	got, _ = s.GetUserFromUserID(ctx, user.ID)
+	if !got.IsChirpyRed {
+		t.Errorf("SetChirpyRed did not persist")
+	}
+
+	cutoff := time.Now().Truncate(time.Second)
+	if err := s.SetTokensValidAfter(ctx, database.SetTokensValidAfterParams{TokensValidAfter: sql.NullTime{Time: cutoff, Valid: true}, ID: user.ID}); err != nil {
+		t.Fatalf("SetTokensValidAfter returned error: %v", err)
+	}
+	got, _ = s.GetUserFromUserID(ctx, user.ID)
+	if !got.TokensValidAfter.Valid || !got.TokensValidAfter.Time.Equal(cutoff) {
+		t.Errorf("SetTokensValidAfter stored %v, want %v", got.TokensValidAfter, cutoff)
+	}
+
+	expires := time.Now().Add(time.Hour)
+	if _, err := s.StoreRefreshToken(ctx, database.StoreRefreshTokenParams{TokenHash: "t", UserID: user.ID, ExpiresAt: expires, FamilyID: uuid.New()}); err != nil {
+		t.Fatalf("StoreRefreshToken returned error: %v", err)
+	}
+	if err := s.SetRevokeRefreshToken(ctx, "t"); err != nil {
+		t.Fatalf("SetRevokeRefreshToken returned error: %v", err)
+	}
+	rt, err := s.GetRefreshTokenFromToken(ctx, "t")
+	if err != nil {
+		t.Fatalf("GetRefreshTokenFromToken returned error: %v", err)
+	}
+	if !rt.RevokedAt.Valid {
+		t.Errorf("refresh token was not revoked")
+	}
+	if rt.ExpiresAt.Sub(expires).Abs() > time.Millisecond {
+		t.Errorf("refresh token expires_at %v, want %v", rt.ExpiresAt, expires)
+	}
+
+	if err := s.DeleteAllUsers(ctx); err != nil {
+		t.Fatalf("DeleteAllUsers returned error: %v", err)
+	}
+	if _, err := s.GetRefreshTokenFromToken(ctx, "t"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetRefreshTokenFromToken after DeleteAllUsers returned %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestStore_TOTPAndRecoveryCodes(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	user, err := s.CreateUser(ctx, database.CreateUserParams{Email: "a@example.com", HashedPassword: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser returned error: %v", err)
+	}
+
+	// Enabling without a pending secret is a no-op.
+	if err := s.EnableUserTOTP(ctx, user.ID); err != nil {
+		t.Fatalf("EnableUserTOTP returned error: %v", err)
+	}
+	if got, _ := s.GetUserFromUserID(ctx, user.ID); got.TotpEnabledAt.Valid {
+		t.Fatalf("EnableUserTOTP enabled 2FA without a secret")
+	}
+
+	err = s.SetUserTOTPSecret(ctx, database.SetUserTOTPSecretParams{
+		TotpSecret: sql.NullString{String: "sealed", Valid: true},
+		ID:         user.ID,
+	})
+	if err != nil {
+		t.Fatalf("SetUserTOTPSecret returned error: %v", err)
+	}
+	if err := s.EnableUserTOTP(ctx, user.ID); err != nil {
+		t.Fatalf("EnableUserTOTP returned error: %v", err)
+	}
+	got, _ := s.GetUserFromUserID(ctx, user.ID)
+	if got.TotpSecret.String != "sealed" || !got.TotpEnabledAt.Valid {
+		t.Fatalf("user after enrollment: secret %v, enabled %v", got.TotpSecret, got.TotpEnabledAt)
+	}
+
+	err = s.CreateRecoveryCode(ctx, database.CreateRecoveryCodeParams{CodeHash: "code1", UserID: user.ID})
+	if err != nil {
+		t.Fatalf("CreateRecoveryCode returned error: %v", err)
+	}
+	use := database.UseRecoveryCodeParams{CodeHash: "code1", UserID: user.ID}
+	if n, err := s.UseRecoveryCode(ctx, use); err != nil || n != 1 {
+		t.Fatalf("UseRecoveryCode = %d, %v; want 1", n, err)
+	}
+	if n, _ := s.UseRecoveryCode(ctx, use); n != 0 {
+		t.Errorf("UseRecoveryCode accepted a used code")
+	}
+
+	// Time steps are accepted in increasing order only.
+	for _, tt := range []struct {
+		step int64
+		want int64
+	}{{100, 1}, {100, 0}, {99, 0}, {101, 1}} {
+		if n, err := s.UseTOTPStep(ctx, database.UseTOTPStepParams{Step: tt.step, ID: user.ID}); err != nil || n != tt.want {
+			t.Errorf("UseTOTPStep(%d) = %d, %v; want %d", tt.step, n, err, tt.want)
+		}
+	}
+
+	expiresAt := time.Now().Add(time.Minute)
+	mfa := database.UseMFATokenParams{Jti: "jti1", UserID: user.ID, ExpiresAt: expiresAt}
+	if n, err := s.UseMFAToken(ctx, mfa); err != nil || n != 1 {
+		t.Fatalf("UseMFAToken = %d, %v; want 1", n, err)
+	}
+	if n, _ := s.UseMFAToken(ctx, mfa); n != 0 {
+		t.Errorf("UseMFAToken accepted a used token")
+	}
+	if err := s.DeleteExpiredMFATokens(ctx, expiresAt.Add(time.Second)); err != nil {
+		t.Fatalf("DeleteExpiredMFATokens returned error: %v", err)
+	}
+	if n, _ := s.UseMFAToken(ctx, mfa); n != 1 {
+		t.Errorf("DeleteExpiredMFATokens kept an expired token")
+	}
+
+	if err := s.DisableUserTOTP(ctx, user.ID); err != nil {
+		t.Fatalf("DisableUserTOTP returned error: %v", err)
+	}
+	if err := s.DeleteRecoveryCodesForUser(ctx, user.ID); err != nil {
+		t.Fatalf("DeleteRecoveryCodesForUser returned error: %v", err)
+	}
+	got, _ = s.GetUserFromUserID(ctx, user.ID)
+	if got.TotpSecret.Valid || got.TotpEnabledAt.Valid || got.TotpLastStep.Valid {
+		t.Errorf("DisableUserTOTP left secret %v, enabled %v, last step %v", got.TotpSecret, got.TotpEnabledAt, got.TotpLastStep)
+	}
+}
+
+func TestStore_PasswordResetTokens(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	user, err := s.CreateUser(ctx, database.CreateUserParams{Email: "a@example.com", HashedPassword: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser returned error: %v", err)
+	}
+
+	for _, tok := range []struct {
+		hash      string
+		expiresAt time.Time
+	}{
+		{"live", time.Now().Add(time.Hour)},
+		{"expired", time.Now().Add(-time.Minute)},
+		{"other", time.Now().Add(time.Hour)},
+	} {
+		err := s.CreatePasswordResetToken(ctx, database.CreatePasswordResetTokenParams{
+			TokenHash: tok.hash,
+			UserID:    user.ID,
+			ExpiresAt: tok.expiresAt,
+		})
+		if err != nil {
+			t.Fatalf("CreatePasswordResetToken returned error: %v", err)
+		}
+	}
+
+	// Looking a token up leaves it usable.
+	for i := 0; i < 2; i++ {
+		if got, err := s.GetPasswordResetTokenUser(ctx, database.GetPasswordResetTokenUserParams{TokenHash: "live", Now: time.Now()}); err != nil || got != user.ID {
+			t.Fatalf("GetPasswordResetTokenUser = %v, %v; want %v", got, err, user.ID)
+		}
+	}
+	if _, err := s.GetPasswordResetTokenUser(ctx, database.GetPasswordResetTokenUserParams{TokenHash: "expired", Now: time.Now()}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetPasswordResetTokenUser on an expired token: err %v, want sql.ErrNoRows", err)
+	}
+
+	if got, err := s.ConsumePasswordResetToken(ctx, database.ConsumePasswordResetTokenParams{Now: time.Now(), TokenHash: "live"}); err != nil || got != user.ID {
+		t.Fatalf("ConsumePasswordResetToken = %v, %v; want %v", got, err, user.ID)
+	}
+	if _, err := s.ConsumePasswordResetToken(ctx, database.ConsumePasswordResetTokenParams{Now: time.Now(), TokenHash: "live"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("ConsumePasswordResetToken on a used token: err %v, want sql.ErrNoRows", err)
+	}
+	if _, err := s.GetPasswordResetTokenUser(ctx, database.GetPasswordResetTokenUserParams{TokenHash: "live", Now: time.Now()}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetPasswordResetTokenUser on a used token: err %v, want sql.ErrNoRows", err)
+	}
+	if _, err := s.ConsumePasswordResetToken(ctx, database.ConsumePasswordResetTokenParams{Now: time.Now(), TokenHash: "expired"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("ConsumePasswordResetToken on an expired token: err %v, want sql.ErrNoRows", err)
+	}
+
+	if err := s.InvalidatePasswordResetTokensForUser(ctx, user.ID); err != nil {
+		t.Fatalf("InvalidatePasswordResetTokensForUser returned error: %v", err)
+	}
+	if _, err := s.ConsumePasswordResetToken(ctx, database.ConsumePasswordResetTokenParams{Now: time.Now(), TokenHash: "other"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("ConsumePasswordResetToken after invalidation: err %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestStore_EmailVerification(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	user, err := s.CreateUser(ctx, database.CreateUserParams{Email: "a@example.com", HashedPassword: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser returned error: %v", err)
+	}
+	if user.EmailVerifiedAt.Valid {
+		t.Fatalf("new user is already verified")
+	}
+
+	err = s.CreateEmailVerificationToken(ctx, database.CreateEmailVerificationTokenParams{
+		TokenHash: "verify",
+		UserID:    user.ID,
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("CreateEmailVerificationToken returned error: %v", err)
+	}
+
+	row, err := s.ConsumeEmailVerificationToken(ctx, database.ConsumeEmailVerificationTokenParams{Now: time.Now(), TokenHash: "verify"})
+	if err != nil || row.UserID != user.ID || row.Email != user.Email {
+		t.Fatalf("ConsumeEmailVerificationToken = %+v, %v", row, err)
+	}
+	if _, err := s.ConsumeEmailVerificationToken(ctx, database.ConsumeEmailVerificationTokenParams{Now: time.Now(), TokenHash: "verify"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("ConsumeEmailVerificationToken on a used token: err %v, want sql.ErrNoRows", err)
+	}
+
+	if n, _ := s.MarkEmailVerified(ctx, database.MarkEmailVerifiedParams{ID: user.ID, Email: "b@example.com"}); n != 0 {
+		t.Errorf("MarkEmailVerified verified a different address")
+	}
+	if n, err := s.MarkEmailVerified(ctx, database.MarkEmailVerifiedParams{ID: user.ID, Email: user.Email}); err != nil || n != 1 {
+		t.Fatalf("MarkEmailVerified = %d, %v; want 1", n, err)
+	}
+	if got, _ := s.GetUserFromUserID(ctx, user.ID); !got.EmailVerifiedAt.Valid {
+		t.Errorf("user is not verified after MarkEmailVerified")
+	}
+}
+
+func TestStore_UserProfiles(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	a, err := s.CreateUser(ctx, database.CreateUserParams{Email: "a@example.com", HashedPassword: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser returned error: %v", err)
+	}
+	b, err := s.CreateUser(ctx, database.CreateUserParams{Email: "b@example.com", HashedPassword: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser returned error: %v", err)
+	}
+
+	handle := sql.NullString{String: "saul", Valid: true}
+	err = s.SetUserProfile(ctx, database.SetUserProfileParams{Handle: handle, DisplayName: "Saul Goodman", Bio: "Better call", ID: a.ID})
+	if err != nil {
+		t.Fatalf("SetUserProfile returned error: %v", err)
+	}
+	if err := s.SetUserProfile(ctx, database.SetUserProfileParams{Handle: handle, ID: b.ID}); !database.IsUniqueViolation(err) {
+		t.Errorf("SetUserProfile with a taken handle returned %v, want a unique violation", err)
+	}
+	// Users without a handle do not collide with each other.
+	if err := s.SetUserProfile(ctx, database.SetUserProfileParams{DisplayName: "B", ID: b.ID}); err != nil {
+		t.Errorf("SetUserProfile without a handle returned error: %v", err)
+	}
+
+	got, err := s.GetUserFromHandle(ctx, handle)
+	if err != nil || got.ID != a.ID || got.DisplayName != "Saul Goodman" || got.Bio != "Better call" {
+		t.Fatalf("GetUserFromHandle = %+v, %v", got, err)
+	}
+
+	// Uploading sets the key; setting another URL by hand drops it.
+	if err := s.SetUserAvatar(ctx, database.SetUserAvatarParams{AvatarUrl: "http://x/a_400.jpg", AvatarKey: "avatars/a", ID: a.ID}); err != nil {
+		t.Fatalf("SetUserAvatar returned error: %v", err)
+	}
+	profile := database.SetUserProfileParams{Handle: handle, DisplayName: "Saul Goodman", AvatarUrl: "http://x/a_400.jpg", ID: a.ID}
+	if err := s.SetUserProfile(ctx, profile); err != nil {
+		t.Fatalf("SetUserProfile returned error: %v", err)
+	}
+	if got, _ := s.GetUserFromUserID(ctx, a.ID); got.AvatarKey != "avatars/a" {
+		t.Errorf("avatar_key = %q after a profile update that kept the avatar", got.AvatarKey)
+	}
+	profile.AvatarUrl = "https://elsewhere.example/me.png"
+	if err := s.SetUserProfile(ctx, profile); err != nil {
+		t.Fatalf("SetUserProfile returned error: %v", err)
+	}
+	if got, _ := s.GetUserFromUserID(ctx, a.ID); got.AvatarKey != "" {
+		t.Errorf("avatar_key = %q after replacing the avatar URL, want empty", got.AvatarKey)
+	}
+
+	list, err := s.ListUsersByIDs(ctx, []uuid.UUID{a.ID, b.ID, uuid.New()})
+	if err != nil || len(list) != 2 {
+		t.Fatalf("ListUsersByIDs returned %d users, %v; want 2", len(list), err)
+	}
+}
+
+func TestStore_PatchUser(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	a, err := s.CreateUser(ctx, database.CreateUserParams{Email: "a@example.com", HashedPassword: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser returned error: %v", err)
+	}
+	if _, err := s.CreateUser(ctx, database.CreateUserParams{Email: "b@example.com", HashedPassword: "hash"}); err != nil {
+		t.Fatalf("CreateUser returned error: %v", err)
+	}
+	if _, err := s.MarkEmailVerified(ctx, database.MarkEmailVerifiedParams{ID: a.ID, Email: a.Email}); err != nil {
+		t.Fatalf("MarkEmailVerified returned error: %v", err)
+	}
+
+	// Without email and password only the profile changes.
+	handle := sql.NullString{String: "saul", Valid: true}
+	patch := database.PatchUserParams{Handle: handle, DisplayName: "Saul Goodman", ID: a.ID}
+	if err := s.PatchUser(ctx, patch); err != nil {
+		t.Fatalf("PatchUser returned error: %v", err)
+	}
+	if got, _ := s.GetUserFromUserID(ctx, a.ID); got.Handle != handle || got.Email != "a@example.com" || got.HashedPassword != "hash" || !got.EmailVerifiedAt.Valid {
+		t.Errorf("user after a profile patch = %+v", got)
+	}
+
+	// A conflict on the email leaves every field as it was.
+	patch.Bio = "Better call"
+	patch.Email = sql.NullString{String: "b@example.com", Valid: true}
+	patch.HashedPassword = sql.NullString{String: "new hash", Valid: true}
+	if err := s.PatchUser(ctx, patch); !database.IsUniqueViolation(err) {
+		t.Fatalf("PatchUser with a taken email returned %v, want a unique violation", err)
+	}
+	if got, _ := s.GetUserFromUserID(ctx, a.ID); got.Bio != "" || got.Email != "a@example.com" || got.HashedPassword != "hash" {
+		t.Errorf("user after a failed patch = %+v", got)
+	}
+
+	patch.Email.String = "saul@example.com"
+	if err := s.PatchUser(ctx, patch); err != nil {
+		t.Fatalf("PatchUser returned error: %v", err)
+	}
+	if got, _ := s.GetUserFromUserID(ctx, a.ID); got.Bio != "Better call" || got.Email != "saul@example.com" || got.HashedPassword != "new hash" || got.EmailVerifiedAt.Valid {
+		t.Errorf("user after a full patch = %+v", got)
+	}
+}
+
+func TestStore_AccountDeletion(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	user, err := s.CreateUser(ctx, database.CreateUserParams{Email: "a@example.com", HashedPassword: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser returned error: %v", err)
+	}
+	chirp, err := s.CreateChirp(ctx, database.CreateChirpParams{Body: "hello", UserID: user.ID})
+	if err != nil {
+		t.Fatalf("CreateChirp returned error: %v", err)
+	}
+	if err := s.CreateWebhookEvent(ctx, database.CreateWebhookEventParams{UserID: user.ID, Event: "user.upgraded", Payload: `{"event":"user.upgraded"}`}); err != nil {
+		t.Fatalf("CreateWebhookEvent returned error: %v", err)
+	}
+	if events, err := s.ListWebhookEventsByUser(ctx, user.ID); err != nil || len(events) != 1 || events[0].Event != "user.upgraded" {
+		t.Fatalf("ListWebhookEventsByUser = %+v, %v", events, err)
+	}
+
+	later := sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+	if err := s.ScheduleUserDeletion(ctx, database.ScheduleUserDeletionParams{DeleteAfter: later, ID: user.ID}); err != nil {
+		t.Fatalf("ScheduleUserDeletion returned error: %v", err)
+	}
+	if rows, err := s.PurgeDeletedUsers(ctx, time.Now()); err != nil || len(rows) != 0 {
+		t.Fatalf("PurgeDeletedUsers during the grace period = %+v, %v", rows, err)
+	}
+	if chirps, err := s.ListChirpsDesc(ctx, database.ListChirpsDescParams{Limit: 10}); err != nil || len(chirps) != 0 {
+		t.Errorf("ListChirpsDesc during the grace period = %+v, %v; want none", chirps, err)
+	}
+	if err := s.CancelUserDeletion(ctx, user.ID); err != nil {
+		t.Fatalf("CancelUserDeletion returned error: %v", err)
+	}
+	if chirps, err := s.ListChirpsDesc(ctx, database.ListChirpsDescParams{Limit: 10}); err != nil || len(chirps) != 1 {
+		t.Errorf("ListChirpsDesc after CancelUserDeletion = %+v, %v; want the chirp", chirps, err)
+	}
+	if got, _ := s.GetUserFromUserID(ctx, user.ID); got.DeleteAfter.Valid {
+		t.Errorf("delete_after still set after CancelUserDeletion")
+	}
+
+	past := sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+	if err := s.ScheduleUserDeletion(ctx, database.ScheduleUserDeletionParams{DeleteAfter: past, ID: user.ID}); err != nil {
+		t.Fatalf("ScheduleUserDeletion returned error: %v", err)
+	}
+	rows, err := s.PurgeDeletedUsers(ctx, time.Now())
+	if err != nil || len(rows) != 1 || rows[0].ID != user.ID {
+		t.Fatalf("PurgeDeletedUsers = %+v, %v; want the user", rows, err)
+	}
+	if _, err := s.GetChirp(ctx, chirp.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("chirp of a purged user: err %v, want sql.ErrNoRows", err)
+	}
+	if events, _ := s.ListWebhookEventsByUser(ctx, user.ID); len(events) != 0 {
+		t.Errorf("webhook events of a purged user still exist")
+	}
+}
+
+func TestStore_Follows(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	var ids []uuid.UUID
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		u, err := s.CreateUser(ctx, database.CreateUserParams{Email: email, HashedPassword: "hash"})
+		if err != nil {
+			t.Fatalf("CreateUser returned error: %v", err)
+		}
+		ids = append(ids, u.ID)
+	}
+	a, b, c := ids[0], ids[1], ids[2]
+
+	for _, followee := range []uuid.UUID{b, c, b} {
+		if _, err := s.FollowUser(ctx, database.FollowUserParams{FollowerID: a, FolloweeID: followee}); err != nil {
+			t.Fatalf("FollowUser returned error: %v", err)
+		}
+	}
+	if _, err := s.FollowUser(ctx, database.FollowUserParams{FollowerID: a, FolloweeID: a}); err == nil {
+		t.Errorf("FollowUser of oneself succeeded")
+	}
+	if n, err := s.CountFollowing(ctx, a); err != nil || n != 2 {
+		t.Errorf("CountFollowing = %d, %v; want 2", n, err)
+	}
+	if n, err := s.CountFollowers(ctx, b); err != nil || n != 1 {
+		t.Errorf("CountFollowers = %d, %v; want 1", n, err)
+	}
+
+	following, err := s.ListFollowing(ctx, database.ListFollowingParams{UserID: a, Limit: 10})
+	if err != nil || len(following) != 2 {
+		t.Fatalf("ListFollowing = %+v, %v", following, err)
+	}
+	last := following[0]
+	rest, err := s.ListFollowing(ctx, database.ListFollowingParams{
+		UserID:          a,
+		CursorCreatedAt: sql.NullTime{Time: last.FollowedAt, Valid: true},
+		CursorID:        uuid.NullUUID{UUID: last.ID, Valid: true},
+		Limit:           10,
+	})
+	if err != nil || len(rest) != 1 || rest[0].ID != following[1].ID {
+		t.Errorf("ListFollowing after the cursor = %+v, %v; want %v", rest, err, following[1].ID)
+	}
+	if followers, err := s.ListFollowers(ctx, database.ListFollowersParams{UserID: c, Limit: 10}); err != nil || len(followers) != 1 || followers[0].ID != a {
+		t.Errorf("ListFollowers = %+v, %v", followers, err)
+	}
+	if _, err := s.FollowUser(ctx, database.FollowUserParams{FollowerID: c, FolloweeID: a}); err != nil {
+		t.Fatalf("FollowUser returned error: %v", err)
+	}
+	if follows, err := s.ListFollowsByUser(ctx, a); err != nil || len(follows) != 3 {
+		t.Errorf("ListFollowsByUser = %+v, %v; want both directions", follows, err)
+	}
+
+	if n, err := s.UnfollowUser(ctx, database.UnfollowUserParams{FollowerID: a, FolloweeID: b}); err != nil || n != 1 {
+		t.Errorf("UnfollowUser = %d, %v; want 1", n, err)
+	}
+	if err := s.DeleteAllUsers(ctx); err != nil {
+		t.Fatalf("DeleteAllUsers returned error: %v", err)
+	}
+	if n, _ := s.CountFollowers(ctx, c); n != 0 {
+		t.Errorf("follows survived DeleteAllUsers")
+	}
+}
+
+func TestStore_Timeline(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	reader, err := s.CreateUser(ctx, database.CreateUserParams{Email: "a@example.com", HashedPassword: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser returned error: %v", err)
+	}
+	author, err := s.CreateUser(ctx, database.CreateUserParams{Email: "b@example.com", HashedPassword: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser returned error: %v", err)
+	}
+	old, err := s.CreateChirp(ctx, database.CreateChirpParams{Body: "before the follow", UserID: author.ID})
+	if err != nil {
+		t.Fatalf("CreateChirp returned error: %v", err)
+	}
+	if _, err := s.FollowUser(ctx, database.FollowUserParams{FollowerID: reader.ID, FolloweeID: author.ID}); err != nil {
+		t.Fatalf("FollowUser returned error: %v", err)
+	}
+	if err := s.BackfillTimeline(ctx, database.BackfillTimelineParams{UserID: reader.ID, FolloweeID: author.ID, Limit: 10}); err != nil {
+		t.Fatalf("BackfillTimeline returned error: %v", err)
+	}
+
+	chirp, err := s.CreateChirp(ctx, database.CreateChirpParams{Body: "after the follow", UserID: author.ID})
+	if err != nil {
+		t.Fatalf("CreateChirp returned error: %v", err)
+	}
+	pending, err := s.ListUnfannedTimelineChirps(ctx, database.ListUnfannedTimelineChirpsParams{UserID: reader.ID, Limit: 10})
+	if err != nil || len(pending) != 2 {
+		t.Fatalf("ListUnfannedTimelineChirps before fan-out = %+v, %v", pending, err)
+	}
+	if n, err := s.FanOutChirp(ctx, chirp.ID); err != nil || n != 1 {
+		t.Fatalf("FanOutChirp = %d, %v; want 1", n, err)
+	}
+	if err := s.MarkChirpFannedOut(ctx, chirp.ID); err != nil {
+		t.Fatalf("MarkChirpFannedOut returned error: %v", err)
+	}
+	if pending, _ := s.ListUnfannedTimelineChirps(ctx, database.ListUnfannedTimelineChirpsParams{UserID: reader.ID, Limit: 10}); len(pending) != 1 || pending[0].ID != old.ID {
+		t.Errorf("ListUnfannedTimelineChirps after fan-out = %+v", pending)
+	}
+
+	entries, err := s.ListTimelineEntries(ctx, database.ListTimelineEntriesParams{UserID: reader.ID, Limit: 10})
+	if err != nil || len(entries) != 2 || entries[0].ID != chirp.ID || entries[1].ID != old.ID {
+		t.Fatalf("ListTimelineEntries = %+v, %v", entries, err)
+	}
+	rest, err := s.ListTimelineEntries(ctx, database.ListTimelineEntriesParams{
+		UserID:          reader.ID,
+		CursorCreatedAt: sql.NullTime{Time: entries[0].CreatedAt, Valid: true},
+		CursorID:        uuid.NullUUID{UUID: entries[0].ID, Valid: true},
+		Limit:           10,
+	})
+	if err != nil || len(rest) != 1 || rest[0].ID != old.ID {
+		t.Errorf("ListTimelineEntries after the cursor = %+v, %v", rest, err)
+	}
+
+	// Rebuilding keeps only fanned-out chirps; the old one is still served on read.
+	if err := s.DeleteAllTimelineEntries(ctx); err != nil {
+		t.Fatalf("DeleteAllTimelineEntries returned error: %v", err)
+	}
+	if n, err := s.RebuildTimelineEntries(ctx); err != nil || n != 1 {
+		t.Errorf("RebuildTimelineEntries = %d, %v; want 1", n, err)
+	}
+	if unfanned, err := s.ListUnfannedChirps(ctx); err != nil || len(unfanned) != 1 || unfanned[0].ID != old.ID {
+		t.Errorf("ListUnfannedChirps = %+v, %v", unfanned, err)
+	}
+
+	if err := s.DeleteTimelineEntriesByAuthor(ctx, database.DeleteTimelineEntriesByAuthorParams{UserID: reader.ID, AuthorID: author.ID}); err != nil {
+		t.Fatalf("DeleteTimelineEntriesByAuthor returned error: %v", err)
+	}
+	if entries, _ := s.ListTimelineEntries(ctx, database.ListTimelineEntriesParams{UserID: reader.ID, Limit: 10}); len(entries) != 0 {
+		t.Errorf("timeline entries left after DeleteTimelineEntriesByAuthor: %+v", entries)
+	}
+}
+
+func TestStore_Replies(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	user, err := s.CreateUser(ctx, database.CreateUserParams{Email: "a@example.com", HashedPassword: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser returned error: %v", err)
+	}
+	reply := func(body string, parent database.Chirp) database.Chirp {
+		t.Helper()
+		c, err := s.CreateChirp(ctx, database.CreateChirpParams{
+			Body:           body,
+			UserID:         user.ID,
+			InReplyToID:    uuid.NullUUID{UUID: parent.ID, Valid: true},
+			ConversationID: uuid.NullUUID{UUID: parent.ConversationID, Valid: true},
+		})
+		if err != nil {
+			t.Fatalf("CreateChirp(%q) returned error: %v", body, err)
+		}
+		return c
+	}
+
+	root, err := s.CreateChirp(ctx, database.CreateChirpParams{Body: "root", UserID: user.ID})
+	if err != nil {
+		t.Fatalf("CreateChirp returned error: %v", err)
+	}
+	if root.ConversationID != root.ID || root.InReplyToID.Valid {
+		t.Errorf("root chirp = %+v, want its own conversation", root)
+	}
+	a := reply("a", root)
+	b := reply("b", root)
+	a1 := reply("a1", a)
+	a11 := reply("a11", a1)
+	if a11.ConversationID != root.ID {
+		t.Errorf("conversation of a nested reply = %v, want %v", a11.ConversationID, root.ID)
+	}
+
+	ancestors, err := s.ListChirpAncestors(ctx, database.ListChirpAncestorsParams{ID: a11.ID, MaxDepth: 10})
+	if err != nil || len(ancestors) != 3 || ancestors[0].ID != root.ID || ancestors[2].ID != a1.ID {
+		t.Fatalf("ListChirpAncestors = %+v, %v", ancestors, err)
+	}
+	if ancestors, _ := s.ListChirpAncestors(ctx, database.ListChirpAncestorsParams{ID: a11.ID, MaxDepth: 1}); len(ancestors) != 1 || ancestors[0].ID != a1.ID {
+		t.Errorf("ListChirpAncestors with depth 1 = %+v", ancestors)
+	}
+
+	replies, err := s.ListReplies(ctx, database.ListRepliesParams{ID: root.ID, Limit: 1})
+	if err != nil || len(replies) != 1 || replies[0].ID != a.ID {
+		t.Fatalf("ListReplies = %+v, %v", replies, err)
+	}
+	rest, err := s.ListReplies(ctx, database.ListRepliesParams{
+		ID:              root.ID,
+		CursorCreatedAt: sql.NullTime{Time: replies[0].CreatedAt, Valid: true},
+		CursorID:        uuid.NullUUID{UUID: replies[0].ID, Valid: true},
+		Limit:           10,
+	})
+	if err != nil || len(rest) != 1 || rest[0].ID != b.ID {
+		t.Errorf("ListReplies after the cursor = %+v, %v", rest, err)
+	}
+
+	descendants, err := s.ListDescendants(ctx, database.ListDescendantsParams{
+		ID:             root.ID,
+		FirstCreatedAt: a.CreatedAt,
+		FirstID:        a.ID,
+		LastCreatedAt:  a.CreatedAt,
+		LastID:         a.ID,
+		MaxDepth:       2,
+		Limit:          10,
+	})
+	if err != nil || len(descendants) != 1 || descendants[0].ID != a1.ID {
+		t.Errorf("ListDescendants = %+v, %v", descendants, err)
+	}
+
+	if n, err := s.CountReplies(ctx, root.ID); err != nil || n != 2 {
+		t.Errorf("CountReplies = %d, %v; want 2", n, err)
+	}
+	counts, err := s.CountRepliesByIDs(ctx, []uuid.UUID{root.ID, a.ID, b.ID})
+	if err != nil || len(counts) != 2 {
+		t.Fatalf("CountRepliesByIDs = %+v, %v", counts, err)
+	}
+	for _, c := range counts {
+		if want := map[uuid.UUID]int64{root.ID: 2, a.ID: 1}[c.InReplyToID.UUID]; c.ReplyCount != want {
+			t.Errorf("reply count of %v = %d, want %d", c.InReplyToID.UUID, c.ReplyCount, want)
+		}
+	}
+
+	// A tombstone keeps its place in the thread but leaves the chirp lists.
+	if err := s.TombstoneChirp(ctx, a.ID); err != nil {
+		t.Fatalf("TombstoneChirp returned error: %v", err)
+	}
+	if got, err := s.GetChirp(ctx, a.ID); err != nil || !got.DeletedAt.Valid || got.Body != "" {
+		t.Errorf("tombstoned chirp = %+v, %v", got, err)
+	}
+	if chirps, _ := s.ListChirpsAsc(ctx, database.ListChirpsAscParams{Limit: 10}); len(chirps) != 4 {
+		t.Errorf("ListChirpsAsc returned %d chirps, want 4 without the tombstone", len(chirps))
+	}
+
+	// Deleting a parent outright detaches its replies.
+	if err := s.DeleteChirp(ctx, a1.ID); err != nil {
+		t.Fatalf("DeleteChirp returned error: %v", err)
+	}
+	if got, err := s.GetChirp(ctx, a11.ID); err != nil || got.InReplyToID.Valid {
+		t.Errorf("reply to a deleted chirp = %+v, %v", got, err)
+	}
+}
+
+func TestStore_Likes(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	user, err := s.CreateUser(ctx, database.CreateUserParams{Email: "a@example.com", HashedPassword: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser returned error: %v", err)
+	}
+	var chirps []database.Chirp
+	for _, body := range []string{"first", "second"} {
+		c, err := s.CreateChirp(ctx, database.CreateChirpParams{Body: body, UserID: user.ID})
+		if err != nil {
+			t.Fatalf("CreateChirp returned error: %v", err)
+		}
+		chirps = append(chirps, c)
+	}
+
+	for _, c := range chirps {
+		if n, err := s.LikeChirp(ctx, database.LikeChirpParams{UserID: user.ID, ChirpID: c.ID}); err != nil || n != 1 {
+			t.Fatalf("LikeChirp = %d, %v; want 1", n, err)
+		}
+	}
+	if n, err := s.LikeChirp(ctx, database.LikeChirpParams{UserID: user.ID, ChirpID: chirps[0].ID}); err != nil || n != 0 {
+		t.Errorf("second LikeChirp = %d, %v; want 0", n, err)
+	}
+
+	ids := []uuid.UUID{chirps[0].ID, chirps[1].ID}
+	counts, err := s.CountLikesByIDs(ctx, ids)
+	if err != nil || len(counts) != 2 || counts[0].LikeCount != 1 {
+		t.Errorf("CountLikesByIDs = %+v, %v", counts, err)
+	}
+	if liked, err := s.ListLikedChirpIDs(ctx, database.ListLikedChirpIDsParams{UserID: user.ID, Ids: ids}); err != nil || len(liked) != 2 {
+		t.Errorf("ListLikedChirpIDs = %+v, %v", liked, err)
+	}
+
+	liked, err := s.ListLikedChirps(ctx, database.ListLikedChirpsParams{UserID: user.ID, Limit: 1})
+	if err != nil || len(liked) != 1 || liked[0].Chirp.ID != chirps[1].ID {
+		t.Fatalf("ListLikedChirps = %+v, %v", liked, err)
+	}
+	rest, err := s.ListLikedChirps(ctx, database.ListLikedChirpsParams{
+		UserID:          user.ID,
+		CursorCreatedAt: sql.NullTime{Time: liked[0].LikedAt, Valid: true},
+		CursorID:        uuid.NullUUID{UUID: liked[0].Chirp.ID, Valid: true},
+		Limit:           10,
+	})
+	if err != nil || len(rest) != 1 || rest[0].Chirp.ID != chirps[0].ID || rest[0].Chirp.Body != "first" {
+		t.Errorf("ListLikedChirps after the cursor = %+v, %v", rest, err)
+	}
+	if likes, err := s.ListLikesByUser(ctx, user.ID); err != nil || len(likes) != 2 || likes[0].ChirpID != chirps[0].ID {
+		t.Errorf("ListLikesByUser = %+v, %v", likes, err)
+	}
+
+	if n, err := s.UnlikeChirp(ctx, database.UnlikeChirpParams{UserID: user.ID, ChirpID: chirps[0].ID}); err != nil || n != 1 {
+		t.Errorf("UnlikeChirp = %d, %v; want 1", n, err)
+	}
+	// Deleting a chirp deletes its likes.
+	if err := s.DeleteChirp(ctx, chirps[1].ID); err != nil {
+		t.Fatalf("DeleteChirp returned error: %v", err)
+	}
+	if counts, _ := s.CountLikesByIDs(ctx, ids); len(counts) != 0 {
+		t.Errorf("likes left: %+v", counts)
+	}
+}
+
+func TestStore_Rechirps(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	user, err := s.CreateUser(ctx, database.CreateUserParams{Email: "a@example.com", HashedPassword: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser returned error: %v", err)
+	}
+	original, err := s.CreateChirp(ctx, database.CreateChirpParams{Body: "original", UserID: user.ID})
+	if err != nil {
+		t.Fatalf("CreateChirp returned error: %v", err)
+	}
+
+	rechirp, err := s.CreateRechirp(ctx, database.CreateRechirpParams{UserID: user.ID, RechirpOfID: original.ID})
+	if err != nil || rechirp.RechirpOfID.UUID != original.ID || rechirp.Body != "" || rechirp.ConversationID != rechirp.ID {
+		t.Fatalf("CreateRechirp = %+v, %v", rechirp, err)
+	}
+	if _, err := s.CreateRechirp(ctx, database.CreateRechirpParams{UserID: user.ID, RechirpOfID: original.ID}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("second CreateRechirp error = %v, want sql.ErrNoRows", err)
+	}
+	if got, err := s.GetRechirp(ctx, database.GetRechirpParams{UserID: user.ID, RechirpOfID: original.ID}); err != nil || got.ID != rechirp.ID {
+		t.Errorf("GetRechirp = %+v, %v", got, err)
+	}
+
+	quote, err := s.CreateChirp(ctx, database.CreateChirpParams{
+		Body:     "quote",
+		UserID:   user.ID,
+		QuotedID: uuid.NullUUID{UUID: original.ID, Valid: true},
+	})
+	if err != nil || quote.QuotedID.UUID != original.ID {
+		t.Fatalf("CreateChirp with a quote = %+v, %v", quote, err)
+	}
+
+	ids := []uuid.UUID{original.ID, quote.ID}
+	if rows, err := s.CountRechirpsByIDs(ctx, ids); err != nil || len(rows) != 1 || rows[0].RechirpOfID.UUID != original.ID || rows[0].RechirpCount != 1 {
+		t.Errorf("CountRechirpsByIDs = %+v, %v", rows, err)
+	}
+	if rows, err := s.CountQuotesByIDs(ctx, ids); err != nil || len(rows) != 1 || rows[0].QuotedID.UUID != original.ID || rows[0].QuoteCount != 1 {
+		t.Errorf("CountQuotesByIDs = %+v, %v", rows, err)
+	}
+	if got, err := s.ListChirpsByIDs(ctx, ids); err != nil || len(got) != 2 {
+		t.Errorf("ListChirpsByIDs = %+v, %v", got, err)
+	}
+
+	if n, err := s.DeleteRechirp(ctx, database.DeleteRechirpParams{UserID: user.ID, RechirpOfID: original.ID}); err != nil || n != 1 {
+		t.Errorf("DeleteRechirp = %d, %v; want 1", n, err)
+	}
+	if _, err := s.CreateRechirp(ctx, database.CreateRechirpParams{UserID: user.ID, RechirpOfID: original.ID}); err != nil {
+		t.Fatalf("CreateRechirp after undoing returned error: %v", err)
+	}
+	if err := s.DeleteRechirpsOf(ctx, original.ID); err != nil {
+		t.Fatalf("DeleteRechirpsOf returned error: %v", err)
+	}
+	if rows, _ := s.CountRechirpsByIDs(ctx, ids); len(rows) != 0 {
+		t.Errorf("rechirps left after DeleteRechirpsOf: %+v", rows)
+	}
+
+	// Deleting the original keeps the quote and its reference.
+	if err := s.DeleteChirp(ctx, original.ID); err != nil {
+		t.Fatalf("DeleteChirp returned error: %v", err)
+	}
+	if got, err := s.GetChirp(ctx, quote.ID); err != nil || got.QuotedID.UUID != original.ID {
+		t.Errorf("quote after deleting the original = %+v, %v", got, err)
+	}
+}
+
+func TestStore_LoginAttempts(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	if _, err := s.GetLoginAttempt(ctx, "email:a@example.com"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("GetLoginAttempt on an unknown key: err %v, want sql.ErrNoRows", err)
+	}
+
+	record := database.RecordLoginFailureParams{Key: "email:a@example.com", Now: time.Now(), ResetBefore: time.Now().Add(-time.Hour)}
+	for want := int32(1); want <= 3; want++ {
+		a, err := s.RecordLoginFailure(ctx, record)
+		if err != nil {
+			t.Fatalf("RecordLoginFailure returned error: %v", err)
+		}
+		if a.Failures != want {
+			t.Fatalf("RecordLoginFailure failures = %d, want %d", a.Failures, want)
+		}
+	}
+
+	// A failure after the window starts a new count.
+	a, err := s.RecordLoginFailure(ctx, database.RecordLoginFailureParams{Key: record.Key, Now: time.Now(), ResetBefore: time.Now().Add(time.Minute)})
+	if err != nil || a.Failures != 1 {
+		t.Fatalf("RecordLoginFailure after the window = %d, %v; want 1", a.Failures, err)
+	}
+
+	lockedUntil := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	err = s.LockLogin(ctx, database.LockLoginParams{LockedUntil: sql.NullTime{Time: lockedUntil, Valid: true}, Key: record.Key})
+	if err != nil {
+		t.Fatalf("LockLogin returned error: %v", err)
+	}
+	if a, _ := s.GetLoginAttempt(ctx, record.Key); !a.LockedUntil.Time.Equal(lockedUntil) {
+		t.Errorf("locked_until = %v, want %v", a.LockedUntil, lockedUntil)
+	}
+
+	if err := s.ClearLoginAttempts(ctx, record.Key); err != nil {
+		t.Fatalf("ClearLoginAttempts returned error: %v", err)
+	}
+	if _, err := s.GetLoginAttempt(ctx, record.Key); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetLoginAttempt after clear: err %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestStore_PasswordResetAttempts(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	record := database.RecordPasswordResetRequestParams{Key: "email:a@example.com", Now: time.Now(), ResetBefore: time.Now().Add(-time.Hour)}
+	for want := int32(1); want <= 2; want++ {
+		if a, err := s.RecordPasswordResetRequest(ctx, record); err != nil || a.Requests != want {
+			t.Fatalf("RecordPasswordResetRequest = %d, %v; want %d", a.Requests, err, want)
+		}
+	}
+	a, err := s.RecordPasswordResetRequest(ctx, database.RecordPasswordResetRequestParams{Key: record.Key, Now: time.Now(), ResetBefore: time.Now().Add(time.Minute)})
+	if err != nil || a.Requests != 1 {
+		t.Fatalf("RecordPasswordResetRequest after the window = %d, %v; want 1", a.Requests, err)
+	}
+
+	lockedUntil := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	err = s.LockPasswordReset(ctx, database.LockPasswordResetParams{LockedUntil: sql.NullTime{Time: lockedUntil, Valid: true}, Key: record.Key})
+	if err != nil {
+		t.Fatalf("LockPasswordReset returned error: %v", err)
+	}
+	if a, _ := s.GetPasswordResetAttempt(ctx, record.Key); !a.LockedUntil.Time.Equal(lockedUntil) {
+		t.Errorf("locked_until = %v, want %v", a.LockedUntil, lockedUntil)
+	}
+
+	// The same key in login_attempts is a separate counter.
+	if _, err := s.GetLoginAttempt(ctx, record.Key); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetLoginAttempt after reset requests: err %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestStore_ListChirpsKeyset(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	alice, _ := s.CreateUser(ctx, database.CreateUserParams{Email: "alice@example.com", HashedPassword: "hash"})
+	bob, _ := s.CreateUser(ctx, database.CreateUserParams{Email: "bob@example.com", HashedPassword: "hash"})
+	for i := 0; i < 4; i++ {
+		for _, u := range []database.User{alice, bob} {
+			if _, err := s.CreateChirp(ctx, database.CreateChirpParams{Body: "hello", UserID: u.ID}); err != nil {
+				t.Fatalf("CreateChirp returned error: %v", err)
+			}
+		}
+	}
+
+	all, err := s.ListChirpsAsc(ctx, database.ListChirpsAscParams{Limit: 100})
+	if err != nil {
+		t.Fatalf("ListChirpsAsc returned error: %v", err)
+	}
+	if len(all) != 8 {
+		t.Fatalf("ListChirpsAsc returned %d chirps, want 8", len(all))
+	}
+
+	var desc []database.Chirp
+	arg := database.ListChirpsDescParams{Limit: 3}
+	for {
+		page, err := s.ListChirpsDesc(ctx, arg)
+		if err != nil {
+			t.Fatalf("ListChirpsDesc returned error: %v", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		desc = append(desc, page...)
+		last := page[len(page)-1]
+		arg.CursorCreatedAt = sql.NullTime{Time: last.CreatedAt, Valid: true}
+		arg.CursorID = uuid.NullUUID{UUID: last.ID, Valid: true}
+	}
+	if len(desc) != len(all) {
+		t.Fatalf("descending page walk returned %d chirps, want %d", len(desc), len(all))
+	}
+	for i := range all {
+		if desc[i].ID != all[len(all)-1-i].ID {
+			t.Fatalf("descending page walk differs at %d", i)
+		}
+	}
+
+	byAlice, err := s.ListChirpsAsc(ctx, database.ListChirpsAscParams{AuthorID: uuid.NullUUID{UUID: alice.ID, Valid: true}, Limit: 100})
+	if err != nil {
+		t.Fatalf("ListChirpsAsc by author returned error: %v", err)
+	}
+	if len(byAlice) != 4 {
+		t.Errorf("ListChirpsAsc by author returned %d chirps, want 4", len(byAlice))
+	}
+}
@@ -0,0 +1,288 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: timeline.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+const backfillTimeline = `-- name: BackfillTimeline :exec
+INSERT OR IGNORE INTO timeline_entries (user_id, chirp_id, author_id, created_at)
+SELECT CAST(?1 AS TEXT), chirps.id, chirps.user_id, chirps.created_at
+FROM chirps
+WHERE chirps.user_id = ?2 AND chirps.deleted_at IS NULL
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT ?3
+`
+
+type BackfillTimelineParams struct {
+	UserID     string
+	FolloweeID uuid.UUID
+	Limit      int64
+}
+
+// Copies the latest chirps of a newly followed account into the follower's timeline.
+func (q *Queries) BackfillTimeline(ctx context.Context, arg BackfillTimelineParams) error {
+	_, err := q.db.ExecContext(ctx, backfillTimeline, arg.UserID, arg.FolloweeID, arg.Limit)
+	return err
+}
+
+const deleteAllTimelineEntries = `-- name: DeleteAllTimelineEntries :exec
+DELETE FROM timeline_entries
+`
+
+func (q *Queries) DeleteAllTimelineEntries(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteAllTimelineEntries)
+	return err
+}
+
+const deleteTimelineEntriesByAuthor = `-- name: DeleteTimelineEntriesByAuthor :exec
+DELETE FROM timeline_entries
+WHERE user_id = ? AND author_id = ?
+`
+
+type DeleteTimelineEntriesByAuthorParams struct {
+	UserID   uuid.UUID
+	AuthorID uuid.UUID
+}
+
+func (q *Queries) DeleteTimelineEntriesByAuthor(ctx context.Context, arg DeleteTimelineEntriesByAuthorParams) error {
+	_, err := q.db.ExecContext(ctx, deleteTimelineEntriesByAuthor, arg.UserID, arg.AuthorID)
+	return err
+}
+
+const deleteTimelineEntriesForChirp = `-- name: DeleteTimelineEntriesForChirp :exec
+DELETE FROM timeline_entries
+WHERE chirp_id = ?
+`
+
+func (q *Queries) DeleteTimelineEntriesForChirp(ctx context.Context, chirpID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteTimelineEntriesForChirp, chirpID)
+	return err
+}
+
+const fanOutChirp = `-- name: FanOutChirp :execrows
+INSERT OR IGNORE INTO timeline_entries (user_id, chirp_id, author_id, created_at)
+SELECT follows.follower_id, chirps.id, chirps.user_id, chirps.created_at
+FROM chirps
+JOIN follows ON follows.followee_id = chirps.user_id
+WHERE chirps.id = ?
+`
+
+// Copies a chirp into the timeline of every follower of its author.
+func (q *Queries) FanOutChirp(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, fanOutChirp, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listTimelineEntries = `-- name: ListTimelineEntries :many
+SELECT id, created_at, updated_at, body, user_id, fanned_out_at, in_reply_to_id, conversation_id, deleted_at, rechirp_of_id, quoted_id FROM chirps
+WHERE id IN (
+  SELECT chirp_id FROM timeline_entries
+  WHERE timeline_entries.user_id = ?1
+    AND timeline_entries.author_id NOT IN (SELECT users.id FROM users WHERE users.delete_after IS NOT NULL)
+    AND (
+      timeline_entries.created_at < ?2
+      OR (timeline_entries.created_at = ?2 AND timeline_entries.chirp_id < CAST(?3 AS TEXT))
+      OR ?2 IS NULL
+    )
+  ORDER BY timeline_entries.created_at DESC, timeline_entries.chirp_id DESC
+  LIMIT ?4
+)
+ORDER BY created_at DESC, id DESC
+`
+
+type ListTimelineEntriesParams struct {
+	UserID          uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        sql.NullString
+	Limit           int64
+}
+
+// One page of the materialized timeline, newest first, without accounts waiting for deletion.
+func (q *Queries) ListTimelineEntries(ctx context.Context, arg ListTimelineEntriesParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listTimelineEntries,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.FannedOutAt,
+			&i.InReplyToID,
+			&i.ConversationID,
+			&i.DeletedAt,
+			&i.RechirpOfID,
+			&i.QuotedID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnfannedChirps = `-- name: ListUnfannedChirps :many
+SELECT id, created_at, updated_at, body, user_id, fanned_out_at, in_reply_to_id, conversation_id, deleted_at, rechirp_of_id, quoted_id FROM chirps
+WHERE fanned_out_at IS NULL
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListUnfannedChirps(ctx context.Context) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listUnfannedChirps)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.FannedOutAt,
+			&i.InReplyToID,
+			&i.ConversationID,
+			&i.DeletedAt,
+			&i.RechirpOfID,
+			&i.QuotedID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnfannedTimelineChirps = `-- name: ListUnfannedTimelineChirps :many
+SELECT id, created_at, updated_at, body, user_id, fanned_out_at, in_reply_to_id, conversation_id, deleted_at, rechirp_of_id, quoted_id FROM chirps
+WHERE chirps.fanned_out_at IS NULL
+  AND chirps.deleted_at IS NULL
+  AND chirps.user_id IN (SELECT followee_id FROM follows WHERE follower_id = ?1)
+  AND chirps.user_id NOT IN (SELECT users.id FROM users WHERE users.delete_after IS NOT NULL)
+  AND (
+    chirps.created_at < ?2
+    OR (chirps.created_at = ?2 AND chirps.id < CAST(?3 AS TEXT))
+    OR ?2 IS NULL
+  )
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT ?4
+`
+
+type ListUnfannedTimelineChirpsParams struct {
+	UserID          uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        sql.NullString
+	Limit           int64
+}
+
+// Chirps of followed accounts that are not fanned out, newest first: those of
+// accounts above the fan-out threshold and those still waiting for fan-out.
+func (q *Queries) ListUnfannedTimelineChirps(ctx context.Context, arg ListUnfannedTimelineChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listUnfannedTimelineChirps,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.FannedOutAt,
+			&i.InReplyToID,
+			&i.ConversationID,
+			&i.DeletedAt,
+			&i.RechirpOfID,
+			&i.QuotedID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markChirpFannedOut = `-- name: MarkChirpFannedOut :exec
+UPDATE chirps
+SET fanned_out_at = ?1
+WHERE id = ?2
+`
+
+type MarkChirpFannedOutParams struct {
+	Now sql.NullTime
+	ID  uuid.UUID
+}
+
+func (q *Queries) MarkChirpFannedOut(ctx context.Context, arg MarkChirpFannedOutParams) error {
+	_, err := q.db.ExecContext(ctx, markChirpFannedOut, arg.Now, arg.ID)
+	return err
+}
+
+const rebuildTimelineEntries = `-- name: RebuildTimelineEntries :execrows
+INSERT OR IGNORE INTO timeline_entries (user_id, chirp_id, author_id, created_at)
+SELECT follows.follower_id, chirps.id, chirps.user_id, chirps.created_at
+FROM follows
+JOIN chirps ON chirps.user_id = follows.followee_id
+WHERE chirps.fanned_out_at IS NOT NULL AND chirps.deleted_at IS NULL
+`
+
+// Materializes every fanned-out chirp into the timelines of its author's current followers.
+func (q *Queries) RebuildTimelineEntries(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rebuildTimelineEntries)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,682 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: users.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const cancelUserDeletion = `-- name: CancelUserDeletion :exec
+UPDATE users
+SET
+    updated_at = ?1,
+    delete_after = NULL
+WHERE id = ?2
+`
+
+type CancelUserDeletionParams struct {
+	Now time.Time
+	ID  uuid.UUID
+}
+
+func (q *Queries) CancelUserDeletion(ctx context.Context, arg CancelUserDeletionParams) error {
+	_, err := q.db.ExecContext(ctx, cancelUserDeletion, arg.Now, arg.ID)
+	return err
+}
+
+const createUser = `-- name: CreateUser :one
+INSERT INTO users (id, created_at, updated_at, email, hashed_password)
+VALUES (
+    ?1,
+    ?2,
+    ?2,
+    ?3,
+    ?4
+)
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, tokens_valid_after, role, totp_secret, totp_enabled_at, email_verified_at, handle, display_name, bio, avatar_url, avatar_key, header_url, header_key, delete_after, totp_last_step
+`
+
+type CreateUserParams struct {
+	ID             uuid.UUID
+	Now            time.Time
+	Email          string
+	HashedPassword string
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, createUser,
+		arg.ID,
+		arg.Now,
+		arg.Email,
+		arg.HashedPassword,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.TokensValidAfter,
+		&i.Role,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.EmailVerifiedAt,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.AvatarKey,
+		&i.HeaderUrl,
+		&i.HeaderKey,
+		&i.DeleteAfter,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const deleteAllUsers = `-- name: DeleteAllUsers :exec
+DELETE FROM users
+`
+
+func (q *Queries) DeleteAllUsers(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteAllUsers)
+	return err
+}
+
+const disableUserTOTP = `-- name: DisableUserTOTP :exec
+UPDATE users
+SET
+    updated_at = ?1,
+    totp_secret = NULL,
+    totp_enabled_at = NULL,
+    totp_last_step = NULL
+WHERE id = ?2
+`
+
+type DisableUserTOTPParams struct {
+	Now time.Time
+	ID  uuid.UUID
+}
+
+func (q *Queries) DisableUserTOTP(ctx context.Context, arg DisableUserTOTPParams) error {
+	_, err := q.db.ExecContext(ctx, disableUserTOTP, arg.Now, arg.ID)
+	return err
+}
+
+const enableUserTOTP = `-- name: EnableUserTOTP :exec
+UPDATE users
+SET
+    updated_at = ?1,
+    totp_enabled_at = ?1
+WHERE id = ?2 AND totp_secret IS NOT NULL
+`
+
+type EnableUserTOTPParams struct {
+	Now time.Time
+	ID  uuid.UUID
+}
+
+func (q *Queries) EnableUserTOTP(ctx context.Context, arg EnableUserTOTPParams) error {
+	_, err := q.db.ExecContext(ctx, enableUserTOTP, arg.Now, arg.ID)
+	return err
+}
+
+const getUserFromEmail = `-- name: GetUserFromEmail :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, tokens_valid_after, role, totp_secret, totp_enabled_at, email_verified_at, handle, display_name, bio, avatar_url, avatar_key, header_url, header_key, delete_after, totp_last_step FROM users
+WHERE email = ?
+`
+
+func (q *Queries) GetUserFromEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserFromEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.TokensValidAfter,
+		&i.Role,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.EmailVerifiedAt,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.AvatarKey,
+		&i.HeaderUrl,
+		&i.HeaderKey,
+		&i.DeleteAfter,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const getUserFromHandle = `-- name: GetUserFromHandle :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, tokens_valid_after, role, totp_secret, totp_enabled_at, email_verified_at, handle, display_name, bio, avatar_url, avatar_key, header_url, header_key, delete_after, totp_last_step FROM users
+WHERE handle = ?
+`
+
+func (q *Queries) GetUserFromHandle(ctx context.Context, handle sql.NullString) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserFromHandle, handle)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.TokensValidAfter,
+		&i.Role,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.EmailVerifiedAt,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.AvatarKey,
+		&i.HeaderUrl,
+		&i.HeaderKey,
+		&i.DeleteAfter,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const getUserFromUserID = `-- name: GetUserFromUserID :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, tokens_valid_after, role, totp_secret, totp_enabled_at, email_verified_at, handle, display_name, bio, avatar_url, avatar_key, header_url, header_key, delete_after, totp_last_step FROM users
+WHERE id = ?
+`
+
+func (q *Queries) GetUserFromUserID(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserFromUserID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.TokensValidAfter,
+		&i.Role,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.EmailVerifiedAt,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.AvatarKey,
+		&i.HeaderUrl,
+		&i.HeaderKey,
+		&i.DeleteAfter,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const listUsersByIDs = `-- name: ListUsersByIDs :many
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, tokens_valid_after, role, totp_secret, totp_enabled_at, email_verified_at, handle, display_name, bio, avatar_url, avatar_key, header_url, header_key, delete_after, totp_last_step FROM users
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) ListUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]User, error) {
+	query := listUsersByIDs
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			&i.TokensValidAfter,
+			&i.Role,
+			&i.TotpSecret,
+			&i.TotpEnabledAt,
+			&i.EmailVerifiedAt,
+			&i.Handle,
+			&i.DisplayName,
+			&i.Bio,
+			&i.AvatarUrl,
+			&i.AvatarKey,
+			&i.HeaderUrl,
+			&i.HeaderKey,
+			&i.DeleteAfter,
+			&i.TotpLastStep,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markEmailVerified = `-- name: MarkEmailVerified :execrows
+UPDATE users
+SET
+    updated_at = ?1,
+    email_verified_at = ?1
+WHERE id = ?2 AND email = ?3
+`
+
+type MarkEmailVerifiedParams struct {
+	Now   time.Time
+	ID    uuid.UUID
+	Email string
+}
+
+func (q *Queries) MarkEmailVerified(ctx context.Context, arg MarkEmailVerifiedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markEmailVerified, arg.Now, arg.ID, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const patchUser = `-- name: PatchUser :exec
+UPDATE users
+SET
+    updated_at = ?1,
+    handle = ?2,
+    display_name = ?3,
+    bio = ?4,
+    avatar_url = ?5,
+    avatar_key = CASE WHEN avatar_url = ?5 THEN avatar_key ELSE '' END,
+    email = COALESCE(CAST(?6 AS TEXT), email),
+    email_verified_at = CASE WHEN CAST(?6 AS TEXT) IS NULL THEN email_verified_at ELSE NULL END,
+    hashed_password = COALESCE(CAST(?7 AS TEXT), hashed_password)
+WHERE id = ?8
+`
+
+type PatchUserParams struct {
+	Now            time.Time
+	Handle         sql.NullString
+	DisplayName    string
+	Bio            string
+	AvatarUrl      string
+	Email          sql.NullString
+	HashedPassword sql.NullString
+	ID             uuid.UUID
+}
+
+// PATCH /api/users in one statement. The profile fields are always written; email and
+// hashed_password only when not NULL. A new address has to be verified again.
+func (q *Queries) PatchUser(ctx context.Context, arg PatchUserParams) error {
+	_, err := q.db.ExecContext(ctx, patchUser,
+		arg.Now,
+		arg.Handle,
+		arg.DisplayName,
+		arg.Bio,
+		arg.AvatarUrl,
+		arg.Email,
+		arg.HashedPassword,
+		arg.ID,
+	)
+	return err
+}
+
+const purgeDeletedUsers = `-- name: PurgeDeletedUsers :many
+DELETE FROM users
+WHERE delete_after <= ?1
+RETURNING id, avatar_key, header_key
+`
+
+type PurgeDeletedUsersRow struct {
+	ID        uuid.UUID
+	AvatarKey string
+	HeaderKey string
+}
+
+// Everything the user owns goes with the row (ON DELETE CASCADE); uploads are returned for cleanup.
+func (q *Queries) PurgeDeletedUsers(ctx context.Context, now sql.NullTime) ([]PurgeDeletedUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, purgeDeletedUsers, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PurgeDeletedUsersRow
+	for rows.Next() {
+		var i PurgeDeletedUsersRow
+		if err := rows.Scan(&i.ID, &i.AvatarKey, &i.HeaderKey); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rehashUserPassword = `-- name: RehashUserPassword :execrows
+UPDATE users
+SET hashed_password = ?1
+WHERE id = ?2 AND hashed_password = ?3
+`
+
+type RehashUserPasswordParams struct {
+	NewHash string
+	ID      uuid.UUID
+	OldHash string
+}
+
+// Compare-and-set: a password changed since the old hash was read is left alone.
+func (q *Queries) RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rehashUserPassword, arg.NewHash, arg.ID, arg.OldHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const scheduleUserDeletion = `-- name: ScheduleUserDeletion :exec
+UPDATE users
+SET
+    updated_at = ?1,
+    delete_after = ?2
+WHERE id = ?3
+`
+
+type ScheduleUserDeletionParams struct {
+	Now         time.Time
+	DeleteAfter sql.NullTime
+	ID          uuid.UUID
+}
+
+func (q *Queries) ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) error {
+	_, err := q.db.ExecContext(ctx, scheduleUserDeletion, arg.Now, arg.DeleteAfter, arg.ID)
+	return err
+}
+
+const setChirpyRed = `-- name: SetChirpyRed :exec
+UPDATE users
+SET
+    updated_at = ?1,
+    is_chirpy_red = ?2
+WHERE id = ?3
+`
+
+type SetChirpyRedParams struct {
+	Now         time.Time
+	IsChirpyRed bool
+	ID          uuid.UUID
+}
+
+func (q *Queries) SetChirpyRed(ctx context.Context, arg SetChirpyRedParams) error {
+	_, err := q.db.ExecContext(ctx, setChirpyRed, arg.Now, arg.IsChirpyRed, arg.ID)
+	return err
+}
+
+const setTokensValidAfter = `-- name: SetTokensValidAfter :exec
+UPDATE users
+SET
+    updated_at = ?1,
+    tokens_valid_after = ?2
+WHERE id = ?3
+`
+
+type SetTokensValidAfterParams struct {
+	Now              time.Time
+	TokensValidAfter sql.NullTime
+	ID               uuid.UUID
+}
+
+func (q *Queries) SetTokensValidAfter(ctx context.Context, arg SetTokensValidAfterParams) error {
+	_, err := q.db.ExecContext(ctx, setTokensValidAfter, arg.Now, arg.TokensValidAfter, arg.ID)
+	return err
+}
+
+const setUserAvatar = `-- name: SetUserAvatar :exec
+UPDATE users
+SET
+    updated_at = ?1,
+    avatar_url = ?2,
+    avatar_key = ?3
+WHERE id = ?4
+`
+
+type SetUserAvatarParams struct {
+	Now       time.Time
+	AvatarUrl string
+	AvatarKey string
+	ID        uuid.UUID
+}
+
+func (q *Queries) SetUserAvatar(ctx context.Context, arg SetUserAvatarParams) error {
+	_, err := q.db.ExecContext(ctx, setUserAvatar,
+		arg.Now,
+		arg.AvatarUrl,
+		arg.AvatarKey,
+		arg.ID,
+	)
+	return err
+}
+
+const setUserEmail = `-- name: SetUserEmail :exec
+UPDATE users
+SET
+    updated_at = ?1,
+    email = ?2,
+    email_verified_at = NULL
+WHERE id = ?3
+`
+
+type SetUserEmailParams struct {
+	Now   time.Time
+	Email string
+	ID    uuid.UUID
+}
+
+// A new address has to be verified again.
+func (q *Queries) SetUserEmail(ctx context.Context, arg SetUserEmailParams) error {
+	_, err := q.db.ExecContext(ctx, setUserEmail, arg.Now, arg.Email, arg.ID)
+	return err
+}
+
+const setUserHeader = `-- name: SetUserHeader :exec
+UPDATE users
+SET
+    updated_at = ?1,
+    header_url = ?2,
+    header_key = ?3
+WHERE id = ?4
+`
+
+type SetUserHeaderParams struct {
+	Now       time.Time
+	HeaderUrl string
+	HeaderKey string
+	ID        uuid.UUID
+}
+
+func (q *Queries) SetUserHeader(ctx context.Context, arg SetUserHeaderParams) error {
+	_, err := q.db.ExecContext(ctx, setUserHeader,
+		arg.Now,
+		arg.HeaderUrl,
+		arg.HeaderKey,
+		arg.ID,
+	)
+	return err
+}
+
+const setUserPassword = `-- name: SetUserPassword :exec
+UPDATE users
+SET
+    updated_at = ?1,
+    hashed_password = ?2
+WHERE id = ?3
+`
+
+type SetUserPasswordParams struct {
+	Now            time.Time
+	HashedPassword string
+	ID             uuid.UUID
+}
+
+func (q *Queries) SetUserPassword(ctx context.Context, arg SetUserPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, setUserPassword, arg.Now, arg.HashedPassword, arg.ID)
+	return err
+}
+
+const setUserProfile = `-- name: SetUserProfile :exec
+UPDATE users
+SET
+    updated_at = ?1,
+    handle = ?2,
+    display_name = ?3,
+    bio = ?4,
+    avatar_url = ?5,
+    -- An avatar URL set by hand replaces the uploaded one.
+    avatar_key = CASE WHEN avatar_url = ?5 THEN avatar_key ELSE '' END
+WHERE id = ?6
+`
+
+type SetUserProfileParams struct {
+	Now         time.Time
+	Handle      sql.NullString
+	DisplayName string
+	Bio         string
+	AvatarUrl   string
+	ID          uuid.UUID
+}
+
+func (q *Queries) SetUserProfile(ctx context.Context, arg SetUserProfileParams) error {
+	_, err := q.db.ExecContext(ctx, setUserProfile,
+		arg.Now,
+		arg.Handle,
+		arg.DisplayName,
+		arg.Bio,
+		arg.AvatarUrl,
+		arg.ID,
+	)
+	return err
+}
+
+const setUserRole = `-- name: SetUserRole :exec
+UPDATE users
+SET
+    updated_at = ?1,
+    role = ?2
+WHERE id = ?3
+`
+
+type SetUserRoleParams struct {
+	Now  time.Time
+	Role string
+	ID   uuid.UUID
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) error {
+	_, err := q.db.ExecContext(ctx, setUserRole, arg.Now, arg.Role, arg.ID)
+	return err
+}
+
+const setUserTOTPSecret = `-- name: SetUserTOTPSecret :exec
+UPDATE users
+SET
+    updated_at = ?1,
+    totp_secret = ?2,
+    totp_enabled_at = NULL,
+    totp_last_step = NULL
+WHERE id = ?3
+`
+
+type SetUserTOTPSecretParams struct {
+	Now        time.Time
+	TotpSecret sql.NullString
+	ID         uuid.UUID
+}
+
+func (q *Queries) SetUserTOTPSecret(ctx context.Context, arg SetUserTOTPSecretParams) error {
+	_, err := q.db.ExecContext(ctx, setUserTOTPSecret, arg.Now, arg.TotpSecret, arg.ID)
+	return err
+}
+
+const updateUser = `-- name: UpdateUser :exec
+UPDATE users
+SET
+    updated_at = ?1,
+    email = ?2,
+    hashed_password = ?3,
+    email_verified_at = CASE WHEN email = ?2 THEN email_verified_at END
+WHERE id = ?4
+`
+
+type UpdateUserParams struct {
+	Now            time.Time
+	Email          string
+	HashedPassword string
+	ID             uuid.UUID
+}
+
+// A new address has to be verified again.
+func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
+	_, err := q.db.ExecContext(ctx, updateUser,
+		arg.Now,
+		arg.Email,
+		arg.HashedPassword,
+		arg.ID,
+	)
+	return err
+}
+
+const useTOTPStep = `-- name: UseTOTPStep :execrows
+UPDATE users
+SET totp_last_step = CAST(?1 AS INTEGER)
+WHERE id = ?2 AND (totp_last_step IS NULL OR totp_last_step < ?1)
+`
+
+type UseTOTPStepParams struct {
+	Step int64
+	ID   uuid.UUID
+}
+
+// Accepts each time step once: a code for a step at or before the last accepted one is a replay.
+func (q *Queries) UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, useTOTPStep, arg.Step, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,78 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: webhook_events.sql
+
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createWebhookEvent = `-- name: CreateWebhookEvent :exec
+INSERT INTO webhook_events (id, created_at, user_id, event, payload)
+VALUES (
+    ?1,
+    ?2,
+    ?3,
+    ?4,
+    ?5
+)
+`
+
+type CreateWebhookEventParams struct {
+	ID      uuid.UUID
+	Now     time.Time
+	UserID  uuid.UUID
+	Event   string
+	Payload string
+}
+
+func (q *Queries) CreateWebhookEvent(ctx context.Context, arg CreateWebhookEventParams) error {
+	_, err := q.db.ExecContext(ctx, createWebhookEvent,
+		arg.ID,
+		arg.Now,
+		arg.UserID,
+		arg.Event,
+		arg.Payload,
+	)
+	return err
+}
+
+const listWebhookEventsByUser = `-- name: ListWebhookEventsByUser :many
+SELECT id, created_at, user_id, event, payload FROM webhook_events
+WHERE user_id = ?
+ORDER BY created_at
+`
+
+func (q *Queries) ListWebhookEventsByUser(ctx context.Context, userID uuid.UUID) ([]WebhookEvent, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookEventsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookEvent
+	for rows.Next() {
+		var i WebhookEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UserID,
+			&i.Event,
+			&i.Payload,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
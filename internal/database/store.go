@@ -0,0 +1,12 @@
+package database
+
+// Store is the persistence layer used by the HTTP handlers.
+// It is implemented by the sqlc generated *Queries (Postgres), *MemoryStore and sqlite.Store.
+type Store interface {
+	Querier
+}
+
+var (
+	_ Store = (*Queries)(nil)
+	_ Store = (*MemoryStore)(nil)
+)
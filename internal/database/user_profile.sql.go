@@ -0,0 +1,208 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: user_profile.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const getUserFromHandle = `-- name: GetUserFromHandle :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, tokens_valid_after, role, totp_secret, totp_enabled_at, email_verified_at, handle, display_name, bio, avatar_url, avatar_key, header_url, header_key, delete_after, totp_last_step FROM users
+WHERE handle = $1
+`
+
+func (q *Queries) GetUserFromHandle(ctx context.Context, handle sql.NullString) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserFromHandle, handle)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.TokensValidAfter,
+		&i.Role,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.EmailVerifiedAt,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.AvatarKey,
+		&i.HeaderUrl,
+		&i.HeaderKey,
+		&i.DeleteAfter,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const listUsersByIDs = `-- name: ListUsersByIDs :many
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, tokens_valid_after, role, totp_secret, totp_enabled_at, email_verified_at, handle, display_name, bio, avatar_url, avatar_key, header_url, header_key, delete_after, totp_last_step FROM users
+WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) ListUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			&i.TokensValidAfter,
+			&i.Role,
+			&i.TotpSecret,
+			&i.TotpEnabledAt,
+			&i.EmailVerifiedAt,
+			&i.Handle,
+			&i.DisplayName,
+			&i.Bio,
+			&i.AvatarUrl,
+			&i.AvatarKey,
+			&i.HeaderUrl,
+			&i.HeaderKey,
+			&i.DeleteAfter,
+			&i.TotpLastStep,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const patchUser = `-- name: PatchUser :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    handle = $1,
+    display_name = $2,
+    bio = $3,
+    avatar_url = $4,
+    avatar_key = CASE WHEN avatar_url = $4 THEN avatar_key ELSE '' END,
+    email = COALESCE($5::text, email),
+    email_verified_at = CASE WHEN $5::text IS NULL THEN email_verified_at ELSE NULL END,
+    hashed_password = COALESCE($6::text, hashed_password)
+WHERE id = $7
+`
+
+type PatchUserParams struct {
+	Handle         sql.NullString
+	DisplayName    string
+	Bio            string
+	AvatarUrl      string
+	Email          sql.NullString
+	HashedPassword sql.NullString
+	ID             uuid.UUID
+}
+
+// PATCH /api/users in one statement. The profile fields are always written; email and
+// hashed_password only when not NULL. A new address has to be verified again.
+func (q *Queries) PatchUser(ctx context.Context, arg PatchUserParams) error {
+	_, err := q.db.ExecContext(ctx, patchUser,
+		arg.Handle,
+		arg.DisplayName,
+		arg.Bio,
+		arg.AvatarUrl,
+		arg.Email,
+		arg.HashedPassword,
+		arg.ID,
+	)
+	return err
+}
+
+const setUserAvatar = `-- name: SetUserAvatar :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    avatar_url = $1,
+    avatar_key = $2
+WHERE id = $3
+`
+
+type SetUserAvatarParams struct {
+	AvatarUrl string
+	AvatarKey string
+	ID        uuid.UUID
+}
+
+func (q *Queries) SetUserAvatar(ctx context.Context, arg SetUserAvatarParams) error {
+	_, err := q.db.ExecContext(ctx, setUserAvatar, arg.AvatarUrl, arg.AvatarKey, arg.ID)
+	return err
+}
+
+const setUserHeader = `-- name: SetUserHeader :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    header_url = $1,
+    header_key = $2
+WHERE id = $3
+`
+
+type SetUserHeaderParams struct {
+	HeaderUrl string
+	HeaderKey string
+	ID        uuid.UUID
+}
+
+func (q *Queries) SetUserHeader(ctx context.Context, arg SetUserHeaderParams) error {
+	_, err := q.db.ExecContext(ctx, setUserHeader, arg.HeaderUrl, arg.HeaderKey, arg.ID)
+	return err
+}
+
+const setUserProfile = `-- name: SetUserProfile :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    handle = $1,
+    display_name = $2,
+    bio = $3,
+    avatar_url = $4,
+    -- An avatar URL set by hand replaces the uploaded one.
+    avatar_key = CASE WHEN avatar_url = $4 THEN avatar_key ELSE '' END
+WHERE id = $5
+`
+
+type SetUserProfileParams struct {
+	Handle      sql.NullString
+	DisplayName string
+	Bio         string
+	AvatarUrl   string
+	ID          uuid.UUID
+}
+
+func (q *Queries) SetUserProfile(ctx context.Context, arg SetUserProfileParams) error {
+	_, err := q.db.ExecContext(ctx, setUserProfile,
+		arg.Handle,
+		arg.DisplayName,
+		arg.Bio,
+		arg.AvatarUrl,
+		arg.ID,
+	)
+	return err
+}
@@ -18,7 +18,7 @@ VALUES (
     $1,
     $2
 )
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, tokens_valid_after, role, totp_secret, totp_enabled_at, email_verified_at, handle, display_name, bio, avatar_url, avatar_key, header_url, header_key, delete_after, totp_last_step
 `
 
 type CreateUserParams struct {
@@ -36,6 +36,20 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.TokensValidAfter,
+		&i.Role,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.EmailVerifiedAt,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.AvatarKey,
+		&i.HeaderUrl,
+		&i.HeaderKey,
+		&i.DeleteAfter,
+		&i.TotpLastStep,
 	)
 	return i, err
 }
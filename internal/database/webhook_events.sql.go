@@ -0,0 +1,69 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: webhook_events.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const createWebhookEvent = `-- name: CreateWebhookEvent :exec
+INSERT INTO webhook_events (id, created_at, user_id, event, payload)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3
+)
+`
+
+type CreateWebhookEventParams struct {
+	UserID  uuid.UUID
+	Event   string
+	Payload string
+}
+
+func (q *Queries) CreateWebhookEvent(ctx context.Context, arg CreateWebhookEventParams) error {
+	_, err := q.db.ExecContext(ctx, createWebhookEvent, arg.UserID, arg.Event, arg.Payload)
+	return err
+}
+
+const listWebhookEventsByUser = `-- name: ListWebhookEventsByUser :many
+SELECT id, created_at, user_id, event, payload FROM webhook_events
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListWebhookEventsByUser(ctx context.Context, userID uuid.UUID) ([]WebhookEvent, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookEventsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookEvent
+	for rows.Next() {
+		var i WebhookEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UserID,
+			&i.Event,
+			&i.Payload,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,117 @@
+// Package imaging decodes untrusted image uploads and re-encodes them as fixed-size JPEG thumbnails.
+// Re-encoding drops every metadata block (EXIF, GPS, ICC, comments) and anything appended to the file.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// MaxPixels bounds the decoded size, so a small file cannot expand into gigabytes of pixels.
+const MaxPixels = 40_000_000
+
+const jpegQuality = 85
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrTooManyPixels     = errors.New("image dimensions are too large")
+)
+
+// Sniff identifies the format from the magic bytes: "jpeg", "png", "gif", "webp" or "".
+func Sniff(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return "jpeg"
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return "png"
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return "gif"
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return "webp"
+	}
+	return ""
+}
+
+// Image is a decoded upload together with the EXIF orientation it was stored with.
+type Image struct {
+	img         image.Image
+	orientation int
+}
+
+// Decode checks the magic bytes and dimensions before decoding. Animated GIFs keep their first frame.
+func Decode(data []byte) (*Image, error) {
+	format := Sniff(data)
+	if format == "" {
+		return nil, ErrUnsupportedFormat
+	}
+
+	cfg, decoded, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if decoded != format {
+		return nil, ErrUnsupportedFormat
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > MaxPixels/cfg.Height {
+		return nil, ErrTooManyPixels
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+	return &Image{img: img, orientation: orientation}, nil
+}
+
+// Thumbnail returns the image upright, center-cropped to the aspect ratio of width x height and
+// scaled to exactly that size. Transparent areas become white, since JPEG has no alpha channel.
+func (i *Image) Thumbnail(width, height int) image.Image {
+	// Orientations 5-8 turn the picture by 90 degrees: crop and scale the stored
+	// image to the swapped size, then rotate the small result.
+	w, h := width, height
+	if i.orientation >= 5 {
+		w, h = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), i.img, centerCrop(i.img.Bounds(), w, h), draw.Over, nil)
+	return orient(dst, i.orientation)
+}
+
+// centerCrop returns the largest rectangle in b with the aspect ratio w:h.
+func centerCrop(b image.Rectangle, w, h int) image.Rectangle {
+	cw, ch := b.Dx(), b.Dy()
+	if cw*h > ch*w {
+		cw = ch * w / h
+	} else {
+		ch = cw * h / w
+	}
+	if cw < 1 {
+		cw = 1
+	}
+	if ch < 1 {
+		ch = 1
+	}
+	min := b.Min.Add(image.Pt((b.Dx()-cw)/2, (b.Dy()-ch)/2))
+	return image.Rectangle{Min: min, Max: min.Add(image.Pt(cw, ch))}
+}
+
+// EncodeJPEG writes img as a baseline JPEG without metadata.
+func EncodeJPEG(w io.Writer, img image.Image) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+}
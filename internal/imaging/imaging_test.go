@@ -0,0 +1,156 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		data []byte
+		want string
+	}{
+		{[]byte{0xFF, 0xD8, 0xFF, 0xE0}, "jpeg"},
+		{[]byte("\x89PNG\r\n\x1a\n...."), "png"},
+		{[]byte("GIF89a..."), "gif"},
+		{[]byte("RIFF\x00\x00\x00\x00WEBPVP8 "), "webp"},
+		{[]byte("<svg xmlns=...>"), ""},
+		{[]byte("<html>"), ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := Sniff(tt.data); got != tt.want {
+			t.Errorf("Sniff(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	if _, err := Decode([]byte("<svg></svg>")); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Decode(svg) returned %v, want ErrUnsupportedFormat", err)
+	}
+	if _, err := Decode(pngHeader(100_000, 100_000)); !errors.Is(err, ErrTooManyPixels) {
+		t.Errorf("Decode(huge png) returned %v, want ErrTooManyPixels", err)
+	}
+	if _, err := Decode([]byte{0xFF, 0xD8, 0xFF, 0xE0, 0, 0}); err == nil {
+		t.Errorf("Decode(truncated jpeg) returned nil error")
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	// 40x20, transparent left half, opaque red right half.
+	src := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 20; x < 40; x++ {
+			src.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := Decode(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Decode returned error: %v", err)
+	}
+	thumb := img.Thumbnail(10, 5)
+	if b := thumb.Bounds(); b.Dx() != 10 || b.Dy() != 5 {
+		t.Fatalf("Thumbnail size = %v, want 10x5", b)
+	}
+	if r, g, b, _ := thumb.At(1, 2).RGBA(); r>>8 < 240 || g>>8 < 240 || b>>8 < 240 {
+		t.Errorf("transparent area = %v, want white", thumb.At(1, 2))
+	}
+
+	// A square crop of a 2:1 image keeps the middle.
+	square := img.Thumbnail(8, 8)
+	if b := square.Bounds(); b.Dx() != 8 || b.Dy() != 8 {
+		t.Fatalf("square Thumbnail size = %v, want 8x8", b)
+	}
+}
+
+func TestOrientationAndExifStripped(t *testing.T) {
+	// Stored sideways: red on the left, blue on the right, with "rotate 90 CW" in EXIF.
+	src := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= 20 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			src.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, src, nil); err != nil {
+		t.Fatal(err)
+	}
+	data := withExifOrientation(buf.Bytes(), 6)
+	if got := jpegOrientation(data); got != 6 {
+		t.Fatalf("jpegOrientation = %d, want 6", got)
+	}
+
+	img, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode returned error: %v", err)
+	}
+	thumb := img.Thumbnail(10, 20)
+	if b := thumb.Bounds(); b.Dx() != 10 || b.Dy() != 20 {
+		t.Fatalf("Thumbnail size = %v, want 10x20", b)
+	}
+	// Turned clockwise, the left (red) half ends up on top.
+	if r, _, b, _ := thumb.At(5, 3).RGBA(); r <= b {
+		t.Errorf("top of the upright image is %v, want red", thumb.At(5, 3))
+	}
+	if r, _, b, _ := thumb.At(5, 16).RGBA(); b <= r {
+		t.Errorf("bottom of the upright image is %v, want blue", thumb.At(5, 16))
+	}
+
+	var out bytes.Buffer
+	if err := EncodeJPEG(&out, thumb); err != nil {
+		t.Fatalf("EncodeJPEG returned error: %v", err)
+	}
+	if bytes.Contains(out.Bytes(), []byte("Exif")) {
+		t.Errorf("re-encoded JPEG still carries EXIF")
+	}
+}
+
+// withExifOrientation inserts an APP1 segment with a big-endian IFD0 holding only the orientation.
+func withExifOrientation(jpg []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1) // one entry
+	tiff = binary.BigEndian.AppendUint16(tiff, exifOrientationTag)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3) // SHORT
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0) // value padding, no next IFD
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, segment...)
+	return append(out, jpg[2:]...)
+}
+
+// pngHeader returns a PNG signature and IHDR chunk claiming the given size, with no pixel data.
+func pngHeader(width, height uint32) []byte {
+	ihdr := []byte("IHDR")
+	ihdr = binary.BigEndian.AppendUint32(ihdr, width)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, height)
+	ihdr = append(ihdr, 8, 6, 0, 0, 0) // 8-bit RGBA
+
+	out := []byte("\x89PNG\r\n\x1a\n")
+	out = binary.BigEndian.AppendUint32(out, uint32(len(ihdr)-4))
+	out = append(out, ihdr...)
+	return binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(ihdr))
+}
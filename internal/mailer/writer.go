@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+)
+
+const localFrom = "chirpy@localhost"
+
+// WriterMailer writes messages to w instead of delivering them, for local
+// development: links in the mail can be copied from the terminal or file.
+type WriterMailer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterMailer(w io.Writer) *WriterMailer {
+	return &WriterMailer{w: w}
+}
+
+// NewFileMailer appends messages to the file at path.
+func NewFileMailer(path string) (*WriterMailer, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriterMailer(f), nil
+}
+
+func (m *WriterMailer) Send(ctx context.Context, msg Message) error {
+	data, err := format(localFrom, msg)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.w.Write(data); err != nil {
+		return err
+	}
+	_, err = io.WriteString(m.w, "\r\n")
+	return err
+}
@@ -0,0 +1,47 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestEncodeDecodeCursor(t *testing.T) {
+	want := Cursor{
+		CreatedAt: time.Date(2025, 7, 1, 12, 30, 45, 123456000, time.UTC),
+		ID:        uuid.New(),
+	}
+
+	got, err := DecodeCursor(EncodeCursor(want))
+	if err != nil {
+		t.Fatalf("DecodeCursor returned error: %v", err)
+	}
+
+	if !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("DecodeCursor returned created_at %v, want %v", got.CreatedAt, want.CreatedAt)
+	}
+	if got.ID != want.ID {
+		t.Errorf("DecodeCursor returned id %v, want %v", got.ID, want.ID)
+	}
+}
+
+func TestDecodeCursor_TableDriven(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"missing separator", "bm9zZXBhcmF0b3I"},
+		{"bad timestamp", "eWVzdGVyZGF5fDNmYTg1ZjY0LTU3MTctNDU2Mi1iM2ZjLTJjOTYzZjY2YWZhNg"},
+		{"bad id", "MjAyNS0wNy0wMVQxMjozMDo0NVp8bm90LWEtdXVpZA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeCursor(tt.cursor); err == nil {
+				t.Fatalf("DecodeCursor(%q) expected error, got nil", tt.cursor)
+			}
+		})
+	}
+}
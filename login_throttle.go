@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Tadateki/Chirpy/internal/database"
+)
+
+// loginPolicy is the brute-force protection for /api/login. Counters live in
+// the login_attempts table so every instance sees the same failures.
+type loginPolicy struct {
+	freeFailures  int           // failures per key before backoff starts
+	backoffBase   time.Duration // wait after the first counted failure, doubled for each further one
+	backoffMax    time.Duration
+	maxFailures   int // failures per email before the account is locked
+	lockout       time.Duration
+	ipMaxFailures int           // failures per client IP before the IP is locked out
+	window        time.Duration // failures older than this are forgotten
+}
+
+func defaultLoginPolicy() loginPolicy {
+	return loginPolicy{
+		freeFailures:  3,
+		backoffBase:   time.Second,
+		backoffMax:    5 * time.Minute,
+		maxFailures:   10,
+		lockout:       15 * time.Minute,
+		ipMaxFailures: 100,
+		window:        time.Hour,
+	}
+}
+
+// backoff returns how long to wait after the given number of consecutive failures.
+func (p loginPolicy) backoff(failures int32) time.Duration {
+	n := int(failures) - p.freeFailures
+	if n <= 0 {
+		return 0
+	}
+	d := time.Duration(float64(p.backoffBase) * math.Pow(2, float64(n-1)))
+	if d > p.backoffMax || d <= 0 {
+		return p.backoffMax
+	}
+	return d
+}
+
+// loginKey is a login_attempts key. Per-email keys lock the account (423),
+// per-IP keys only throttle the client (429).
+type loginKey struct {
+	key         string
+	maxFailures int
+	isAccount   bool
+}
+
+func (cfg *apiConfig) loginKeys(email, ip string) []loginKey {
+	return []loginKey{
+		{key: loginEmailKey(email), maxFailures: cfg.loginPolicy.maxFailures, isAccount: true},
+		{key: "ip:" + ip, maxFailures: cfg.loginPolicy.ipMaxFailures},
+	}
+}
+
+func loginEmailKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// checkLoginThrottle returns 0 when a login attempt may proceed, otherwise the
+// status to answer with and how long the client has to wait.
+func (cfg *apiConfig) checkLoginThrottle(ctx context.Context, keys []loginKey) (int, time.Duration, error) {
+	now := time.Now()
+	for _, k := range keys {
+		a, err := cfg.dbQueries.GetLoginAttempt(ctx, k.key)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if a.LockedUntil.Valid && a.LockedUntil.Time.After(now) {
+			if k.isAccount {
+				return http.StatusLocked, a.LockedUntil.Time.Sub(now), nil
+			}
+			return http.StatusTooManyRequests, a.LockedUntil.Time.Sub(now), nil
+		}
+
+		if now.Sub(a.LastFailedAt) > cfg.loginPolicy.window {
+			continue
+		}
+		if until := a.LastFailedAt.Add(cfg.loginPolicy.backoff(a.Failures)); until.After(now) {
+			return http.StatusTooManyRequests, until.Sub(now), nil
+		}
+	}
+	return 0, 0, nil
+}
+
+// recordLoginFailure counts a failed attempt and locks keys that reached their limit.
+func (cfg *apiConfig) recordLoginFailure(ctx context.Context, keys []loginKey) error {
+	now := time.Now().UTC()
+	for _, k := range keys {
+		a, err := cfg.dbQueries.RecordLoginFailure(ctx, database.RecordLoginFailureParams{
+			Key:         k.key,
+			Now:         now,
+			ResetBefore: now.Add(-cfg.loginPolicy.window),
+		})
+		if err != nil {
+			return err
+		}
+		if int(a.Failures) < k.maxFailures {
+			continue
+		}
+		err = cfg.dbQueries.LockLogin(ctx, database.LockLoginParams{
+			LockedUntil: sql.NullTime{Time: now.Add(cfg.loginPolicy.lockout), Valid: true},
+			Key:         k.key,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func respondWithThrottle(w http.ResponseWriter, status int, retryAfter time.Duration) {
+	setRetryAfter(w, retryAfter)
+
+	if status == http.StatusLocked {
+		respondWithError(w, status, "account temporarily locked after too many failed logins")
+		return
+	}
+	respondWithError(w, status, "too many failed login attempts")
+}
+
+// setRetryAfter sets the Retry-After header in whole seconds, at least one.
+func setRetryAfter(w http.ResponseWriter, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+}
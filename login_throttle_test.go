@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestLoginPolicyBackoff(t *testing.T) {
+	p := defaultLoginPolicy()
+
+	tests := []struct {
+		failures int32
+		want     time.Duration
+	}{
+		{0, 0},
+		{3, 0},
+		{4, time.Second},
+		{5, 2 * time.Second},
+		{8, 16 * time.Second},
+		{30, p.backoffMax},
+		{1000, p.backoffMax},
+	}
+
+	for _, tt := range tests {
+		if got := p.backoff(tt.failures); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.loginPolicy.freeFailures = 100 // no backoff, only the lockout
+	cfg.loginPolicy.maxFailures = 3
+	h := cfg.routes()
+	signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+
+	creds := map[string]string{"email": "saul@bettercall.com", "password": "correct horse battery staple"}
+	wrong := map[string]string{"email": "saul@bettercall.com", "password": "wrong"}
+	for i := 0; i < 2; i++ {
+		if rec := doRequest(t, h, "POST", "/api/login", "", wrong, nil); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("wrong password: status %d, want 401", rec.Code)
+		}
+	}
+
+	// A success resets the counter.
+	if rec := doRequest(t, h, "POST", "/api/login", "", creds, nil); rec.Code != http.StatusOK {
+		t.Fatalf("login: status %d, want 200", rec.Code)
+	}
+	for i := 0; i < 3; i++ {
+		if rec := doRequest(t, h, "POST", "/api/login", "", wrong, nil); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("wrong password: status %d, want 401", rec.Code)
+		}
+	}
+
+	rec := doRequest(t, h, "POST", "/api/login", "", creds, nil)
+	if rec.Code != http.StatusLocked {
+		t.Fatalf("login on a locked account: status %d, want 423", rec.Code)
+	}
+	if s, err := strconv.Atoi(rec.Header().Get("Retry-After")); err != nil || s <= 0 {
+		t.Errorf("Retry-After = %q, want a positive number of seconds", rec.Header().Get("Retry-After"))
+	}
+
+	// Other accounts are unaffected.
+	signup(t, h, "kim@wexlermcgill.com", "correct horse battery staple")
+
+	if err := runAdminCommand(context.Background(), cfg.dbQueries, []string{"promote", "kim@wexlermcgill.com"}); err != nil {
+		t.Fatalf("promote: %v", err)
+	}
+	var admin loginResponse
+	if rec := doRequest(t, h, "POST", "/api/login", "", map[string]string{"email": "kim@wexlermcgill.com", "password": "correct horse battery staple"}, &admin); rec.Code != http.StatusOK {
+		t.Fatalf("login as admin: status %d", rec.Code)
+	}
+	user, _ := cfg.dbQueries.GetUserFromEmail(context.Background(), "saul@bettercall.com")
+	if rec := doRequest(t, h, "POST", "/admin/users/"+user.ID.String()+"/unlock", admin.Token, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("unlock: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if rec := doRequest(t, h, "POST", "/api/login", "", creds, nil); rec.Code != http.StatusOK {
+		t.Errorf("login after unlock: status %d, want 200", rec.Code)
+	}
+}
+
+func TestLoginBackoff(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.loginPolicy.freeFailures = 1
+	cfg.loginPolicy.backoffBase = time.Hour
+	h := cfg.routes()
+	signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+
+	// Unknown accounts are throttled the same way.
+	wrong := map[string]string{"email": "nobody@example.com", "password": "wrong"}
+	for i := 0; i < 2; i++ {
+		if rec := doRequest(t, h, "POST", "/api/login", "", wrong, nil); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("wrong password: status %d, want 401", rec.Code)
+		}
+	}
+	rec := doRequest(t, h, "POST", "/api/login", "", wrong, nil)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("login during backoff: status %d, want 429", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Errorf("429 without Retry-After")
+	}
+}
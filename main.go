@@ -3,14 +3,20 @@ package main
 // All code comments should be written in English.
 
 import (
-	"database/sql"
+	"context"
+	"encoding/base64"
+	"flag"
 	"log"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"sync/atomic"
+	"time"
 
-	"github.com/Tadateki/Chirpy/internal/database"
+	"github.com/Tadateki/Chirpy/internal/auth"
+	"github.com/Tadateki/Chirpy/internal/blobstore"
+	"github.com/Tadateki/Chirpy/internal/mailer"
 
 	"github.com/joho/godotenv"
 
@@ -19,6 +25,9 @@ import (
 
 const (
 	EventUserUpgraded = "user.upgraded"
+
+	// uploadsDir is below the directory served at /app/.
+	uploadsDir = "app/uploads"
 )
 
 func main() {
@@ -31,11 +40,152 @@ func main() {
 
 	// DB Connect
 	dbURL := os.Getenv("DB_URL")
-	db, err := sql.Open("postgres", dbURL)
+	dbQueries, db, err := openStore(dbURL)
 	if err != nil {
 		log.Fatal(err)
 	}
-	dbQueries := database.New(db)
+
+	// Subcommand: chirpy admin promote|demote <email>
+	if len(os.Args) > 1 && os.Args[1] == "admin" {
+		if err := runAdminCommand(context.Background(), dbQueries, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Subcommand: chirpy timeline rebuild
+	fanoutMaxFollowers := int64(defaultFanoutMaxFollowers)
+	if n, err := strconv.ParseInt(os.Getenv("TIMELINE_FANOUT_MAX_FOLLOWERS"), 10, 64); err == nil && n >= 0 {
+		fanoutMaxFollowers = n
+	}
+	if len(os.Args) > 1 && os.Args[1] == "timeline" {
+		if err := runTimelineCommand(context.Background(), dbQueries, fanoutMaxFollowers, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Subcommand: chirpy migrate up|down|status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(context.Background(), dbURL, db, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	autoMigrate := flag.Bool("migrate", false, "apply pending database migrations before starting the server")
+	flag.Parse()
+
+	// Schema version check
+	if err := ensureSchema(context.Background(), dbURL, db, *autoMigrate); err != nil {
+		log.Fatal(err)
+	}
+
+	// JWT signing keys (previous keys still verify tokens during rotation)
+	var jwtKeys *auth.Keyring
+	if path := os.Getenv("JWT_SIGNING_KEY"); path != "" {
+		previous := strings.FieldsFunc(os.Getenv("JWT_PREVIOUS_KEYS"), func(r rune) bool { return r == ',' })
+		jwtKeys, err = auth.LoadKeyring(path, previous)
+	} else if os.Getenv("PLATFORM") == "dev" {
+		log.Print("JWT_SIGNING_KEY is not set; using an ephemeral Ed25519 key, tokens will not survive a restart")
+		jwtKeys, err = auth.GenerateEd25519Keyring()
+	} else {
+		log.Fatal("JWT_SIGNING_KEY must be set (PLATFORM=dev falls back to an ephemeral key)")
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// HS256 tokens signed with SECRETSTRING are accepted only until an explicit deadline.
+	if s := os.Getenv("JWT_LEGACY_HS256_UNTIL"); s != "" {
+		until, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			log.Fatal("JWT_LEGACY_HS256_UNTIL must be an RFC 3339 time, e.g. 2025-07-01T00:00:00Z")
+		}
+		if os.Getenv("SECRETSTRING") == "" {
+			log.Fatal("JWT_LEGACY_HS256_UNTIL needs the old HS256 secret in SECRETSTRING")
+		}
+		if until.After(time.Now()) {
+			log.Printf("accepting legacy HS256 access tokens until %s", until.UTC().Format(time.RFC3339))
+		}
+		jwtKeys.WithLegacySecret(os.Getenv("SECRETSTRING"), until)
+	}
+
+	// TOTP secrets are stored encrypted, so they survive a DB dump but not a lost key.
+	var totpKey []byte
+	if encoded := os.Getenv("TOTP_ENCRYPTION_KEY"); encoded != "" {
+		totpKey, err = base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(totpKey) != 32 {
+			log.Fatal("TOTP_ENCRYPTION_KEY must be 32 bytes encoded in base64")
+		}
+	} else {
+		log.Print("TOTP_ENCRYPTION_KEY is not set; two-factor enrollment is disabled")
+	}
+
+	// Outgoing mail (smtp://..., file://... or stdout)
+	mail, err := mailer.New(os.Getenv("MAILER_URL"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	publicURL := strings.TrimSuffix(os.Getenv("PUBLIC_URL"), "/")
+	if publicURL == "" {
+		publicURL = "http://localhost:8080"
+	}
+	requireVerifiedEmail, _ := strconv.ParseBool(os.Getenv("REQUIRE_VERIFIED_EMAIL"))
+
+	// Brute-force protection for /api/login
+	policy := defaultLoginPolicy()
+	if n, err := strconv.Atoi(os.Getenv("LOGIN_MAX_FAILURES")); err == nil && n > 0 {
+		policy.maxFailures = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("LOGIN_LOCKOUT_MINUTES")); err == nil && n > 0 {
+		policy.lockout = time.Duration(n) * time.Minute
+	}
+
+	// Argon2id cost for password hashes
+	passwordParams := auth.DefaultPasswordParams
+	if n, err := strconv.ParseUint(os.Getenv("ARGON2_MEMORY_KIB"), 10, 32); err == nil {
+		passwordParams.Memory = uint32(n)
+	}
+	if n, err := strconv.ParseUint(os.Getenv("ARGON2_ITERATIONS"), 10, 32); err == nil {
+		passwordParams.Iterations = uint32(n)
+	}
+	if n, err := strconv.ParseUint(os.Getenv("ARGON2_PARALLELISM"), 10, 8); err == nil {
+		passwordParams.Parallelism = uint8(n)
+	}
+	if err := passwordParams.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Rules for new passwords
+	passwordPolicy := auth.DefaultPasswordPolicy
+	if n, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_LENGTH")); err == nil {
+		passwordPolicy.MinLength = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_SCORE")); err == nil {
+		passwordPolicy.MinScore = n
+	}
+	if dir := os.Getenv("BREACHED_PASSWORDS_DIR"); dir != "" {
+		breached, err := auth.NewBreachedDir(dir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		passwordPolicy.Breached = breached
+	}
+
+	// Uploads live inside the /app/ file server tree, which serves them.
+	blobs, err := blobstore.NewLocalStore(uploadsDir, publicURL+"/app/uploads")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Deleted accounts can be restored by logging in until the grace period is over.
+	accountDeletionGrace := defaultAccountDeletionGrace
+	if n, err := strconv.Atoi(os.Getenv("ACCOUNT_DELETION_GRACE_DAYS")); err == nil && n >= 0 {
+		accountDeletionGrace = time.Duration(n) * 24 * time.Hour
+	}
+
 	expires_in_seconds, _ := strconv.Atoi(os.Getenv("EXPIRES_IN_SECONDS"))
 	refresh_expires_in_hours, _ := strconv.Atoi(os.Getenv("REFRESH_EXPIRES_IN_HOURS"))
 
@@ -46,36 +196,86 @@ func main() {
 		platform:                 os.Getenv("PLATFORM"),
 		db:                       db,
 		tokenSecret:              os.Getenv("SECRETSTRING"),
+		jwtKeys:                  jwtKeys,
 		expires_in_seconds:       expires_in_seconds,
 		refresh_expires_in_hours: refresh_expires_in_hours,
 		polka_key:                os.Getenv("POLKA_KEY"),
+		totpKey:                  totpKey,
+		mailer:                   mail,
+		publicURL:                publicURL,
+		requireVerifiedEmail:     requireVerifiedEmail,
+		loginPolicy:              policy,
+		resetPolicy:              defaultResetPolicy(),
+		passwordParams:           passwordParams,
+		passwordPolicy:           passwordPolicy,
+		blobs:                    blobs,
+		accountDeletionGrace:     accountDeletionGrace,
+		timeline:                 newTimelineFanout(dbQueries, fanoutMaxFollowers, fanoutWorkers),
+	}
+
+	go cfg.runAccountPurger(context.Background(), accountPurgeInterval)
+
+	server := http.Server{
+		Addr:    ":8080",
+		Handler: cfg.routes(),
 	}
+
+	server.ListenAndServe()
+}
+
+func (cfg *apiConfig) routes() *http.ServeMux {
 	servemux := http.NewServeMux()
 	servemux.HandleFunc("GET /api/healthz", healthHandler)
-	servemux.HandleFunc("GET /admin/metrics", cfg.countHandler)
+	servemux.HandleFunc("GET /.well-known/jwks.json", cfg.jwksHandler)
+	servemux.Handle("GET /admin/metrics", cfg.middlewareAdminOnly(http.HandlerFunc(cfg.countHandler)))
 	servemux.HandleFunc("GET /api/chirps", cfg.getchirpsHandler)
 	servemux.HandleFunc("GET /api/chirps/{chirpID}", cfg.getChirpByIDHandler)
+	servemux.HandleFunc("GET /api/chirps/{chirpID}/thread", cfg.getThreadHandler)
+	servemux.HandleFunc("GET /api/users/{handle}", cfg.getProfileHandler)
+	servemux.HandleFunc("GET /api/users/export", cfg.exportAccountHandler)
+	servemux.HandleFunc("GET /api/users/{id}/followers", cfg.followersHandler)
+	servemux.HandleFunc("GET /api/users/{id}/following", cfg.followingHandler)
+	servemux.HandleFunc("GET /api/users/{id}/likes", cfg.userLikesHandler)
+	servemux.HandleFunc("GET /api/timeline", cfg.timelineHandler)
+	servemux.HandleFunc("GET /api/sessions", cfg.getSessionsHandler)
+	servemux.HandleFunc("GET /api/verify-email", cfg.verifyEmailHandler)
 
-	servemux.HandleFunc("POST /admin/reset", cfg.resetHandler)
+	servemux.Handle("POST /admin/reset", cfg.middlewareAdminOnly(http.HandlerFunc(cfg.resetHandler)))
+	servemux.Handle("POST /admin/users/{userID}/revoke-tokens", cfg.middlewareAdminOnly(http.HandlerFunc(cfg.adminRevokeTokensHandler)))
+	servemux.Handle("POST /admin/users/{userID}/unlock", cfg.middlewareAdminOnly(http.HandlerFunc(cfg.adminUnlockHandler)))
 	servemux.HandleFunc("POST /api/chirps", cfg.chirpsHandler)
 	servemux.HandleFunc("POST /api/users", cfg.createUserHandler)
 	servemux.HandleFunc("POST /api/login", cfg.loginUserHandler)
+	servemux.HandleFunc("POST /api/login/2fa", cfg.loginMFAHandler)
+	servemux.HandleFunc("POST /api/password-reset", cfg.passwordResetHandler)
+	servemux.HandleFunc("POST /api/password-reset/confirm", cfg.passwordResetConfirmHandler)
+	servemux.HandleFunc("POST /api/verify-email/resend", cfg.resendVerificationHandler)
+	servemux.HandleFunc("POST /api/2fa/enroll", cfg.totpEnrollHandler)
+	servemux.HandleFunc("POST /api/2fa/confirm", cfg.totpConfirmHandler)
+	servemux.HandleFunc("POST /api/2fa/disable", cfg.totpDisableHandler)
 	servemux.HandleFunc("POST /api/refresh", cfg.refreshHandler)
 	servemux.HandleFunc("POST /api/revoke", cfg.revokeHandler)
+	servemux.HandleFunc("POST /api/logout-all", cfg.logoutAllHandler)
 	servemux.HandleFunc("POST /api/polka/webhooks", cfg.eventHandler)
+	servemux.HandleFunc("POST /api/users/{id}/follow", cfg.followHandler)
+	servemux.HandleFunc("POST /api/chirps/{chirpID}/like", cfg.likeHandler)
+	servemux.HandleFunc("POST /api/chirps/{chirpID}/rechirp", cfg.rechirpHandler)
 
 	servemux.HandleFunc("DELETE /api/chirps/{chirpID}", cfg.deleteChirpyHandler)
+	servemux.HandleFunc("DELETE /api/sessions/{sessionID}", cfg.deleteSessionHandler)
+	servemux.HandleFunc("DELETE /api/users", cfg.deleteAccountHandler)
+	servemux.HandleFunc("DELETE /api/users/{id}/follow", cfg.unfollowHandler)
+	servemux.HandleFunc("DELETE /api/chirps/{chirpID}/like", cfg.unlikeHandler)
+	servemux.HandleFunc("DELETE /api/chirps/{chirpID}/rechirp", cfg.unrechirpHandler)
 
 	servemux.HandleFunc("PUT /api/users", cfg.updateUserHandler)
+	servemux.HandleFunc("PATCH /api/users", cfg.patchUserHandler)
+	servemux.HandleFunc("PUT /api/users/avatar", cfg.uploadAvatarHandler)
+	servemux.HandleFunc("PUT /api/users/header", cfg.uploadHeaderHandler)
 
 	servemux.Handle("/app/", cfg.middlewareMetricsInc(http.FileServer(http.Dir("."))))
 
-	server := http.Server{
-		Addr:    ":8080",
-		Handler: servemux,
-	}
-
-	server.ListenAndServe()
+	return servemux
 }
 
 func middlewareLog(next http.Handler) http.Handler {
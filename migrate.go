@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+)
+
+// Migration files are embedded so the binary can bring its own schema up to date.
+//
+//go:embed sql/schema/*.sql sql/sqlite/schema/*.sql
+var migrationFiles embed.FS
+
+func newMigrationProvider(dbURL string, db *sql.DB) (*goose.Provider, error) {
+	dialect, dir := goose.DialectPostgres, "sql/schema"
+	if strings.HasPrefix(dbURL, sqliteDBURL) {
+		dialect, dir = goose.DialectSQLite3, "sql/sqlite/schema"
+	}
+
+	fsys, err := fs.Sub(migrationFiles, dir)
+	if err != nil {
+		return nil, err
+	}
+	return goose.NewProvider(dialect, db, fsys)
+}
+
+// runMigrateCommand implements `chirpy migrate up|down|status`.
+func runMigrateCommand(ctx context.Context, dbURL string, db *sql.DB, args []string) error {
+	if db == nil {
+		return fmt.Errorf("migrations are not supported for DB_URL %q", dbURL)
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: chirpy migrate up|down|status")
+	}
+
+	provider, err := newMigrationProvider(dbURL, db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		results, err := provider.Up(ctx)
+		if err != nil {
+			return err
+		}
+		for _, r := range results {
+			log.Printf("applied %s (%s)", r.Source.Path, r.Duration)
+		}
+		if len(results) == 0 {
+			log.Printf("no pending migrations")
+		}
+
+	case "down":
+		r, err := provider.Down(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("rolled back %s (%s)", r.Source.Path, r.Duration)
+
+	case "status":
+		statuses, err := provider.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			appliedAt := "Pending"
+			if s.State == goose.StateApplied {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%-20s %s\n", appliedAt, s.Source.Path)
+		}
+
+	default:
+		return fmt.Errorf("unknown migrate command %q; usage: chirpy migrate up|down|status", args[0])
+	}
+	return nil
+}
+
+// ensureSchema applies pending migrations when autoMigrate is set,
+// and refuses to continue if the database is still behind the embedded schema.
+func ensureSchema(ctx context.Context, dbURL string, db *sql.DB, autoMigrate bool) error {
+	if db == nil {
+		return nil
+	}
+
+	provider, err := newMigrationProvider(dbURL, db)
+	if err != nil {
+		return err
+	}
+
+	if autoMigrate {
+		results, err := provider.Up(ctx)
+		if err != nil {
+			return fmt.Errorf("apply migrations: %w", err)
+		}
+		for _, r := range results {
+			log.Printf("applied migration %s", r.Source.Path)
+		}
+	}
+
+	current, target, err := provider.GetVersions(ctx)
+	if err != nil {
+		return fmt.Errorf("check schema version: %w", err)
+	}
+	if current < target {
+		return fmt.Errorf("database schema is at version %d but this binary expects %d; run `chirpy migrate up` or start with --migrate", current, target)
+	}
+	return nil
+}
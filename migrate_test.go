@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnsureSchema_SQLite(t *testing.T) {
+	ctx := context.Background()
+	dbURL := sqliteDBURL + filepath.Join(t.TempDir(), "chirpy.db")
+
+	_, db, err := openStore(dbURL)
+	if err != nil {
+		t.Fatalf("openStore returned error: %v", err)
+	}
+	defer db.Close()
+
+	err = ensureSchema(ctx, dbURL, db, false)
+	if err == nil || !strings.Contains(err.Error(), "migrate up") {
+		t.Fatalf("ensureSchema on an empty database returned %v, want schema behind error", err)
+	}
+
+	if err := ensureSchema(ctx, dbURL, db, true); err != nil {
+		t.Fatalf("ensureSchema with autoMigrate returned error: %v", err)
+	}
+	if err := ensureSchema(ctx, dbURL, db, false); err != nil {
+		t.Fatalf("ensureSchema after migrating returned error: %v", err)
+	}
+
+	if err := runMigrateCommand(ctx, dbURL, db, []string{"down"}); err != nil {
+		t.Fatalf("migrate down returned error: %v", err)
+	}
+	if err := ensureSchema(ctx, dbURL, db, false); err == nil {
+		t.Fatalf("ensureSchema after migrate down returned nil, want schema behind error")
+	}
+
+	if err := runMigrateCommand(ctx, dbURL, db, []string{"up"}); err != nil {
+		t.Fatalf("migrate up returned error: %v", err)
+	}
+	if err := runMigrateCommand(ctx, dbURL, db, []string{"sideways"}); err == nil {
+		t.Fatalf("migrate with an unknown command returned nil error")
+	}
+}
+
+func TestEnsureSchema_Memory(t *testing.T) {
+	if err := ensureSchema(context.Background(), memoryDBURL, nil, false); err != nil {
+		t.Fatalf("ensureSchema for the memory store returned error: %v", err)
+	}
+}
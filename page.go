@@ -0,0 +1,66 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Tadateki/Chirpy/internal/pagination"
+	"github.com/google/uuid"
+)
+
+// page is the limit and keyset cursor of a list request.
+type page struct {
+	limit           int
+	cursorCreatedAt sql.NullTime
+	cursorID        uuid.NullUUID
+}
+
+// parsePage reads the limit and cursor query parameters. On failure it has already responded.
+func parsePage(w http.ResponseWriter, r *http.Request) (page, bool) {
+	query := r.URL.Query()
+	p := page{limit: defaultChirpsLimit}
+
+	// Page size
+	if l := query.Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit < 1 || limit > maxChirpsLimit {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit parameter; must be between 1 and "+strconv.Itoa(maxChirpsLimit))
+			return page{}, false
+		}
+		p.limit = limit
+	}
+
+	// Cursor (created_at + id of the last item on the previous page)
+	if c := query.Get("cursor"); c != "" {
+		cursor, err := pagination.DecodeCursor(c)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid cursor parameter")
+			return page{}, false
+		}
+		p.cursorCreatedAt = sql.NullTime{Time: cursor.CreatedAt, Valid: true}
+		p.cursorID = uuid.NullUUID{UUID: cursor.ID, Valid: true}
+	}
+	return p, true
+}
+
+// queryLimit asks for one extra row, which tells us whether a next page exists.
+func (p page) queryLimit() int32 {
+	return int32(p.limit + 1)
+}
+
+// next sets the Link header to the page after the item (createdAt, id) and returns its cursor.
+func (p page) next(w http.ResponseWriter, r *http.Request, createdAt time.Time, id uuid.UUID) string {
+	cursor := pagination.EncodeCursor(pagination.Cursor{CreatedAt: createdAt, ID: id})
+
+	next := url.Values{}
+	for k, v := range r.URL.Query() {
+		next[k] = v
+	}
+	next.Set("cursor", cursor)
+	next.Set("limit", strconv.Itoa(p.limit))
+	w.Header().Set("Link", `<`+r.URL.Path+"?"+next.Encode()+`>; rel="next"`)
+	return cursor
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"net/http"
+)
+
+// checkPasswordPolicy answers 400 with the list of violations and returns false
+// when password is not acceptable as a new password.
+func (cfg *apiConfig) checkPasswordPolicy(w http.ResponseWriter, r *http.Request, password string, userInputs ...string) bool {
+	violations, err := cfg.passwordPolicy.Check(r.Context(), password, userInputs...)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Password Check Error")
+		return false
+	}
+	if len(violations) > 0 {
+		respondWithJSON(w, http.StatusBadRequest, map[string]any{
+			"error":      "password does not meet the policy",
+			"violations": violations,
+		})
+		return false
+	}
+	return true
+}
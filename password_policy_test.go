@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestPasswordPolicyOnSignup(t *testing.T) {
+	h := newTestConfig().routes()
+
+	var rejected struct {
+		Error      string `json:"error"`
+		Violations []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"violations"`
+	}
+	creds := map[string]string{"email": "saul@bettercall.com", "password": ""}
+	if rec := doRequest(t, h, "POST", "/api/users", "", creds, &rejected); rec.Code != http.StatusBadRequest {
+		t.Fatalf("create user with an empty password: status %d, want 400", rec.Code)
+	}
+	if len(rejected.Violations) == 0 || rejected.Violations[0].Code != "too_short" || rejected.Violations[0].Message == "" {
+		t.Errorf("create user with an empty password returned %+v", rejected)
+	}
+
+	creds["password"] = "password"
+	if rec := doRequest(t, h, "POST", "/api/users", "", creds, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("create user with a common password: status %d, want 400", rec.Code)
+	}
+
+	login := signup(t, h, "saul@bettercall.com", "correct horse battery staple")
+
+	update := map[string]string{"email": "saul@bettercall.com", "password": "123456"}
+	if rec := doRequest(t, h, "PUT", "/api/users", login.Token, update, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("update to a weak password: status %d, want 400", rec.Code)
+	}
+	// The rejected update must not have revoked the session.
+	if rec := doRequest(t, h, "GET", "/api/sessions", login.Token, nil, nil); rec.Code != http.StatusOK {
+		t.Errorf("access token after a rejected update: status %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxDisplayNameLength = 50
+	maxBioLength         = 160
+	maxAvatarURLLength   = 2048
+)
+
+var handleRe = regexp.MustCompile(`^[a-z0-9_]{3,30}$`)
+
+// reservedHandles would be shadowed by fixed routes under /api/users/.
+var reservedHandles = map[string]bool{
+	"admin":  true,
+	"avatar": true,
+	"export": true,
+	"header": true,
+	"me":     true,
+}
+
+// normalizeHandle strips a leading "@" and lowercases, so "@Saul" and "saul" are the same handle.
+func normalizeHandle(handle string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+}
+
+// validHandle expects a normalized handle.
+func validHandle(handle string) bool {
+	return handleRe.MatchString(handle) && !reservedHandles[handle]
+}
+
+func validDisplayName(name string) bool {
+	return utf8.ValidString(name) && utf8.RuneCountInString(name) <= maxDisplayNameLength && !strings.ContainsAny(name, "\r\n")
+}
+
+func validBio(bio string) bool {
+	return utf8.ValidString(bio) && utf8.RuneCountInString(bio) <= maxBioLength
+}
+
+// validAvatarURL accepts an absolute http(s) URL; empty removes the avatar.
+func validAvatarURL(s string) bool {
+	if s == "" {
+		return true
+	}
+	if len(s) > maxAvatarURLLength {
+		return false
+	}
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
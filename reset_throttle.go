@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Tadateki/Chirpy/internal/database"
+)
+
+// resetPolicy limits POST /api/password-reset per email and per client IP.
+// Requests are counted in the password_reset_attempts table, so they never
+// lock the account's logins.
+type resetPolicy struct {
+	maxPerEmail int // reset mails per address within window
+	maxPerIP    int // reset requests per client IP within window
+	window      time.Duration
+}
+
+func defaultResetPolicy() resetPolicy {
+	return resetPolicy{
+		maxPerEmail: 3,
+		maxPerIP:    20,
+		window:      time.Hour,
+	}
+}
+
+// checkResetThrottle counts a reset request for email from ip. It returns 0 when
+// the request may proceed, otherwise how long the client has to wait. Unknown
+// addresses are counted like registered ones, so the answer reveals nothing.
+func (cfg *apiConfig) checkResetThrottle(ctx context.Context, email, ip string) (time.Duration, error) {
+	keys := []struct {
+		key         string
+		maxRequests int
+	}{
+		{loginEmailKey(email), cfg.resetPolicy.maxPerEmail},
+		{"ip:" + ip, cfg.resetPolicy.maxPerIP},
+	}
+
+	now := time.Now().UTC()
+	for _, k := range keys {
+		a, err := cfg.dbQueries.GetPasswordResetAttempt(ctx, k.key)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if a.LockedUntil.Valid && a.LockedUntil.Time.After(now) {
+			return a.LockedUntil.Time.Sub(now), nil
+		}
+	}
+
+	// The request that reaches a limit still goes through; the ones after it wait out the window.
+	for _, k := range keys {
+		a, err := cfg.dbQueries.RecordPasswordResetRequest(ctx, database.RecordPasswordResetRequestParams{
+			Key:         k.key,
+			Now:         now,
+			ResetBefore: now.Add(-cfg.resetPolicy.window),
+		})
+		if err != nil {
+			return 0, err
+		}
+		if int(a.Requests) < k.maxRequests {
+			continue
+		}
+		err = cfg.dbQueries.LockPasswordReset(ctx, database.LockPasswordResetParams{
+			LockedUntil: sql.NullTime{Time: now.Add(cfg.resetPolicy.window), Valid: true},
+			Key:         k.key,
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return 0, nil
+}
+
+func respondWithResetThrottle(w http.ResponseWriter, retryAfter time.Duration) {
+	setRetryAfter(w, retryAfter)
+	respondWithError(w, http.StatusTooManyRequests, "too many password reset requests")
+}
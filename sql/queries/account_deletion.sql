@@ -0,0 +1,24 @@
+-- name: ScheduleUserDeletion :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    delete_after = $1
+WHERE id = $2;
+
+-- name: CancelUserDeletion :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    delete_after = NULL
+WHERE id = $1;
+
+-- name: PurgeDeletedUsers :many
+-- Everything the user owns goes with the row (ON DELETE CASCADE); uploads are returned for cleanup.
+DELETE FROM users
+WHERE delete_after <= sqlc.arg('now')::timestamp
+RETURNING id, avatar_key, header_key;
+
+-- name: ListRefreshTokensByUser :many
+SELECT * FROM refresh_tokens
+WHERE user_id = $1
+ORDER BY created_at;
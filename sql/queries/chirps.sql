@@ -1,10 +1,7 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
-VALUES (
-  gen_random_uuid(),
-  NOW(),
-  NOW(),
-  $1,
-  $2
-)
+-- A chirp without a conversation_id starts its own, named by its ID.
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, in_reply_to_id, conversation_id, quoted_id)
+SELECT generated.id, NOW(), NOW(), sqlc.arg('body')::text, sqlc.arg('user_id')::uuid, sqlc.narg('in_reply_to_id')::uuid,
+  COALESCE(sqlc.narg('conversation_id')::uuid, generated.id), sqlc.narg('quoted_id')::uuid
+FROM (SELECT gen_random_uuid() AS id) AS generated
 RETURNING *;
@@ -0,0 +1,16 @@
+-- name: CreateEmailVerificationToken :exec
+INSERT INTO email_verification_tokens (token_hash, created_at, user_id, email, expires_at, used_at)
+VALUES ($1, NOW(), $2, $3, $4, NULL);
+
+-- name: ConsumeEmailVerificationToken :one
+UPDATE email_verification_tokens
+SET used_at = sqlc.arg('now')::timestamp
+WHERE token_hash = sqlc.arg('token_hash') AND used_at IS NULL AND expires_at > sqlc.arg('now')::timestamp
+RETURNING user_id, email;
+
+-- name: MarkEmailVerified :execrows
+UPDATE users
+SET
+    updated_at = NOW(),
+    email_verified_at = NOW()
+WHERE id = $1 AND email = $2;
@@ -0,0 +1,50 @@
+-- name: FollowUser :execrows
+INSERT INTO follows (follower_id, followee_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: UnfollowUser :execrows
+DELETE FROM follows
+WHERE follower_id = $1 AND followee_id = $2;
+
+-- name: CountFollowers :one
+SELECT COUNT(*) FROM follows
+WHERE followee_id = $1;
+
+-- name: CountFollowing :one
+SELECT COUNT(*) FROM follows
+WHERE follower_id = $1;
+
+-- name: ListFollowers :many
+-- Newest follows first; the cursor is (follows.created_at, follower id).
+SELECT users.id, users.handle, users.display_name, users.avatar_url, follows.created_at AS followed_at
+FROM follows
+JOIN users ON users.id = follows.follower_id
+WHERE follows.followee_id = sqlc.arg('user_id')
+  AND users.delete_after IS NULL
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (follows.created_at, follows.follower_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY follows.created_at DESC, follows.follower_id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListFollowing :many
+-- Newest follows first; the cursor is (follows.created_at, followee id).
+SELECT users.id, users.handle, users.display_name, users.avatar_url, follows.created_at AS followed_at
+FROM follows
+JOIN users ON users.id = follows.followee_id
+WHERE follows.follower_id = sqlc.arg('user_id')
+  AND users.delete_after IS NULL
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (follows.created_at, follows.followee_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY follows.created_at DESC, follows.followee_id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListFollowsByUser :many
+-- Both directions, for the account export.
+SELECT * FROM follows
+WHERE follower_id = sqlc.arg('user_id') OR followee_id = sqlc.arg('user_id')
+ORDER BY created_at;
@@ -0,0 +1,41 @@
+-- name: LikeChirp :execrows
+INSERT INTO likes (user_id, chirp_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: UnlikeChirp :execrows
+DELETE FROM likes
+WHERE user_id = $1 AND chirp_id = $2;
+
+-- name: CountLikesByIDs :many
+SELECT chirp_id, COUNT(*) AS like_count
+FROM likes
+WHERE chirp_id = ANY(sqlc.arg('ids')::uuid[])
+GROUP BY chirp_id;
+
+-- name: ListLikedChirpIDs :many
+-- Which of the chirps the user has liked.
+SELECT chirp_id FROM likes
+WHERE user_id = sqlc.arg('user_id')
+  AND chirp_id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: ListLikedChirps :many
+-- Newest likes first; the cursor is (likes.created_at, chirp id).
+SELECT sqlc.embed(chirps), likes.created_at AS liked_at
+FROM likes
+JOIN chirps ON chirps.id = likes.chirp_id
+WHERE likes.user_id = sqlc.arg('user_id')
+  AND chirps.deleted_at IS NULL
+  AND chirps.user_id NOT IN (SELECT users.id FROM users WHERE users.delete_after IS NOT NULL)
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (likes.created_at, likes.chirp_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY likes.created_at DESC, likes.chirp_id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListLikesByUser :many
+-- Every like, deleted chirps included, for the account export.
+SELECT * FROM likes
+WHERE user_id = $1
+ORDER BY created_at;
@@ -0,0 +1,24 @@
+-- name: GetLoginAttempt :one
+SELECT * FROM login_attempts
+WHERE key = $1;
+
+-- name: RecordLoginFailure :one
+-- The counter starts over when the previous failure is older than reset_before.
+INSERT INTO login_attempts (key, failures, last_failed_at, locked_until)
+VALUES (sqlc.arg('key'), 1, sqlc.arg('now')::timestamp, NULL)
+ON CONFLICT (key) DO UPDATE SET
+    failures = CASE
+        WHEN login_attempts.last_failed_at < sqlc.arg('reset_before') THEN 1
+        ELSE login_attempts.failures + 1
+    END,
+    last_failed_at = sqlc.arg('now')::timestamp
+RETURNING *;
+
+-- name: LockLogin :exec
+UPDATE login_attempts
+SET locked_until = $1
+WHERE key = $2;
+
+-- name: ClearLoginAttempts :exec
+DELETE FROM login_attempts
+WHERE key = $1;
@@ -0,0 +1,32 @@
+-- name: CreatePasswordResetToken :exec
+INSERT INTO password_reset_tokens (token_hash, created_at, user_id, expires_at, used_at)
+VALUES ($1, NOW(), $2, $3, NULL);
+
+-- name: GetPasswordResetTokenUser :one
+-- Looks up a usable token without consuming it.
+SELECT user_id FROM password_reset_tokens
+WHERE token_hash = sqlc.arg('token_hash') AND used_at IS NULL AND expires_at > sqlc.arg('now')::timestamp;
+
+-- name: ConsumePasswordResetToken :one
+UPDATE password_reset_tokens
+SET used_at = sqlc.arg('now')::timestamp
+WHERE token_hash = sqlc.arg('token_hash') AND used_at IS NULL AND expires_at > sqlc.arg('now')::timestamp
+RETURNING user_id;
+
+-- name: InvalidatePasswordResetTokensForUser :exec
+UPDATE password_reset_tokens
+SET used_at = NOW()
+WHERE user_id = $1 AND used_at IS NULL;
+
+-- name: SetUserPassword :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    hashed_password = $1
+WHERE id = $2;
+
+-- name: RehashUserPassword :execrows
+-- Compare-and-set: a password changed since the old hash was read is left alone.
+UPDATE users
+SET hashed_password = sqlc.arg('new_hash')
+WHERE id = sqlc.arg('id') AND hashed_password = sqlc.arg('old_hash');
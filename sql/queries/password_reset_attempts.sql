@@ -0,0 +1,20 @@
+-- name: GetPasswordResetAttempt :one
+SELECT * FROM password_reset_attempts
+WHERE key = $1;
+
+-- name: RecordPasswordResetRequest :one
+-- The counter starts over when the previous request is older than reset_before.
+INSERT INTO password_reset_attempts (key, requests, last_requested_at, locked_until)
+VALUES (sqlc.arg('key'), 1, sqlc.arg('now')::timestamp, NULL)
+ON CONFLICT (key) DO UPDATE SET
+    requests = CASE
+        WHEN password_reset_attempts.last_requested_at < sqlc.arg('reset_before') THEN 1
+        ELSE password_reset_attempts.requests + 1
+    END,
+    last_requested_at = sqlc.arg('now')::timestamp
+RETURNING *;
+
+-- name: LockPasswordReset :exec
+UPDATE password_reset_attempts
+SET locked_until = $1
+WHERE key = $2;
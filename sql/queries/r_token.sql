@@ -1,12 +1,16 @@
 -- name: StoreRefreshToken :one
-INSERT INTO refresh_tokens (token, created_at, updated_at, user_id, expires_at, revoked_at)
+INSERT INTO refresh_tokens (token_hash, created_at, updated_at, user_id, expires_at, revoked_at, family_id, user_agent, ip_address, session_started_at)
 VALUES (
   $1,
   NOW(),
   NOW(),
   $2,
   $3,
-  NULL
+  NULL,
+  $4,
+  $5,
+  $6,
+  COALESCE(sqlc.narg('session_started_at')::timestamp, NOW())
 )
 RETURNING *;
 
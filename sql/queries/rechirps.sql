@@ -0,0 +1,36 @@
+-- name: CreateRechirp :one
+-- Returns no row if the user has already rechirped the chirp.
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, conversation_id, rechirp_of_id)
+SELECT generated.id, NOW(), NOW(), '', sqlc.arg('user_id')::uuid, generated.id, sqlc.arg('rechirp_of_id')::uuid
+FROM (SELECT gen_random_uuid() AS id) AS generated
+ON CONFLICT DO NOTHING
+RETURNING *;
+
+-- name: GetRechirp :one
+SELECT * FROM chirps
+WHERE user_id = sqlc.arg('user_id')::uuid AND rechirp_of_id = sqlc.arg('rechirp_of_id')::uuid;
+
+-- name: DeleteRechirp :execrows
+DELETE FROM chirps
+WHERE user_id = sqlc.arg('user_id')::uuid AND rechirp_of_id = sqlc.arg('rechirp_of_id')::uuid;
+
+-- name: DeleteRechirpsOf :exec
+-- Removes the rechirps of a chirp that is kept as a tombstone.
+DELETE FROM chirps
+WHERE rechirp_of_id = sqlc.arg('rechirp_of_id')::uuid;
+
+-- name: CountRechirpsByIDs :many
+SELECT rechirp_of_id, COUNT(*) AS rechirp_count
+FROM chirps
+WHERE rechirp_of_id = ANY(sqlc.arg('ids')::uuid[])
+GROUP BY rechirp_of_id;
+
+-- name: CountQuotesByIDs :many
+SELECT quoted_id, COUNT(*) AS quote_count
+FROM chirps
+WHERE quoted_id = ANY(sqlc.arg('ids')::uuid[]) AND deleted_at IS NULL
+GROUP BY quoted_id;
+
+-- name: ListChirpsByIDs :many
+SELECT * FROM chirps
+WHERE id = ANY(sqlc.arg('ids')::uuid[]);
@@ -0,0 +1,14 @@
+-- name: ListActiveRefreshTokensByUser :many
+SELECT * FROM refresh_tokens
+WHERE user_id = sqlc.arg('user_id') AND revoked_at IS NULL AND expires_at > sqlc.arg('now')::timestamp
+ORDER BY session_started_at DESC;
+
+-- name: RevokeUserRefreshTokenFamily :execrows
+UPDATE refresh_tokens
+SET revoked_at = NOW(), updated_at = NOW()
+WHERE family_id = $1 AND user_id = $2 AND revoked_at IS NULL;
+
+-- name: RevokeAllRefreshTokensForUser :exec
+UPDATE refresh_tokens
+SET revoked_at = NOW(), updated_at = NOW()
+WHERE user_id = $1 AND revoked_at IS NULL;
@@ -0,0 +1,6 @@
+-- name: SetTokensValidAfter :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    tokens_valid_after = $1
+WHERE id = $2;
@@ -1,7 +1,9 @@
 -- name: UpdateUser :exec
+-- A new address has to be verified again.
 UPDATE users
 SET 
     updated_at = NOW(),
     email = $1, 
-    hashed_password = $2 
-WHERE id = $3;
\ No newline at end of file
+    hashed_password = $2,
+    email_verified_at = CASE WHEN email = $1 THEN email_verified_at END
+WHERE id = $3;
@@ -0,0 +1,8 @@
+-- name: SetUserEmail :exec
+-- A new address has to be verified again.
+UPDATE users
+SET
+    updated_at = NOW(),
+    email = $1,
+    email_verified_at = NULL
+WHERE id = $2;
@@ -0,0 +1,6 @@
+-- name: SetUserRole :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    role = $1
+WHERE id = $2;
@@ -0,0 +1,52 @@
+-- name: SetUserTOTPSecret :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    totp_secret = $1,
+    totp_enabled_at = NULL,
+    totp_last_step = NULL
+WHERE id = $2;
+
+-- name: EnableUserTOTP :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    totp_enabled_at = NOW()
+WHERE id = $1 AND totp_secret IS NOT NULL;
+
+-- name: DisableUserTOTP :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    totp_secret = NULL,
+    totp_enabled_at = NULL,
+    totp_last_step = NULL
+WHERE id = $1;
+
+-- name: UseTOTPStep :execrows
+-- Accepts each time step once: a code for a step at or before the last accepted one is a replay.
+UPDATE users
+SET totp_last_step = sqlc.arg('step')::bigint
+WHERE id = sqlc.arg('id') AND (totp_last_step IS NULL OR totp_last_step < sqlc.arg('step'));
+
+-- name: CreateRecoveryCode :exec
+INSERT INTO mfa_recovery_codes (code_hash, created_at, user_id, used_at)
+VALUES ($1, NOW(), $2, NULL);
+
+-- name: DeleteRecoveryCodesForUser :exec
+DELETE FROM mfa_recovery_codes
+WHERE user_id = $1;
+
+-- name: UseRecoveryCode :execrows
+UPDATE mfa_recovery_codes
+SET used_at = NOW()
+WHERE code_hash = $1 AND user_id = $2 AND used_at IS NULL;
+
+-- name: UseMFAToken :execrows
+INSERT INTO used_mfa_tokens (jti, user_id, expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (jti) DO NOTHING;
+
+-- name: DeleteExpiredMFATokens :exec
+DELETE FROM used_mfa_tokens
+WHERE expires_at < $1;
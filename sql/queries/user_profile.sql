@@ -0,0 +1,51 @@
+-- name: GetUserFromHandle :one
+SELECT * FROM users
+WHERE handle = $1;
+
+-- name: ListUsersByIDs :many
+SELECT * FROM users
+WHERE id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: SetUserProfile :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    handle = $1,
+    display_name = $2,
+    bio = $3,
+    avatar_url = $4,
+    -- An avatar URL set by hand replaces the uploaded one.
+    avatar_key = CASE WHEN avatar_url = $4 THEN avatar_key ELSE '' END
+WHERE id = $5;
+
+-- name: PatchUser :exec
+-- PATCH /api/users in one statement. The profile fields are always written; email and
+-- hashed_password only when not NULL. A new address has to be verified again.
+UPDATE users
+SET
+    updated_at = NOW(),
+    handle = sqlc.arg('handle'),
+    display_name = sqlc.arg('display_name'),
+    bio = sqlc.arg('bio'),
+    avatar_url = sqlc.arg('avatar_url'),
+    avatar_key = CASE WHEN avatar_url = sqlc.arg('avatar_url') THEN avatar_key ELSE '' END,
+    email = COALESCE(sqlc.narg('email')::text, email),
+    email_verified_at = CASE WHEN sqlc.narg('email')::text IS NULL THEN email_verified_at ELSE NULL END,
+    hashed_password = COALESCE(sqlc.narg('hashed_password')::text, hashed_password)
+WHERE id = sqlc.arg('id');
+
+-- name: SetUserAvatar :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    avatar_url = $1,
+    avatar_key = $2
+WHERE id = $3;
+
+-- name: SetUserHeader :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    header_url = $1,
+    header_key = $2
+WHERE id = $3;
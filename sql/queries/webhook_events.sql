@@ -0,0 +1,14 @@
+-- name: CreateWebhookEvent :exec
+INSERT INTO webhook_events (id, created_at, user_id, event, payload)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3
+);
+
+-- name: ListWebhookEventsByUser :many
+SELECT * FROM webhook_events
+WHERE user_id = $1
+ORDER BY created_at;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Refresh tokens are stored as SHA-256 digests; hash the existing rows in place.
+ALTER TABLE refresh_tokens RENAME COLUMN token TO token_hash;
+UPDATE refresh_tokens SET
+    token_hash = encode(sha256(convert_to(token_hash, 'UTF8')), 'hex'),
+    replaced_by = encode(sha256(convert_to(replaced_by, 'UTF8')), 'hex');
+
+-- +goose Down
+-- Digests cannot be turned back into tokens, so every session is invalidated.
+DELETE FROM refresh_tokens;
+ALTER TABLE refresh_tokens RENAME COLUMN token_hash TO token;
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE refresh_tokens ADD COLUMN user_agent TEXT NOT NULL DEFAULT '';
+ALTER TABLE refresh_tokens ADD COLUMN ip_address TEXT NOT NULL DEFAULT '';
+-- Login time of the session; copied to every token of the family on rotation.
+ALTER TABLE refresh_tokens ADD COLUMN session_started_at TIMESTAMP;
+UPDATE refresh_tokens SET session_started_at = created_at;
+ALTER TABLE refresh_tokens ALTER COLUMN session_started_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_refresh_tokens_user_id;
+ALTER TABLE refresh_tokens DROP COLUMN session_started_at;
+ALTER TABLE refresh_tokens DROP COLUMN ip_address;
+ALTER TABLE refresh_tokens DROP COLUMN user_agent;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Single-use, expiring reset tokens; only the SHA-256 digest is stored, like refresh tokens.
+CREATE TABLE password_reset_tokens (
+  token_hash TEXT PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  expires_at TIMESTAMP NOT NULL,
+  used_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS password_reset_tokens;
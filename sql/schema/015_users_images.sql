@@ -0,0 +1,10 @@
+-- +goose Up
+-- *_key is the blob key prefix of uploaded thumbnails; empty when the URL points elsewhere (or nowhere).
+ALTER TABLE users ADD COLUMN avatar_key TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN header_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN header_key TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN header_key;
+ALTER TABLE users DROP COLUMN header_url;
+ALTER TABLE users DROP COLUMN avatar_key;
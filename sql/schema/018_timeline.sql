@@ -0,0 +1,24 @@
+-- +goose Up
+-- Set once a chirp has been copied into its author's followers' timelines. Chirps
+-- without it (pending, or by accounts above the fan-out threshold) are read directly.
+ALTER TABLE chirps ADD COLUMN fanned_out_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_chirps_not_fanned_out ON chirps (user_id, created_at)
+  WHERE fanned_out_at IS NULL;
+
+-- Materialized home timelines; created_at is the chirp's, for keyset pagination.
+CREATE TABLE timeline_entries (
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+  author_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (user_id, chirp_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_timeline_entries_page ON timeline_entries (user_id, created_at, chirp_id);
+CREATE INDEX IF NOT EXISTS idx_timeline_entries_author ON timeline_entries (user_id, author_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS timeline_entries;
+DROP INDEX IF EXISTS idx_chirps_not_fanned_out;
+ALTER TABLE chirps DROP COLUMN fanned_out_at;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Replies lose their parent only if it is removed outside the API, which keeps a tombstone instead.
+ALTER TABLE chirps ADD COLUMN in_reply_to_id UUID REFERENCES chirps(id) ON DELETE SET NULL;
+
+-- The ID of the chirp that started the thread; a chirp that replies to nothing starts its own.
+ALTER TABLE chirps ADD COLUMN conversation_id UUID;
+UPDATE chirps SET conversation_id = id;
+ALTER TABLE chirps ALTER COLUMN conversation_id SET NOT NULL;
+
+-- Set when a chirp with replies is deleted: the row stays without its body so the thread holds together.
+ALTER TABLE chirps ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_chirps_in_reply_to_id ON chirps (in_reply_to_id, created_at, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_chirps_in_reply_to_id;
+ALTER TABLE chirps DROP COLUMN deleted_at;
+ALTER TABLE chirps DROP COLUMN conversation_id;
+ALTER TABLE chirps DROP COLUMN in_reply_to_id;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- A quote keeps the ID of the quoted chirp after that chirp is deleted outright,
+-- so it can still show it as deleted instead of silently losing the reference.
+ALTER TABLE chirps DROP CONSTRAINT chirps_quoted_id_fkey;
+
+-- +goose Down
+UPDATE chirps SET quoted_id = NULL
+WHERE quoted_id IS NOT NULL AND quoted_id NOT IN (SELECT id FROM chirps);
+ALTER TABLE chirps ADD CONSTRAINT chirps_quoted_id_fkey
+  FOREIGN KEY (quoted_id) REFERENCES chirps(id) ON DELETE SET NULL;
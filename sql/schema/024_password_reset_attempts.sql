@@ -0,0 +1,15 @@
+-- +goose Up
+-- Password reset request counters, kept apart from login_attempts so they never
+-- count as failed logins. key is "email:<address>" or "ip:<address>".
+CREATE TABLE password_reset_attempts (
+  key TEXT PRIMARY KEY,
+  requests INTEGER NOT NULL,
+  last_requested_at TIMESTAMP NOT NULL,
+  locked_until TIMESTAMP
+);
+
+-- Counters written to login_attempts before this table existed.
+DELETE FROM login_attempts WHERE key LIKE 'reset-%';
+
+-- +goose Down
+DROP TABLE IF EXISTS password_reset_attempts;
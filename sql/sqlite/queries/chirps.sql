@@ -0,0 +1,48 @@
+-- name: CreateChirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, in_reply_to_id, conversation_id, quoted_id)
+VALUES (
+  sqlc.arg('id'),
+  sqlc.arg('now'),
+  sqlc.arg('now'),
+  sqlc.arg('body'),
+  sqlc.arg('user_id'),
+  sqlc.narg('in_reply_to_id'),
+  sqlc.arg('conversation_id'),
+  sqlc.narg('quoted_id')
+)
+RETURNING *;
+
+-- name: DeleteChirp :exec
+DELETE FROM chirps
+WHERE id = ?;
+
+-- name: GetChirp :one
+SELECT * FROM chirps
+WHERE id = ?;
+
+-- name: ListChirpsAsc :many
+-- Chirps of accounts waiting for deletion are hidden, like their profiles.
+SELECT * FROM chirps
+WHERE chirps.deleted_at IS NULL
+  AND (CAST(sqlc.narg('author_id') AS TEXT) IS NULL OR chirps.user_id = CAST(sqlc.narg('author_id') AS TEXT))
+  AND chirps.user_id NOT IN (SELECT users.id FROM users WHERE users.delete_after IS NOT NULL)
+  AND (
+    chirps.created_at > sqlc.narg('cursor_created_at')
+    OR (chirps.created_at = sqlc.narg('cursor_created_at') AND chirps.id > CAST(sqlc.narg('cursor_id') AS TEXT))
+    OR sqlc.narg('cursor_created_at') IS NULL
+  )
+ORDER BY chirps.created_at ASC, chirps.id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ListChirpsDesc :many
+SELECT * FROM chirps
+WHERE chirps.deleted_at IS NULL
+  AND (CAST(sqlc.narg('author_id') AS TEXT) IS NULL OR chirps.user_id = CAST(sqlc.narg('author_id') AS TEXT))
+  AND chirps.user_id NOT IN (SELECT users.id FROM users WHERE users.delete_after IS NOT NULL)
+  AND (
+    chirps.created_at < sqlc.narg('cursor_created_at')
+    OR (chirps.created_at = sqlc.narg('cursor_created_at') AND chirps.id < CAST(sqlc.narg('cursor_id') AS TEXT))
+    OR sqlc.narg('cursor_created_at') IS NULL
+  )
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('limit');
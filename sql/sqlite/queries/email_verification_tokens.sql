@@ -0,0 +1,9 @@
+-- name: CreateEmailVerificationToken :exec
+INSERT INTO email_verification_tokens (token_hash, created_at, user_id, email, expires_at, used_at)
+VALUES (sqlc.arg('token_hash'), sqlc.arg('now'), sqlc.arg('user_id'), sqlc.arg('email'), sqlc.arg('expires_at'), NULL);
+
+-- name: ConsumeEmailVerificationToken :one
+UPDATE email_verification_tokens
+SET used_at = sqlc.arg('now')
+WHERE token_hash = sqlc.arg('token_hash') AND used_at IS NULL AND expires_at > sqlc.arg('now')
+RETURNING user_id, email;
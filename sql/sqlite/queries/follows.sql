@@ -0,0 +1,52 @@
+-- name: FollowUser :execrows
+INSERT INTO follows (follower_id, followee_id, created_at)
+VALUES (sqlc.arg('follower_id'), sqlc.arg('followee_id'), sqlc.arg('now'))
+ON CONFLICT DO NOTHING;
+
+-- name: UnfollowUser :execrows
+DELETE FROM follows
+WHERE follower_id = ? AND followee_id = ?;
+
+-- name: CountFollowers :one
+SELECT COUNT(*) FROM follows
+WHERE followee_id = ?;
+
+-- name: CountFollowing :one
+SELECT COUNT(*) FROM follows
+WHERE follower_id = ?;
+
+-- name: ListFollowers :many
+-- Newest follows first; the cursor is (follows.created_at, follower id).
+SELECT users.id, users.handle, users.display_name, users.avatar_url, follows.created_at AS followed_at
+FROM follows
+JOIN users ON users.id = follows.follower_id
+WHERE follows.followee_id = sqlc.arg('user_id')
+  AND users.delete_after IS NULL
+  AND (
+    follows.created_at < sqlc.narg('cursor_created_at')
+    OR (follows.created_at = sqlc.narg('cursor_created_at') AND follows.follower_id < CAST(sqlc.narg('cursor_id') AS TEXT))
+    OR sqlc.narg('cursor_created_at') IS NULL
+  )
+ORDER BY follows.created_at DESC, follows.follower_id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListFollowing :many
+-- Newest follows first; the cursor is (follows.created_at, followee id).
+SELECT users.id, users.handle, users.display_name, users.avatar_url, follows.created_at AS followed_at
+FROM follows
+JOIN users ON users.id = follows.followee_id
+WHERE follows.follower_id = sqlc.arg('user_id')
+  AND users.delete_after IS NULL
+  AND (
+    follows.created_at < sqlc.narg('cursor_created_at')
+    OR (follows.created_at = sqlc.narg('cursor_created_at') AND follows.followee_id < CAST(sqlc.narg('cursor_id') AS TEXT))
+    OR sqlc.narg('cursor_created_at') IS NULL
+  )
+ORDER BY follows.created_at DESC, follows.followee_id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListFollowsByUser :many
+-- Both directions, for the account export.
+SELECT * FROM follows
+WHERE follower_id = sqlc.arg('user_id') OR followee_id = sqlc.arg('user_id')
+ORDER BY created_at;
@@ -0,0 +1,30 @@
+-- name: GetLoginAttempt :one
+SELECT * FROM login_attempts
+WHERE key = ?;
+
+-- name: EnsureLoginAttempt :exec
+-- SQLite part of RecordLoginFailure: create the row with no failures, then count in IncrementLoginFailures.
+INSERT INTO login_attempts (key, failures, last_failed_at, locked_until)
+VALUES (sqlc.arg('key'), 0, sqlc.arg('now'), NULL)
+ON CONFLICT (key) DO NOTHING;
+
+-- name: IncrementLoginFailures :one
+-- The counter starts over when the previous failure is older than reset_before.
+UPDATE login_attempts
+SET
+    failures = CASE
+        WHEN last_failed_at < sqlc.arg('reset_before') THEN 1
+        ELSE failures + 1
+    END,
+    last_failed_at = sqlc.arg('now')
+WHERE key = sqlc.arg('key')
+RETURNING *;
+
+-- name: LockLogin :exec
+UPDATE login_attempts
+SET locked_until = sqlc.arg('locked_until')
+WHERE key = sqlc.arg('key');
+
+-- name: ClearLoginAttempts :exec
+DELETE FROM login_attempts
+WHERE key = ?;
@@ -0,0 +1,21 @@
+-- name: CreateRecoveryCode :exec
+INSERT INTO mfa_recovery_codes (code_hash, created_at, user_id, used_at)
+VALUES (sqlc.arg('code_hash'), sqlc.arg('now'), sqlc.arg('user_id'), NULL);
+
+-- name: DeleteRecoveryCodesForUser :exec
+DELETE FROM mfa_recovery_codes
+WHERE user_id = ?;
+
+-- name: UseRecoveryCode :execrows
+UPDATE mfa_recovery_codes
+SET used_at = sqlc.arg('now')
+WHERE code_hash = sqlc.arg('code_hash') AND user_id = sqlc.arg('user_id') AND used_at IS NULL;
+
+-- name: UseMFAToken :execrows
+INSERT INTO used_mfa_tokens (jti, user_id, expires_at)
+VALUES (sqlc.arg('jti'), sqlc.arg('user_id'), sqlc.arg('expires_at'))
+ON CONFLICT (jti) DO NOTHING;
+
+-- name: DeleteExpiredMFATokens :exec
+DELETE FROM used_mfa_tokens
+WHERE expires_at < sqlc.arg('before');
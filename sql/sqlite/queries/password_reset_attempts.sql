@@ -0,0 +1,26 @@
+-- name: GetPasswordResetAttempt :one
+SELECT * FROM password_reset_attempts
+WHERE key = ?;
+
+-- name: EnsurePasswordResetAttempt :exec
+-- SQLite part of RecordPasswordResetRequest: create the row with no requests, then count in IncrementPasswordResetRequests.
+INSERT INTO password_reset_attempts (key, requests, last_requested_at, locked_until)
+VALUES (sqlc.arg('key'), 0, sqlc.arg('now'), NULL)
+ON CONFLICT (key) DO NOTHING;
+
+-- name: IncrementPasswordResetRequests :one
+-- The counter starts over when the previous request is older than reset_before.
+UPDATE password_reset_attempts
+SET
+    requests = CASE
+        WHEN last_requested_at < sqlc.arg('reset_before') THEN 1
+        ELSE requests + 1
+    END,
+    last_requested_at = sqlc.arg('now')
+WHERE key = sqlc.arg('key')
+RETURNING *;
+
+-- name: LockPasswordReset :exec
+UPDATE password_reset_attempts
+SET locked_until = sqlc.arg('locked_until')
+WHERE key = sqlc.arg('key');
@@ -0,0 +1,19 @@
+-- name: CreatePasswordResetToken :exec
+INSERT INTO password_reset_tokens (token_hash, created_at, user_id, expires_at, used_at)
+VALUES (sqlc.arg('token_hash'), sqlc.arg('now'), sqlc.arg('user_id'), sqlc.arg('expires_at'), NULL);
+
+-- name: GetPasswordResetTokenUser :one
+-- Looks up a usable token without consuming it.
+SELECT user_id FROM password_reset_tokens
+WHERE token_hash = sqlc.arg('token_hash') AND used_at IS NULL AND expires_at > sqlc.arg('now');
+
+-- name: ConsumePasswordResetToken :one
+UPDATE password_reset_tokens
+SET used_at = sqlc.arg('now')
+WHERE token_hash = sqlc.arg('token_hash') AND used_at IS NULL AND expires_at > sqlc.arg('now')
+RETURNING user_id;
+
+-- name: InvalidatePasswordResetTokensForUser :exec
+UPDATE password_reset_tokens
+SET used_at = sqlc.arg('now')
+WHERE user_id = sqlc.arg('user_id') AND used_at IS NULL;
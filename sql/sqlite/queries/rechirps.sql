@@ -0,0 +1,43 @@
+-- name: CreateRechirp :one
+-- Returns no row if the user has already rechirped the chirp.
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, conversation_id, rechirp_of_id)
+VALUES (
+  sqlc.arg('id'),
+  sqlc.arg('now'),
+  sqlc.arg('now'),
+  '',
+  sqlc.arg('user_id'),
+  sqlc.arg('id'),
+  CAST(sqlc.arg('rechirp_of_id') AS TEXT)
+)
+ON CONFLICT DO NOTHING
+RETURNING *;
+
+-- name: GetRechirp :one
+SELECT * FROM chirps
+WHERE user_id = sqlc.arg('user_id') AND rechirp_of_id = CAST(sqlc.arg('rechirp_of_id') AS TEXT);
+
+-- name: DeleteRechirp :execrows
+DELETE FROM chirps
+WHERE user_id = sqlc.arg('user_id') AND rechirp_of_id = CAST(sqlc.arg('rechirp_of_id') AS TEXT);
+
+-- name: DeleteRechirpsOf :exec
+-- Removes the rechirps of a chirp that is kept as a tombstone.
+DELETE FROM chirps
+WHERE rechirp_of_id = CAST(sqlc.arg('rechirp_of_id') AS TEXT);
+
+-- name: CountRechirpsByIDs :many
+SELECT rechirp_of_id, COUNT(*) AS rechirp_count
+FROM chirps
+WHERE rechirp_of_id IN (sqlc.slice('ids'))
+GROUP BY rechirp_of_id;
+
+-- name: CountQuotesByIDs :many
+SELECT quoted_id, COUNT(*) AS quote_count
+FROM chirps
+WHERE quoted_id IN (sqlc.slice('ids')) AND deleted_at IS NULL
+GROUP BY quoted_id;
+
+-- name: ListChirpsByIDs :many
+SELECT * FROM chirps
+WHERE id IN (sqlc.slice('ids'));
@@ -0,0 +1,54 @@
+-- name: StoreRefreshToken :one
+INSERT INTO refresh_tokens (token_hash, created_at, updated_at, user_id, expires_at, revoked_at, family_id, user_agent, ip_address, session_started_at)
+VALUES (
+  sqlc.arg('token_hash'),
+  sqlc.arg('now'),
+  sqlc.arg('now'),
+  sqlc.arg('user_id'),
+  sqlc.arg('expires_at'),
+  NULL,
+  sqlc.arg('family_id'),
+  sqlc.arg('user_agent'),
+  sqlc.arg('ip_address'),
+  sqlc.arg('session_started_at')
+)
+RETURNING *;
+
+-- name: GetRefreshTokenFromToken :one
+SELECT * FROM refresh_tokens
+WHERE token_hash = ?;
+
+-- name: SetRevokeRefreshToken :exec
+UPDATE refresh_tokens
+SET revoked_at = sqlc.arg('now'), updated_at = sqlc.arg('now')
+WHERE token_hash = sqlc.arg('token_hash');
+
+-- name: RotateRefreshToken :execrows
+UPDATE refresh_tokens
+SET revoked_at = sqlc.arg('now'), updated_at = sqlc.arg('now'), replaced_by = sqlc.arg('replaced_by')
+WHERE token_hash = sqlc.arg('token_hash') AND revoked_at IS NULL;
+
+-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens
+SET revoked_at = sqlc.arg('now'), updated_at = sqlc.arg('now')
+WHERE family_id = sqlc.arg('family_id') AND revoked_at IS NULL;
+
+-- name: ListActiveRefreshTokensByUser :many
+SELECT * FROM refresh_tokens
+WHERE user_id = sqlc.arg('user_id') AND revoked_at IS NULL AND expires_at > sqlc.arg('now')
+ORDER BY session_started_at DESC;
+
+-- name: RevokeUserRefreshTokenFamily :execrows
+UPDATE refresh_tokens
+SET revoked_at = sqlc.arg('now'), updated_at = sqlc.arg('now')
+WHERE family_id = sqlc.arg('family_id') AND user_id = sqlc.arg('user_id') AND revoked_at IS NULL;
+
+-- name: RevokeAllRefreshTokensForUser :exec
+UPDATE refresh_tokens
+SET revoked_at = sqlc.arg('now'), updated_at = sqlc.arg('now')
+WHERE user_id = sqlc.arg('user_id') AND revoked_at IS NULL;
+
+-- name: ListRefreshTokensByUser :many
+SELECT * FROM refresh_tokens
+WHERE user_id = ?
+ORDER BY created_at;
@@ -0,0 +1,66 @@
+-- name: TombstoneChirp :exec
+-- Keeps a deleted chirp that has replies, without its body.
+UPDATE chirps
+SET body = '', deleted_at = sqlc.arg('now'), updated_at = sqlc.arg('now')
+WHERE id = sqlc.arg('id');
+
+-- name: CountReplies :one
+SELECT COUNT(*) FROM chirps
+WHERE in_reply_to_id = CAST(sqlc.arg('id') AS TEXT);
+
+-- name: CountRepliesByIDs :many
+SELECT in_reply_to_id, COUNT(*) AS reply_count
+FROM chirps
+WHERE in_reply_to_id IN (sqlc.slice('ids'))
+GROUP BY in_reply_to_id;
+
+-- name: ListChirpAncestors :many
+-- The parents of a chirp up to max_depth levels. A parent is older than its
+-- replies, so oldest first is root first.
+WITH RECURSIVE ancestors (id, in_reply_to_id, depth) AS (
+  SELECT parent.id, parent.in_reply_to_id, 1
+  FROM chirps AS parent
+  WHERE parent.id = (SELECT child.in_reply_to_id FROM chirps AS child WHERE child.id = sqlc.arg('id'))
+  UNION ALL
+  SELECT chirps.id, chirps.in_reply_to_id, ancestors.depth + 1
+  FROM chirps
+  JOIN ancestors ON chirps.id = ancestors.in_reply_to_id
+  WHERE ancestors.depth < CAST(sqlc.arg('max_depth') AS INTEGER)
+)
+SELECT * FROM chirps
+WHERE id IN (SELECT ancestors.id FROM ancestors)
+ORDER BY created_at ASC, id ASC;
+
+-- name: ListReplies :many
+-- Direct replies, oldest first.
+SELECT * FROM chirps
+WHERE in_reply_to_id = CAST(sqlc.arg('id') AS TEXT)
+  AND (
+    created_at > sqlc.narg('cursor_created_at')
+    OR (created_at = sqlc.narg('cursor_created_at') AND id > CAST(sqlc.narg('cursor_id') AS TEXT))
+    OR sqlc.narg('cursor_created_at') IS NULL
+  )
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ListDescendants :many
+-- The replies below one page of ListReplies, which is the replies to id between the keys
+-- (first_created_at, first_id) and (last_created_at, last_id), up to max_depth levels in all
+-- counting the page, oldest first. The page itself is not returned, so the limit only cuts
+-- deeper levels. A reply is never older than its parent, so it cannot cut a parent off its replies.
+WITH RECURSIVE descendants (id, depth) AS (
+  SELECT chirps.id, 1
+  FROM chirps
+  WHERE chirps.in_reply_to_id = CAST(sqlc.arg('id') AS TEXT)
+    AND (chirps.created_at > sqlc.arg('first_created_at') OR (chirps.created_at = sqlc.arg('first_created_at') AND chirps.id >= CAST(sqlc.arg('first_id') AS TEXT)))
+    AND (chirps.created_at < sqlc.arg('last_created_at') OR (chirps.created_at = sqlc.arg('last_created_at') AND chirps.id <= CAST(sqlc.arg('last_id') AS TEXT)))
+  UNION ALL
+  SELECT chirps.id, descendants.depth + 1
+  FROM chirps
+  JOIN descendants ON chirps.in_reply_to_id = descendants.id
+  WHERE descendants.depth < CAST(sqlc.arg('max_depth') AS INTEGER)
+)
+SELECT * FROM chirps
+WHERE id IN (SELECT descendants.id FROM descendants WHERE descendants.depth > 1)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
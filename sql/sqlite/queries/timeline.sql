@@ -0,0 +1,78 @@
+-- name: FanOutChirp :execrows
+-- Copies a chirp into the timeline of every follower of its author.
+INSERT OR IGNORE INTO timeline_entries (user_id, chirp_id, author_id, created_at)
+SELECT follows.follower_id, chirps.id, chirps.user_id, chirps.created_at
+FROM chirps
+JOIN follows ON follows.followee_id = chirps.user_id
+WHERE chirps.id = ?;
+
+-- name: MarkChirpFannedOut :exec
+UPDATE chirps
+SET fanned_out_at = sqlc.arg('now')
+WHERE id = sqlc.arg('id');
+
+-- name: BackfillTimeline :exec
+-- Copies the latest chirps of a newly followed account into the follower's timeline.
+INSERT OR IGNORE INTO timeline_entries (user_id, chirp_id, author_id, created_at)
+SELECT CAST(sqlc.arg('user_id') AS TEXT), chirps.id, chirps.user_id, chirps.created_at
+FROM chirps
+WHERE chirps.user_id = sqlc.arg('followee_id') AND chirps.deleted_at IS NULL
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: DeleteTimelineEntriesByAuthor :exec
+DELETE FROM timeline_entries
+WHERE user_id = ? AND author_id = ?;
+
+-- name: ListTimelineEntries :many
+-- One page of the materialized timeline, newest first, without accounts waiting for deletion.
+SELECT * FROM chirps
+WHERE id IN (
+  SELECT chirp_id FROM timeline_entries
+  WHERE timeline_entries.user_id = sqlc.arg('user_id')
+    AND timeline_entries.author_id NOT IN (SELECT users.id FROM users WHERE users.delete_after IS NOT NULL)
+    AND (
+      timeline_entries.created_at < sqlc.narg('cursor_created_at')
+      OR (timeline_entries.created_at = sqlc.narg('cursor_created_at') AND timeline_entries.chirp_id < CAST(sqlc.narg('cursor_id') AS TEXT))
+      OR sqlc.narg('cursor_created_at') IS NULL
+    )
+  ORDER BY timeline_entries.created_at DESC, timeline_entries.chirp_id DESC
+  LIMIT sqlc.arg('limit')
+)
+ORDER BY created_at DESC, id DESC;
+
+-- name: ListUnfannedTimelineChirps :many
+-- Chirps of followed accounts that are not fanned out, newest first: those of
+-- accounts above the fan-out threshold and those still waiting for fan-out.
+SELECT * FROM chirps
+WHERE chirps.fanned_out_at IS NULL
+  AND chirps.deleted_at IS NULL
+  AND chirps.user_id IN (SELECT followee_id FROM follows WHERE follower_id = sqlc.arg('user_id'))
+  AND chirps.user_id NOT IN (SELECT users.id FROM users WHERE users.delete_after IS NOT NULL)
+  AND (
+    chirps.created_at < sqlc.narg('cursor_created_at')
+    OR (chirps.created_at = sqlc.narg('cursor_created_at') AND chirps.id < CAST(sqlc.narg('cursor_id') AS TEXT))
+    OR sqlc.narg('cursor_created_at') IS NULL
+  )
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListUnfannedChirps :many
+SELECT * FROM chirps
+WHERE fanned_out_at IS NULL
+ORDER BY created_at, id;
+
+-- name: DeleteTimelineEntriesForChirp :exec
+DELETE FROM timeline_entries
+WHERE chirp_id = ?;
+
+-- name: DeleteAllTimelineEntries :exec
+DELETE FROM timeline_entries;
+
+-- name: RebuildTimelineEntries :execrows
+-- Materializes every fanned-out chirp into the timelines of its author's current followers.
+INSERT OR IGNORE INTO timeline_entries (user_id, chirp_id, author_id, created_at)
+SELECT follows.follower_id, chirps.id, chirps.user_id, chirps.created_at
+FROM follows
+JOIN chirps ON chirps.user_id = follows.followee_id
+WHERE chirps.fanned_out_at IS NOT NULL AND chirps.deleted_at IS NULL;
@@ -0,0 +1,184 @@
+-- name: CreateUser :one
+INSERT INTO users (id, created_at, updated_at, email, hashed_password)
+VALUES (
+    sqlc.arg('id'),
+    sqlc.arg('now'),
+    sqlc.arg('now'),
+    sqlc.arg('email'),
+    sqlc.arg('hashed_password')
+)
+RETURNING *;
+
+-- name: DeleteAllUsers :exec
+DELETE FROM users;
+
+-- name: GetUserFromEmail :one
+SELECT * FROM users
+WHERE email = ?;
+
+-- name: GetUserFromUserID :one
+SELECT * FROM users
+WHERE id = ?;
+
+-- name: UpdateUser :exec
+-- A new address has to be verified again.
+UPDATE users
+SET
+    updated_at = sqlc.arg('now'),
+    email = sqlc.arg('email'),
+    hashed_password = sqlc.arg('hashed_password'),
+    email_verified_at = CASE WHEN email = sqlc.arg('email') THEN email_verified_at END
+WHERE id = sqlc.arg('id');
+
+-- name: SetChirpyRed :exec
+UPDATE users
+SET
+    updated_at = sqlc.arg('now'),
+    is_chirpy_red = sqlc.arg('is_chirpy_red')
+WHERE id = sqlc.arg('id');
+
+-- name: SetTokensValidAfter :exec
+UPDATE users
+SET
+    updated_at = sqlc.arg('now'),
+    tokens_valid_after = sqlc.arg('tokens_valid_after')
+WHERE id = sqlc.arg('id');
+
+-- name: SetUserRole :exec
+UPDATE users
+SET
+    updated_at = sqlc.arg('now'),
+    role = sqlc.arg('role')
+WHERE id = sqlc.arg('id');
+
+-- name: SetUserTOTPSecret :exec
+UPDATE users
+SET
+    updated_at = sqlc.arg('now'),
+    totp_secret = sqlc.arg('totp_secret'),
+    totp_enabled_at = NULL,
+    totp_last_step = NULL
+WHERE id = sqlc.arg('id');
+
+-- name: EnableUserTOTP :exec
+UPDATE users
+SET
+    updated_at = sqlc.arg('now'),
+    totp_enabled_at = sqlc.arg('now')
+WHERE id = sqlc.arg('id') AND totp_secret IS NOT NULL;
+
+-- name: DisableUserTOTP :exec
+UPDATE users
+SET
+    updated_at = sqlc.arg('now'),
+    totp_secret = NULL,
+    totp_enabled_at = NULL,
+    totp_last_step = NULL
+WHERE id = sqlc.arg('id');
+
+-- name: UseTOTPStep :execrows
+-- Accepts each time step once: a code for a step at or before the last accepted one is a replay.
+UPDATE users
+SET totp_last_step = CAST(sqlc.arg('step') AS INTEGER)
+WHERE id = sqlc.arg('id') AND (totp_last_step IS NULL OR totp_last_step < sqlc.arg('step'));
+
+-- name: SetUserPassword :exec
+UPDATE users
+SET
+    updated_at = sqlc.arg('now'),
+    hashed_password = sqlc.arg('hashed_password')
+WHERE id = sqlc.arg('id');
+
+-- name: RehashUserPassword :execrows
+-- Compare-and-set: a password changed since the old hash was read is left alone.
+UPDATE users
+SET hashed_password = sqlc.arg('new_hash')
+WHERE id = sqlc.arg('id') AND hashed_password = sqlc.arg('old_hash');
+
+-- name: MarkEmailVerified :execrows
+UPDATE users
+SET
+    updated_at = sqlc.arg('now'),
+    email_verified_at = sqlc.arg('now')
+WHERE id = sqlc.arg('id') AND email = sqlc.arg('email');
+
+-- name: SetUserEmail :exec
+-- A new address has to be verified again.
+UPDATE users
+SET
+    updated_at = sqlc.arg('now'),
+    email = sqlc.arg('email'),
+    email_verified_at = NULL
+WHERE id = sqlc.arg('id');
+
+-- name: GetUserFromHandle :one
+SELECT * FROM users
+WHERE handle = ?;
+
+-- name: ListUsersByIDs :many
+SELECT * FROM users
+WHERE id IN (sqlc.slice('ids'));
+
+-- name: SetUserProfile :exec
+UPDATE users
+SET
+    updated_at = sqlc.arg('now'),
+    handle = sqlc.arg('handle'),
+    display_name = sqlc.arg('display_name'),
+    bio = sqlc.arg('bio'),
+    avatar_url = sqlc.arg('avatar_url'),
+    -- An avatar URL set by hand replaces the uploaded one.
+    avatar_key = CASE WHEN avatar_url = sqlc.arg('avatar_url') THEN avatar_key ELSE '' END
+WHERE id = sqlc.arg('id');
+
+-- name: PatchUser :exec
+-- PATCH /api/users in one statement. The profile fields are always written; email and
+-- hashed_password only when not NULL. A new address has to be verified again.
+UPDATE users
+SET
+    updated_at = sqlc.arg('now'),
+    handle = sqlc.arg('handle'),
+    display_name = sqlc.arg('display_name'),
+    bio = sqlc.arg('bio'),
+    avatar_url = sqlc.arg('avatar_url'),
+    avatar_key = CASE WHEN avatar_url = sqlc.arg('avatar_url') THEN avatar_key ELSE '' END,
+    email = COALESCE(CAST(sqlc.narg('email') AS TEXT), email),
+    email_verified_at = CASE WHEN CAST(sqlc.narg('email') AS TEXT) IS NULL THEN email_verified_at ELSE NULL END,
+    hashed_password = COALESCE(CAST(sqlc.narg('hashed_password') AS TEXT), hashed_password)
+WHERE id = sqlc.arg('id');
+
+-- name: SetUserAvatar :exec
+UPDATE users
+SET
+    updated_at = sqlc.arg('now'),
+    avatar_url = sqlc.arg('avatar_url'),
+    avatar_key = sqlc.arg('avatar_key')
+WHERE id = sqlc.arg('id');
+
+-- name: SetUserHeader :exec
+UPDATE users
+SET
+    updated_at = sqlc.arg('now'),
+    header_url = sqlc.arg('header_url'),
+    header_key = sqlc.arg('header_key')
+WHERE id = sqlc.arg('id');
+
+-- name: ScheduleUserDeletion :exec
+UPDATE users
+SET
+    updated_at = sqlc.arg('now'),
+    delete_after = sqlc.arg('delete_after')
+WHERE id = sqlc.arg('id');
+
+-- name: CancelUserDeletion :exec
+UPDATE users
+SET
+    updated_at = sqlc.arg('now'),
+    delete_after = NULL
+WHERE id = sqlc.arg('id');
+
+-- name: PurgeDeletedUsers :many
+-- Everything the user owns goes with the row (ON DELETE CASCADE); uploads are returned for cleanup.
+DELETE FROM users
+WHERE delete_after <= sqlc.arg('now')
+RETURNING id, avatar_key, header_key;
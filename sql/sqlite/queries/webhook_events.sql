@@ -0,0 +1,14 @@
+-- name: CreateWebhookEvent :exec
+INSERT INTO webhook_events (id, created_at, user_id, event, payload)
+VALUES (
+    sqlc.arg('id'),
+    sqlc.arg('now'),
+    sqlc.arg('user_id'),
+    sqlc.arg('event'),
+    sqlc.arg('payload')
+);
+
+-- name: ListWebhookEventsByUser :many
+SELECT * FROM webhook_events
+WHERE user_id = ?
+ORDER BY created_at;
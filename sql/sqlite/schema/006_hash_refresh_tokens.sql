@@ -0,0 +1,9 @@
+-- +goose Up
+-- Refresh tokens are stored as SHA-256 digests. SQLite has no sha256(),
+-- so existing sessions are invalidated instead of converted.
+DELETE FROM refresh_tokens;
+ALTER TABLE refresh_tokens RENAME COLUMN token TO token_hash;
+
+-- +goose Down
+DELETE FROM refresh_tokens;
+ALTER TABLE refresh_tokens RENAME COLUMN token_hash TO token;
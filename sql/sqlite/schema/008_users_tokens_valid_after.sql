@@ -0,0 +1,6 @@
+-- +goose Up
+-- Access tokens issued before this time are rejected (password change, logout-all, admin action).
+ALTER TABLE users ADD COLUMN tokens_valid_after TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN tokens_valid_after;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Failed login counters shared by all instances. key is "email:<address>" or "ip:<address>".
+CREATE TABLE login_attempts (
+  key TEXT PRIMARY KEY,
+  failures INTEGER NOT NULL,
+  last_failed_at TIMESTAMP NOT NULL,
+  locked_until TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS login_attempts;
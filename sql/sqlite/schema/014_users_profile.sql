@@ -0,0 +1,15 @@
+-- +goose Up
+-- handle is stored lowercased; users created before profiles existed have none until they pick one.
+ALTER TABLE users ADD COLUMN handle TEXT;
+ALTER TABLE users ADD COLUMN display_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN bio TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN avatar_url TEXT NOT NULL DEFAULT '';
+-- SQLite cannot add a UNIQUE column, so the constraint is an index.
+CREATE UNIQUE INDEX idx_users_handle ON users (handle);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_handle;
+ALTER TABLE users DROP COLUMN avatar_url;
+ALTER TABLE users DROP COLUMN bio;
+ALTER TABLE users DROP COLUMN display_name;
+ALTER TABLE users DROP COLUMN handle;
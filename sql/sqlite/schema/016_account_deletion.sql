@@ -0,0 +1,18 @@
+-- +goose Up
+-- Set when the user asks to delete the account; the row is removed once it has passed.
+ALTER TABLE users ADD COLUMN delete_after TIMESTAMP;
+
+-- Payment provider webhooks received for a user, kept for the data export.
+CREATE TABLE webhook_events (
+  id TEXT PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  event TEXT NOT NULL,
+  payload TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_events_user_id ON webhook_events (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS webhook_events;
+ALTER TABLE users DROP COLUMN delete_after;
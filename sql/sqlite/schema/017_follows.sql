@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE follows (
+  follower_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  followee_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (follower_id, followee_id),
+  CHECK (follower_id <> followee_id)
+);
+
+-- The primary key serves "who does X follow"; this one serves "who follows X".
+CREATE INDEX IF NOT EXISTS idx_follows_followee_id ON follows (followee_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS follows;
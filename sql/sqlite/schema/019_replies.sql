@@ -0,0 +1,19 @@
+-- +goose Up
+-- Replies lose their parent only if it is removed outside the API, which keeps a tombstone instead.
+ALTER TABLE chirps ADD COLUMN in_reply_to_id TEXT REFERENCES chirps(id) ON DELETE SET NULL;
+
+-- The ID of the chirp that started the thread; a chirp that replies to nothing starts its own.
+-- SQLite cannot add a NOT NULL column without a default; every row is filled in below.
+ALTER TABLE chirps ADD COLUMN conversation_id TEXT NOT NULL DEFAULT '';
+UPDATE chirps SET conversation_id = id;
+
+-- Set when a chirp with replies is deleted: the row stays without its body so the thread holds together.
+ALTER TABLE chirps ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_chirps_in_reply_to_id ON chirps (in_reply_to_id, created_at, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_chirps_in_reply_to_id;
+ALTER TABLE chirps DROP COLUMN deleted_at;
+ALTER TABLE chirps DROP COLUMN conversation_id;
+ALTER TABLE chirps DROP COLUMN in_reply_to_id;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE likes (
+  user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  chirp_id TEXT NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (user_id, chirp_id)
+);
+
+-- The primary key makes likes idempotent; these serve like counts and "chirps X liked".
+CREATE INDEX IF NOT EXISTS idx_likes_chirp_id ON likes (chirp_id);
+CREATE INDEX IF NOT EXISTS idx_likes_user_id_created_at ON likes (user_id, created_at, chirp_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS likes;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- A rechirp is a chirp without a body that reposts another one, so it reaches the
+-- rechirper's followers like any chirp. It goes away with the original.
+ALTER TABLE chirps ADD COLUMN rechirp_of_id TEXT REFERENCES chirps(id) ON DELETE CASCADE;
+
+-- A quote chirp has a body of its own and keeps it if the quoted chirp is removed.
+ALTER TABLE chirps ADD COLUMN quoted_id TEXT REFERENCES chirps(id) ON DELETE SET NULL;
+
+-- One rechirp per user and chirp.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_chirps_rechirp ON chirps (rechirp_of_id, user_id)
+  WHERE rechirp_of_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_chirps_quoted_id ON chirps (quoted_id)
+  WHERE quoted_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_chirps_quoted_id;
+DROP INDEX IF EXISTS idx_chirps_rechirp;
+ALTER TABLE chirps DROP COLUMN quoted_id;
+ALTER TABLE chirps DROP COLUMN rechirp_of_id;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- The last TOTP time step accepted for the user; codes at or before it are replays (RFC 6238 §5.2).
+ALTER TABLE users ADD COLUMN totp_last_step INTEGER;
+
+-- MFA pending tokens that already completed a login, kept until they would have expired.
+CREATE TABLE used_mfa_tokens (
+  jti TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_used_mfa_tokens_expires_at ON used_mfa_tokens (expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS used_mfa_tokens;
+ALTER TABLE users DROP COLUMN totp_last_step;
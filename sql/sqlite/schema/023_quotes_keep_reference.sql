@@ -0,0 +1,21 @@
+-- +goose Up
+-- A quote keeps the ID of the quoted chirp after that chirp is deleted outright,
+-- so it can still show it as deleted instead of silently losing the reference.
+-- SQLite cannot drop a foreign key, so the column is swapped for one without it.
+ALTER TABLE chirps ADD COLUMN quoted_id_new TEXT;
+UPDATE chirps SET quoted_id_new = quoted_id;
+DROP INDEX IF EXISTS idx_chirps_quoted_id;
+ALTER TABLE chirps DROP COLUMN quoted_id;
+ALTER TABLE chirps RENAME COLUMN quoted_id_new TO quoted_id;
+CREATE INDEX IF NOT EXISTS idx_chirps_quoted_id ON chirps (quoted_id)
+  WHERE quoted_id IS NOT NULL;
+
+-- +goose Down
+ALTER TABLE chirps ADD COLUMN quoted_id_old TEXT REFERENCES chirps(id) ON DELETE SET NULL;
+UPDATE chirps SET quoted_id_old = quoted_id
+WHERE quoted_id IN (SELECT id FROM chirps);
+DROP INDEX IF EXISTS idx_chirps_quoted_id;
+ALTER TABLE chirps DROP COLUMN quoted_id;
+ALTER TABLE chirps RENAME COLUMN quoted_id_old TO quoted_id;
+CREATE INDEX IF NOT EXISTS idx_chirps_quoted_id ON chirps (quoted_id)
+  WHERE quoted_id IS NOT NULL;
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/Tadateki/Chirpy/internal/database"
+	"github.com/Tadateki/Chirpy/internal/database/sqlite"
+)
+
+const (
+	memoryDBURL = "memory://"
+	sqliteDBURL = "sqlite://"
+)
+
+// openStore selects the storage backend from DB_URL.
+// "memory://" runs without a database, "sqlite://path" uses a local SQLite file;
+// anything else is treated as a Postgres DSN.
+func openStore(dbURL string) (database.Store, *sql.DB, error) {
+	switch {
+	case strings.HasPrefix(dbURL, memoryDBURL):
+		return database.NewMemoryStore(), nil, nil
+
+	case strings.HasPrefix(dbURL, sqliteDBURL):
+		db, err := sqlite.Open(strings.TrimPrefix(dbURL, sqliteDBURL))
+		if err != nil {
+			return nil, nil, err
+		}
+		return sqlite.NewStore(db), db, nil
+	}
+
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return database.New(db), db, nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Tadateki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	// Chirps of accounts with more followers are not copied into timelines; readers
+	// pull them instead (fan-out-on-read).
+	defaultFanoutMaxFollowers = 10000
+
+	// timelineBackfillLimit is how many recent chirps a new follow copies into the timeline.
+	timelineBackfillLimit = 200
+
+	fanoutWorkers   = 4
+	fanoutQueueSize = 1024
+	fanoutTimeout   = time.Minute
+)
+
+// timelineFanout copies new chirps into the materialized timelines of their authors'
+// followers in the background (fan-out-on-write). Until a chirp is marked fanned out,
+// timeline reads pull it directly, so a slow or dropped fan-out only costs read speed.
+type timelineFanout struct {
+	store        database.Store
+	maxFollowers int64
+	jobs         chan database.Chirp
+	pending      sync.WaitGroup
+}
+
+// newTimelineFanout starts the worker goroutines.
+func newTimelineFanout(store database.Store, maxFollowers int64, workers int) *timelineFanout {
+	f := &timelineFanout{
+		store:        store,
+		maxFollowers: maxFollowers,
+		jobs:         make(chan database.Chirp, fanoutQueueSize),
+	}
+	for range workers {
+		go f.work()
+	}
+	return f
+}
+
+// enqueue never blocks the request; when the queue is full the chirp stays on the
+// read path until `chirpy timeline rebuild` fans it out.
+func (f *timelineFanout) enqueue(chirp database.Chirp) {
+	f.pending.Add(1)
+	select {
+	case f.jobs <- chirp:
+	default:
+		f.pending.Done()
+		log.Printf("timeline fan-out queue is full; chirp %s is served on read", chirp.ID)
+	}
+}
+
+// wait blocks until every enqueued chirp has been processed.
+func (f *timelineFanout) wait() {
+	f.pending.Wait()
+}
+
+func (f *timelineFanout) work() {
+	for chirp := range f.jobs {
+		ctx, cancel := context.WithTimeout(context.Background(), fanoutTimeout)
+		if _, err := f.fanOut(ctx, chirp); err != nil {
+			log.Printf("timeline fan-out of chirp %s: %v", chirp.ID, err)
+		}
+		cancel()
+		f.pending.Done()
+	}
+}
+
+// fanOut copies the chirp into its author's followers' timelines and marks it fanned out.
+// It reports false, leaving the chirp to fan-out-on-read, if the author has too many followers.
+func (f *timelineFanout) fanOut(ctx context.Context, chirp database.Chirp) (bool, error) {
+	followers, err := f.store.CountFollowers(ctx, chirp.UserID)
+	if err != nil {
+		return false, err
+	}
+	if followers > f.maxFollowers {
+		return false, nil
+	}
+
+	if _, err := f.store.FanOutChirp(ctx, chirp.ID); err != nil {
+		return false, err
+	}
+	// Marked last: readers may briefly see the chirp twice, which the merge removes,
+	// but never miss it.
+	return true, f.store.MarkChirpFannedOut(ctx, chirp.ID)
+}
+
+// runTimelineCommand implements `chirpy timeline rebuild`.
+func runTimelineCommand(ctx context.Context, store database.Store, maxFollowers int64, args []string) error {
+	if len(args) != 1 || args[0] != "rebuild" {
+		return fmt.Errorf("usage: chirpy timeline rebuild")
+	}
+	return rebuildTimelines(ctx, store, maxFollowers)
+}
+
+// rebuildTimelines rematerializes every timeline from the follow graph, then fans out the
+// chirps that never were (queue overflow, crashes, accounts that dropped below the threshold).
+// Timelines are incomplete while it runs.
+func rebuildTimelines(ctx context.Context, store database.Store, maxFollowers int64) error {
+	if err := store.DeleteAllTimelineEntries(ctx); err != nil {
+		return err
+	}
+	n, err := store.RebuildTimelineEntries(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("rebuilt %d timeline entries", n)
+
+	chirps, err := store.ListUnfannedChirps(ctx)
+	if err != nil {
+		return err
+	}
+	f := &timelineFanout{store: store, maxFollowers: maxFollowers}
+	heavy := map[uuid.UUID]bool{}
+	fanned := 0
+	for _, chirp := range chirps {
+		if heavy[chirp.UserID] {
+			continue
+		}
+		ok, err := f.fanOut(ctx, chirp)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			heavy[chirp.UserID] = true
+			continue
+		}
+		fanned++
+	}
+	log.Printf("fanned out %d chirps; %d accounts are above the threshold and served on read", fanned, len(heavy))
+	return nil
+}